@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileTokenStore is a TokenStore backed by files in a directory, for sharing a token between
+// processes on the same host or on a shared volume. Each key is stored in its own file with
+// 0600 permissions, and Lock uses an exclusive lock file next to it.
+type FileTokenStore struct {
+	Dir string
+
+	// StaleLockAge is the age after which a lock file left behind by a crashed process is
+	// removed. Defaults to one minute.
+	StaleLockAge time.Duration
+
+	// PollInterval is how often Lock retries while another process holds the lock.
+	// Defaults to 50ms.
+	PollInterval time.Duration
+}
+
+// NewFileTokenStore creates a file token store in dir, creating the directory if needed
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create token store directory: %w", err)
+	}
+	return &FileTokenStore{Dir: dir}, nil
+}
+
+// path returns the file name for key; keys are hashed so any string is a safe file name
+func (s *FileTokenStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.Dir, "token-"+hex.EncodeToString(sum[:8]))
+}
+
+// Get returns the token stored for key, or nil
+func (s *FileTokenStore) Get(ctx context.Context, key string) (*Token, error) {
+	data, err := os.ReadFile(s.path(key) + ".json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		// A corrupt file is treated as missing and overwritten by the next Set
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// Set stores token for key, replacing the file atomically
+func (s *FileTokenStore) Set(ctx context.Context, key string, token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.Dir, ".token-*")
+	if err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)+".json"); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	return nil
+}
+
+// Lock creates an exclusive lock file for key, waiting until it is free or ctx is done
+func (s *FileTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	lockPath := s.path(key) + ".lock"
+	staleAge := s.StaleLockAge
+	if staleAge <= 0 {
+		staleAge = time.Minute
+	}
+	poll := s.PollInterval
+	if poll <= 0 {
+		poll = 50 * time.Millisecond
+	}
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			var once sync.Once
+			return func() { once.Do(func() { os.Remove(lockPath) }) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock token: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleAge {
+			os.Remove(lockPath)
+			continue
+		}
+
+		timer := time.NewTimer(poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
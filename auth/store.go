@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token is an auth token and its expiry, as kept in a TokenStore
+type Token struct {
+	Value     string    `json:"auth_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TokenStore caches tokens so that several TokenProviders, including ones in other
+// processes, can share a single token instead of each fetching their own.
+type TokenStore interface {
+	// Get returns the stored token for key, or nil if there is none
+	Get(ctx context.Context, key string) (*Token, error)
+	// Set stores token for key; it is not used after token.ExpiresAt
+	Set(ctx context.Context, key string, token *Token) error
+}
+
+// TokenLocker is optionally implemented by a TokenStore to serialise token fetches for a key,
+// so that only one provider fetches while the others wait and then read the stored token.
+type TokenLocker interface {
+	Lock(ctx context.Context, key string) (func(), error) // returns the unlock function
+}
+
+// TokenKey returns the store key for a client ID in an environment, identified by its main
+// API base URL. Sandbox and production tokens for the same client ID are kept apart, while
+// the main and Files API clients of one environment share a token.
+func TokenKey(clientID, environment string) string {
+	return "uqpay:token:" + strings.TrimRight(environment, "/") + ":" + clientID
+}
+
+// MemoryTokenStore is an in-process TokenStore
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]Token
+	locks  map[string]chan struct{}
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]Token),
+		locks:  make(map[string]chan struct{}),
+	}
+}
+
+// Get returns the token stored for key, or nil
+func (s *MemoryTokenStore) Get(ctx context.Context, key string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[key]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// Set stores token for key
+func (s *MemoryTokenStore) Set(ctx context.Context, key string, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = *token
+	return nil
+}
+
+// Lock acquires the fetch lock for key, waiting until it is free or ctx is done
+func (s *MemoryTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	s.mu.Lock()
+	sem, ok := s.locks[key]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		s.locks[key] = sem
+	}
+	s.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-sem }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
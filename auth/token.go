@@ -10,15 +10,23 @@ import (
 	"time"
 )
 
+// tokenRequestTimeout bounds a single token fetch, which is shared by all waiting callers
+const tokenRequestTimeout = 10 * time.Second
+
 // TokenResponse represents the auth token response
 type TokenResponse struct {
 	AuthToken string `json:"auth_token"`
 	ExpiredAt int64  `json:"expired_at"`
 }
 
-// TokenProvider automatically manages and refreshes auth tokens
+// TokenProvider automatically manages and refreshes auth tokens.
+//
+// Concurrent callers share a single in-flight refresh. Refresh is lazy: the first GetToken
+// call within refreshBuffer of expiry starts fetching a replacement in the background and
+// still returns the current token. An idle provider does not refresh on its own, so after a
+// long idle period the next call waits for a new token.
 type TokenProvider struct {
-	mu            sync.RWMutex
+	mu            sync.Mutex
 	baseURL       string
 	clientID      string
 	apiKey        string
@@ -26,6 +34,17 @@ type TokenProvider struct {
 	currentToken  string
 	expiresAt     time.Time
 	refreshBuffer time.Duration
+	refreshing    *refreshCall // in-flight refresh, nil when idle
+	store         TokenStore   // shared token cache, nil for an in-memory token only
+	storeKey      string       // key of the token in store
+	invalidated   string       // last token rejected by the API, never reused from the store
+}
+
+// refreshCall tracks an in-flight token fetch; done is closed once token/err are set
+type refreshCall struct {
+	done  chan struct{}
+	token string
+	err   error
 }
 
 // NewTokenProvider creates a new token provider
@@ -43,32 +62,156 @@ func NewTokenProvider(baseURL, clientID, apiKey string, httpClient *http.Client)
 	}
 }
 
-// GetToken returns a valid token, refreshing if necessary
-func (p *TokenProvider) GetToken() (string, error) {
-	p.mu.RLock()
-	if time.Now().Add(p.refreshBuffer).Before(p.expiresAt) {
+// NewTokenProviderWithStore creates a token provider that shares its token through store
+// under TokenKey(clientID, baseURL). Before fetching, the provider reuses a token another
+// provider has stored; if the store implements TokenLocker, fetches are serialised so that
+// only one provider calls the API.
+func NewTokenProviderWithStore(baseURL, clientID, apiKey string, httpClient *http.Client, store TokenStore) *TokenProvider {
+	return NewTokenProviderWithStoreKey(baseURL, clientID, apiKey, httpClient, store, TokenKey(clientID, baseURL))
+}
+
+// NewTokenProviderWithStoreKey is like NewTokenProviderWithStore but stores the token under
+// key, e.g. so that a Files API provider shares the token of its main API provider
+func NewTokenProviderWithStoreKey(baseURL, clientID, apiKey string, httpClient *http.Client, store TokenStore, key string) *TokenProvider {
+	p := NewTokenProvider(baseURL, clientID, apiKey, httpClient)
+	p.store, p.storeKey = store, key
+	return p
+}
+
+// GetToken returns a valid token, refreshing if necessary. If a refresh is needed the call
+// waits for it until ctx is done; cancelling ctx does not abort the refresh for other callers.
+func (p *TokenProvider) GetToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	now := time.Now()
+	if now.Add(p.refreshBuffer).Before(p.expiresAt) {
 		token := p.currentToken
-		p.mu.RUnlock()
+		p.mu.Unlock()
 		return token, nil
 	}
-	p.mu.RUnlock()
 
+	call := p.startRefreshLocked()
+
+	// Still valid but close to expiry: keep using it while the refresh runs in the background
+	if now.Before(p.expiresAt) {
+		token := p.currentToken
+		p.mu.Unlock()
+		return token, nil
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// InvalidateToken discards token if it is still the current one, so the next GetToken fetches
+// a new token. It is called by the API client when a request is rejected with 401.
+func (p *TokenProvider) InvalidateToken(token string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if p.currentToken == token {
+		p.currentToken = ""
+		p.expiresAt = time.Time{}
+	}
+	p.invalidated = token
+}
+
+// startRefreshLocked starts a refresh unless one is already running. p.mu must be held.
+func (p *TokenProvider) startRefreshLocked() *refreshCall {
+	if p.refreshing != nil {
+		return p.refreshing
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	p.refreshing = call
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tokenRequestTimeout)
+		defer cancel()
+		token, err := p.refresh(ctx)
+
+		p.mu.Lock()
+		if err == nil {
+			p.currentToken = token.Value
+			p.expiresAt = token.ExpiresAt
+			call.token = token.Value
+		}
+		call.err = err
+		p.refreshing = nil
+		p.mu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
+
+// refresh returns a fresh token from the store if another provider has stored one, and
+// otherwise fetches one from the API and stores it
+func (p *TokenProvider) refresh(ctx context.Context) (*Token, error) {
+	if p.store == nil {
+		return p.fetch(ctx)
+	}
+
+	key := p.storeKey
+	if token := p.storedToken(ctx, key); token != nil {
+		return token, nil
+	}
 
-	// Double-check after acquiring write lock
-	if time.Now().Add(p.refreshBuffer).Before(p.expiresAt) {
-		return p.currentToken, nil
+	if locker, ok := p.store.(TokenLocker); ok {
+		unlock, err := locker.Lock(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock token store: %w", err)
+		}
+		defer unlock()
+
+		// Another provider may have stored a token while we waited for the lock
+		if token := p.storedToken(ctx, key); token != nil {
+			return token, nil
+		}
 	}
 
-	// Refresh token
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	token, err := p.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// A failed write only costs other providers an extra fetch
+	_ = p.store.Set(ctx, key, token)
+	return token, nil
+}
+
+// storedToken returns the stored token if it is outside the refresh buffer and has not been
+// rejected by the API
+func (p *TokenProvider) storedToken(ctx context.Context, key string) *Token {
+	token, err := p.store.Get(ctx, key)
+	if err != nil || token == nil || token.Value == "" {
+		return nil
+	}
 
+	p.mu.Lock()
+	invalidated := p.invalidated
+	p.mu.Unlock()
+	if token.Value == invalidated || !time.Now().Add(p.refreshBuffer).Before(token.ExpiresAt) {
+		return nil
+	}
+	return token
+}
+
+// fetch requests a new token from the API
+func (p *TokenProvider) fetch(ctx context.Context) (*Token, error) {
+	tokenResp, err := p.fetchToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Token{Value: tokenResp.AuthToken, ExpiresAt: time.Unix(tokenResp.ExpiredAt, 0)}, nil
+}
+
+// fetchToken requests a new token from the API
+func (p *TokenProvider) fetchToken(ctx context.Context) (*TokenResponse, error) {
 	url := p.baseURL + "/v1/connect/token"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader([]byte("{}")))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -77,21 +220,17 @@ func (p *TokenProvider) GetToken() (string, error) {
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to get token: %w", err)
+		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to get token: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to get token: status %d", resp.StatusCode)
 	}
 
 	var tokenResp TokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	p.currentToken = tokenResp.AuthToken
-	p.expiresAt = time.Unix(tokenResp.ExpiredAt, 0)
-
-	return p.currentToken, nil
+	return &tokenResp, nil
 }
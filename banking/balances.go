@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // BalancesClient handles balance operations
@@ -14,21 +15,21 @@ type BalancesClient struct {
 
 // Balance represents account balance information
 type Balance struct {
-	BalanceID        string `json:"balance_id"`
-	Currency         string `json:"currency"`
-	AvailableBalance string `json:"available_balance"` // API returns string
-	PrepaidBalance   string `json:"prepaid_balance"`
-	MarginBalance    string `json:"margin_balance"`
-	FrozenBalance    string `json:"frozen_balance"`
-	BalanceStatus    string `json:"balance_status"`
-	CreateTime       string `json:"create_time"`
-	UpdateTime       string `json:"update_time"`
+	BalanceID        string        `json:"balance_id"`
+	Currency         string        `json:"currency"`
+	AvailableBalance money.Decimal `json:"available_balance"` // API returns string
+	PrepaidBalance   money.Decimal `json:"prepaid_balance"`
+	MarginBalance    money.Decimal `json:"margin_balance"`
+	FrozenBalance    money.Decimal `json:"frozen_balance"`
+	BalanceStatus    string        `json:"balance_status"`
+	CreateTime       string        `json:"create_time"`
+	UpdateTime       string        `json:"update_time"`
 }
 
 // ListBalancesRequest represents a balance list request
 type ListBalancesRequest struct {
-	PageSize   int `json:"page_size"`   // required, 10-100
-	PageNumber int `json:"page_number"` // required, >=1
+	PageSize   int `json:"page_size" url:"page_size"`     // required, 10-100
+	PageNumber int `json:"page_number" url:"page_number"` // required, >=1
 }
 
 // ListBalancesResponse represents a balance list response
@@ -40,27 +41,27 @@ type ListBalancesResponse struct {
 
 // BalanceTransaction represents a balance transaction
 type BalanceTransaction struct {
-	TransactionID     string `json:"transaction_id"`
-	Currency          string `json:"currency"`
-	Amount            string `json:"amount"`
-	TransactionType   string `json:"transaction_type"`   // PAYIN, DEPOSIT, PAYOUT, TRANSFER, CONVERSION, FEE, REFUND, ADJUSTMENT
-	TransactionStatus string `json:"transaction_status"` // COMPLETED, PENDING, FAILED
-	BalanceBefore     string `json:"balance_before"`
-	BalanceAfter      string `json:"balance_after"`
-	Description       string `json:"description"`
-	CreateTime        string `json:"create_time"`
-	ReferenceID       string `json:"reference_id"` // Related resource ID
+	TransactionID     string        `json:"transaction_id"`
+	Currency          string        `json:"currency"`
+	Amount            money.Decimal `json:"amount"`
+	TransactionType   string        `json:"transaction_type"`   // PAYIN, DEPOSIT, PAYOUT, TRANSFER, CONVERSION, FEE, REFUND, ADJUSTMENT
+	TransactionStatus string        `json:"transaction_status"` // COMPLETED, PENDING, FAILED
+	BalanceBefore     money.Decimal `json:"balance_before"`
+	BalanceAfter      money.Decimal `json:"balance_after"`
+	Description       string        `json:"description"`
+	CreateTime        string        `json:"create_time"`
+	ReferenceID       string        `json:"reference_id"` // Related resource ID
 }
 
 // ListBalanceTransactionsRequest represents a balance transaction list request
 type ListBalanceTransactionsRequest struct {
-	PageSize          int    `json:"page_size"`          // required, 10-100
-	PageNumber        int    `json:"page_number"`        // required, >=1
-	StartTime         string `json:"start_time"`         // optional, ISO8601
-	EndTime           string `json:"end_time"`           // optional, ISO8601
-	Currency          string `json:"currency"`           // optional
-	TransactionType   string `json:"transaction_type"`   // optional: ALL, PAYIN, DEPOSIT, etc.
-	TransactionStatus string `json:"transaction_status"` // optional: ALL, COMPLETED, PENDING, FAILED
+	PageSize          int    `json:"page_size" url:"page_size"`                   // required, 10-100
+	PageNumber        int    `json:"page_number" url:"page_number"`               // required, >=1
+	StartTime         string `json:"start_time" url:"start_time"`                 // optional, ISO8601
+	EndTime           string `json:"end_time" url:"end_time"`                     // optional, ISO8601
+	Currency          string `json:"currency" url:"currency"`                     // optional
+	TransactionType   string `json:"transaction_type" url:"transaction_type"`     // optional: ALL, PAYIN, DEPOSIT, etc.
+	TransactionStatus string `json:"transaction_status" url:"transaction_status"` // optional: ALL, COMPLETED, PENDING, FAILED
 }
 
 // ListBalanceTransactionsResponse represents a balance transaction list response
@@ -72,6 +73,7 @@ type ListBalanceTransactionsResponse struct {
 
 // Get retrieves balance for a specific currency
 func (c *BalancesClient) Get(ctx context.Context, currency string) (*Balance, error) {
+	ctx = common.WithOperation(ctx, "banking.balances.get")
 	var resp Balance
 	path := fmt.Sprintf("/v1/balances/%s", currency)
 	if err := c.client.Get(ctx, path, &resp); err != nil {
@@ -82,37 +84,72 @@ func (c *BalancesClient) Get(ctx context.Context, currency string) (*Balance, er
 
 // List lists all balances
 func (c *BalancesClient) List(ctx context.Context, req *ListBalancesRequest) (*ListBalancesResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.balances.list")
 	var resp ListBalancesResponse
-	path := fmt.Sprintf("/v1/balances?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
+	path, err := common.AppendQuery("/v1/balances", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balances: %w", err)
+	}
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list balances: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all balances matching req, starting at req.PageNumber
+func (c *BalancesClient) Iter(req *ListBalancesRequest) *common.Iterator[Balance] {
+	var query ListBalancesRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Balance], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Balance]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all balances matching req, fetching every page
+func (c *BalancesClient) ListAll(ctx context.Context, req *ListBalancesRequest) ([]Balance, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // ListTransactions lists balance transactions
 func (c *BalancesClient) ListTransactions(ctx context.Context, req *ListBalanceTransactionsRequest) (*ListBalanceTransactionsResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.balances.list_transactions")
 	var resp ListBalanceTransactionsResponse
-	path := fmt.Sprintf("/v1/balances/transactions?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.StartTime != "" {
-		path += fmt.Sprintf("&start_time=%s", req.StartTime)
-	}
-	if req.EndTime != "" {
-		path += fmt.Sprintf("&end_time=%s", req.EndTime)
-	}
-	if req.Currency != "" {
-		path += fmt.Sprintf("&currency=%s", req.Currency)
-	}
-	if req.TransactionType != "" {
-		path += fmt.Sprintf("&transaction_type=%s", req.TransactionType)
-	}
-	if req.TransactionStatus != "" {
-		path += fmt.Sprintf("&transaction_status=%s", req.TransactionStatus)
+	path, err := common.AppendQuery("/v1/balances/transactions", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balance transactions: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list balance transactions: %w", err)
 	}
 	return &resp, nil
 }
+
+// IterTransactions returns an iterator over all balance transactions matching req, starting at req.PageNumber
+func (c *BalancesClient) IterTransactions(req *ListBalanceTransactionsRequest) *common.Iterator[BalanceTransaction] {
+	var query ListBalanceTransactionsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[BalanceTransaction], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.ListTransactions(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[BalanceTransaction]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAllTransactions returns all balance transactions matching req, fetching every page
+func (c *BalancesClient) ListAllTransactions(ctx context.Context, req *ListBalanceTransactionsRequest) ([]BalanceTransaction, error) {
+	return c.IterTransactions(req).Collect(ctx, 0)
+}
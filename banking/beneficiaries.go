@@ -3,8 +3,10 @@ package banking
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // BeneficiariesClient handles beneficiary operations
@@ -77,12 +79,12 @@ type BeneficiaryCreationResponse struct {
 
 // ListBeneficiariesRequest represents a beneficiary list request
 type ListBeneficiariesRequest struct {
-	PageSize   int    `json:"page_size"`             // required, 10-100
-	PageNumber int    `json:"page_number"`           // required, >=1
-	Currency   string `json:"currency,omitempty"`    // optional
-	Country    string `json:"country,omitempty"`     // optional, ISO 3166-1 alpha-2
-	Status     string `json:"status,omitempty"`      // optional: active, inactive, deleted
-	EntityType string `json:"entity_type,omitempty"` // optional: INDIVIDUAL, COMPANY
+	PageSize   int    `json:"page_size" url:"page_size"`               // required, 10-100
+	PageNumber int    `json:"page_number" url:"page_number"`           // required, >=1
+	Currency   string `json:"currency,omitempty" url:"currency"`       // optional
+	Country    string `json:"country,omitempty" url:"country"`         // optional, ISO 3166-1 alpha-2
+	Status     string `json:"status,omitempty" url:"status"`           // optional: active, inactive, deleted
+	EntityType string `json:"entity_type,omitempty" url:"entity_type"` // optional: INDIVIDUAL, COMPANY
 }
 
 // ListBeneficiariesResponse represents a beneficiary list response
@@ -102,18 +104,19 @@ type BeneficiaryCheckRequest struct {
 
 // PaymentMethod represents an available payment method
 type PaymentMethod struct {
-	PaymentMethodID   string   `json:"payment_method_id"`
-	PaymentMethodName string   `json:"payment_method_name"`
-	Currency          string   `json:"currency"`
-	Country           string   `json:"country"`
-	RequiredFields    []string `json:"required_fields"`
-	OptionalFields    []string `json:"optional_fields"`
-	MinAmount         string   `json:"min_amount,omitempty"`
-	MaxAmount         string   `json:"max_amount,omitempty"`
+	PaymentMethodID   string         `json:"payment_method_id"`
+	PaymentMethodName string         `json:"payment_method_name"`
+	Currency          string         `json:"currency"`
+	Country           string         `json:"country"`
+	RequiredFields    []string       `json:"required_fields"`
+	OptionalFields    []string       `json:"optional_fields"`
+	MinAmount         *money.Decimal `json:"min_amount,omitempty"`
+	MaxAmount         *money.Decimal `json:"max_amount,omitempty"`
 }
 
 // Create creates a new beneficiary
 func (c *BeneficiariesClient) Create(ctx context.Context, req *BeneficiaryCreationRequest) (*BeneficiaryCreationResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.create")
 	var resp BeneficiaryCreationResponse
 	if err := c.client.Post(ctx, "/v1/beneficiaries", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create beneficiary: %w", err)
@@ -123,30 +126,43 @@ func (c *BeneficiariesClient) Create(ctx context.Context, req *BeneficiaryCreati
 
 // List lists beneficiaries with optional filters
 func (c *BeneficiariesClient) List(ctx context.Context, req *ListBeneficiariesRequest) (*ListBeneficiariesResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.list")
 	var resp ListBeneficiariesResponse
-	path := fmt.Sprintf("/v1/beneficiaries?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.Currency != "" {
-		path += fmt.Sprintf("&currency=%s", req.Currency)
-	}
-	if req.Country != "" {
-		path += fmt.Sprintf("&country=%s", req.Country)
-	}
-	if req.Status != "" {
-		path += fmt.Sprintf("&status=%s", req.Status)
-	}
-	if req.EntityType != "" {
-		path += fmt.Sprintf("&entity_type=%s", req.EntityType)
+	path, err := common.AppendQuery("/v1/beneficiaries", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beneficiaries: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list beneficiaries: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all beneficiaries matching req, starting at req.PageNumber
+func (c *BeneficiariesClient) Iter(req *ListBeneficiariesRequest) *common.Iterator[Beneficiary] {
+	var query ListBeneficiariesRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Beneficiary], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Beneficiary]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all beneficiaries matching req, fetching every page
+func (c *BeneficiariesClient) ListAll(ctx context.Context, req *ListBeneficiariesRequest) ([]Beneficiary, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Get retrieves a specific beneficiary by ID
 func (c *BeneficiariesClient) Get(ctx context.Context, beneficiaryID string) (*Beneficiary, error) {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.get")
 	var resp Beneficiary
 	path := fmt.Sprintf("/v1/beneficiaries/%s", beneficiaryID)
 	if err := c.client.Get(ctx, path, &resp); err != nil {
@@ -157,6 +173,7 @@ func (c *BeneficiariesClient) Get(ctx context.Context, beneficiaryID string) (*B
 
 // Update updates an existing beneficiary
 func (c *BeneficiariesClient) Update(ctx context.Context, beneficiaryID string, req *BeneficiaryCreationRequest) (*Beneficiary, error) {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.update")
 	var resp Beneficiary
 	path := fmt.Sprintf("/v1/beneficiaries/%s", beneficiaryID)
 	if err := c.client.Post(ctx, path, req, &resp); err != nil {
@@ -167,6 +184,7 @@ func (c *BeneficiariesClient) Update(ctx context.Context, beneficiaryID string,
 
 // Delete deletes a beneficiary
 func (c *BeneficiariesClient) Delete(ctx context.Context, beneficiaryID string) error {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.delete")
 	path := fmt.Sprintf("/v1/beneficiaries/%s/delete", beneficiaryID)
 	if err := c.client.Post(ctx, path, nil, nil); err != nil {
 		return fmt.Errorf("failed to delete beneficiary: %w", err)
@@ -176,8 +194,10 @@ func (c *BeneficiariesClient) Delete(ctx context.Context, beneficiaryID string)
 
 // ListPaymentMethods retrieves available payment methods for a currency and country
 func (c *BeneficiariesClient) ListPaymentMethods(ctx context.Context, currency, country string) ([]PaymentMethod, error) {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.list_payment_methods")
 	var methods []PaymentMethod
-	path := fmt.Sprintf("/v1/beneficiaries/paymentmethods?currency=%s&country=%s", currency, country)
+	query := url.Values{"currency": {currency}, "country": {country}}
+	path := "/v1/beneficiaries/paymentmethods?" + query.Encode()
 	if err := c.client.Get(ctx, path, &methods); err != nil {
 		return nil, fmt.Errorf("failed to list payment methods: %w", err)
 	}
@@ -186,6 +206,7 @@ func (c *BeneficiariesClient) ListPaymentMethods(ctx context.Context, currency,
 
 // Check validates beneficiary details before creation
 func (c *BeneficiariesClient) Check(ctx context.Context, req *BeneficiaryCheckRequest) (*Beneficiary, error) {
+	ctx = common.WithOperation(ctx, "banking.beneficiaries.check")
 	var resp Beneficiary
 	if err := c.client.Post(ctx, "/v1/beneficiaries/check", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to check beneficiary: %w", err)
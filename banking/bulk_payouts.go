@@ -0,0 +1,614 @@
+package banking
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// Bulk payout row statuses
+const (
+	BulkRowValid     = "VALID"     // passed validation, not submitted because of DryRun
+	BulkRowInvalid   = "INVALID"   // failed validation, not submitted
+	BulkRowSubmitted = "SUBMITTED" // payout created
+	BulkRowFailed    = "FAILED"    // payout rejected by the API
+	BulkRowSkipped   = "SKIPPED"   // not submitted because the batch was aborted
+)
+
+// DefaultBulkConcurrency is the number of payouts submitted at once when not configured
+const DefaultBulkConcurrency = 4
+
+// ErrBulkValidation is returned by Run when rows are invalid and SkipInvalid is not set
+var ErrBulkValidation = errors.New("uqpay: bulk payout rows failed validation")
+
+// bulkNamespace scopes idempotency keys derived for bulk payout rows
+var bulkNamespace = uuid.MustParse("5b2f6a3e-1c1d-4c57-9a43-0d6f3b1e8c21")
+
+// BulkPayoutRow is one payout in a batch
+type BulkPayoutRow struct {
+	CreatePayoutRequest
+
+	Line int `json:"-"` // line in the CSV file, or 1-based position in the JSON array
+
+	// Country and PaymentMethod select the payment method limits the amount is checked
+	// against. They default to the beneficiary's when BeneficiaryID is set.
+	Country       string `json:"country,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty"`
+}
+
+// BulkPayoutResult is the outcome of one row
+type BulkPayoutResult struct {
+	Row              BulkPayoutRow
+	Status           string // BulkRowValid, BulkRowInvalid, BulkRowSubmitted, BulkRowFailed or BulkRowSkipped
+	IdempotencyKey   string
+	PayoutID         string
+	ShortReferenceID string
+	Err              error // validation problems, the API error or the reason the row was skipped
+}
+
+// BulkPayoutReport lists the outcome of every row, in input order
+type BulkPayoutReport struct {
+	BatchID string
+	DryRun  bool
+	Results []BulkPayoutResult
+}
+
+// BulkPayoutOptions configures a BulkPayoutRunner
+type BulkPayoutOptions struct {
+	// BatchID identifies the batch, e.g. "payroll-2024-05". Idempotency keys are derived from
+	// it and the row contents, so running the same batch again replays the payouts already
+	// created instead of paying twice. Required.
+	BatchID string
+
+	Concurrency int  // maximum concurrent Create calls, DefaultBulkConcurrency when zero
+	DryRun      bool // validate only; rows that would be submitted are reported as VALID
+	SkipInvalid bool // submit valid rows even if others are invalid; by default nothing is submitted
+
+	// SkipBalanceCheck disables the available balance check, e.g. when resubmitting a batch
+	// whose payouts were partly created and have already been debited
+	SkipBalanceCheck bool
+}
+
+// BulkPayoutRunner validates and submits batches of payouts
+type BulkPayoutRunner struct {
+	client *Client
+	opts   BulkPayoutOptions
+}
+
+// NewBulkPayoutRunner returns a runner that validates and submits payouts through client
+func NewBulkPayoutRunner(client *Client, opts BulkPayoutOptions) *BulkPayoutRunner {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBulkConcurrency
+	}
+	return &BulkPayoutRunner{client: client, opts: opts}
+}
+
+// Run validates rows against payment method limits and available balances, then submits
+// the valid ones. The report is returned even when err is non-nil; err is ErrBulkValidation
+// when rows are invalid and SkipInvalid is not set, including on a dry run, a context error
+// if ctx is cancelled, or the error from a lookup needed for validation.
+func (r *BulkPayoutRunner) Run(ctx context.Context, rows []BulkPayoutRow) (*BulkPayoutReport, error) {
+	if r.opts.BatchID == "" {
+		return nil, errors.New("uqpay: bulk payout BatchID is required")
+	}
+	report := &BulkPayoutReport{BatchID: r.opts.BatchID, DryRun: r.opts.DryRun, Results: make([]BulkPayoutResult, len(rows))}
+	r.assignKeys(rows, report)
+	if err := r.validate(ctx, report); err != nil {
+		return report, err
+	}
+	invalid := report.Count(BulkRowInvalid) > 0 && !r.opts.SkipInvalid
+	if r.opts.DryRun {
+		if invalid {
+			return report, ErrBulkValidation
+		}
+		return report, nil
+	}
+	if invalid {
+		report.skip(BulkRowValid, ErrBulkValidation)
+		return report, ErrBulkValidation
+	}
+	return report, r.submit(ctx, report)
+}
+
+// assignKeys derives a deterministic idempotency key for each row. Identical rows are told
+// apart by their occurrence, not their line, so reordering the file keeps the keys stable.
+func (r *BulkPayoutRunner) assignKeys(rows []BulkPayoutRow, report *BulkPayoutReport) {
+	seen := make(map[string]int)
+	for i, row := range rows {
+		result := &report.Results[i]
+		result.Row = row
+		result.Status = BulkRowValid
+		if row.IdempotencyKey != "" {
+			result.IdempotencyKey = row.IdempotencyKey
+			continue
+		}
+		req := row.CreatePayoutRequest
+		req.Amount = money.Decimal{}
+		req.Currency = strings.ToUpper(req.Currency)
+		data, _ := json.Marshal(req)
+		content := fmt.Sprintf("%s\x00%s\x00%s", r.opts.BatchID, data, row.Amount.StringFixed(money.MinorUnits(req.Currency)))
+		seen[content]++
+		content += "\x00" + strconv.Itoa(seen[content])
+		result.IdempotencyKey = uuid.NewSHA1(bulkNamespace, []byte(content)).String()
+	}
+}
+
+// validate marks rows INVALID, leaving valid rows as VALID
+func (r *BulkPayoutRunner) validate(ctx context.Context, report *BulkPayoutReport) error {
+	v := &bulkValidator{
+		client:        r.client,
+		beneficiaries: make(map[string]*Beneficiary),
+		methods:       make(map[string][]PaymentMethod),
+		available:     make(map[string]money.Decimal),
+	}
+	for i := range report.Results {
+		result := &report.Results[i]
+		problems, err := v.check(ctx, &result.Row)
+		if err != nil {
+			return fmt.Errorf("failed to validate line %d: %w", result.Row.Line, err)
+		}
+		if len(problems) > 0 {
+			result.Status = BulkRowInvalid
+			result.Err = errors.New(strings.Join(problems, "; "))
+		}
+	}
+
+	if r.opts.SkipBalanceCheck {
+		return nil
+	}
+	// Balances are checked last, in row order, so the rows that overdraw a currency are flagged
+	needed := make(map[string]money.Decimal)
+	for i := range report.Results {
+		result := &report.Results[i]
+		if result.Status != BulkRowValid {
+			continue
+		}
+		currency := strings.ToUpper(result.Row.Currency)
+		available, err := v.balance(ctx, currency)
+		if err != nil {
+			return fmt.Errorf("failed to validate line %d: %w", result.Row.Line, err)
+		}
+		total := needed[currency].Add(result.Row.Amount)
+		if available.LessThan(total) {
+			result.Status = BulkRowInvalid
+			result.Err = fmt.Errorf("insufficient %s balance: batch needs %s, %s available", currency, total, available)
+			continue
+		}
+		needed[currency] = total
+	}
+	return nil
+}
+
+// submit creates payouts for VALID rows with bounded concurrency
+func (r *BulkPayoutRunner) submit(ctx context.Context, report *BulkPayoutReport) error {
+	sem := make(chan struct{}, r.opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := range report.Results {
+		result := &report.Results[i]
+		if result.Status != BulkRowValid {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			req := result.Row.CreatePayoutRequest
+			req.IdempotencyKey = result.IdempotencyKey
+			resp, err := r.client.Payouts.Create(ctx, &req)
+			if err != nil {
+				result.Status, result.Err = BulkRowFailed, err
+				return
+			}
+			result.Status, result.PayoutID, result.ShortReferenceID = BulkRowSubmitted, resp.PayoutID, resp.ShortReferenceID
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		report.skip(BulkRowValid, err)
+		return err
+	}
+	return nil
+}
+
+// bulkValidator caches the lookups shared by rows of a batch
+type bulkValidator struct {
+	client        *Client
+	beneficiaries map[string]*Beneficiary    // nil when not found
+	methods       map[string][]PaymentMethod // keyed by currency/country
+	available     map[string]money.Decimal   // keyed by currency
+}
+
+// check returns the validation problems of a row, or an error if a lookup failed
+func (v *bulkValidator) check(ctx context.Context, row *BulkPayoutRow) ([]string, error) {
+	var problems []string
+	currency := strings.ToUpper(row.Currency)
+	if len(currency) != 3 {
+		problems = append(problems, "currency is required")
+	}
+	if row.Amount.Sign() <= 0 {
+		problems = append(problems, "amount must be greater than zero")
+	} else if places := money.MinorUnits(currency); !row.Amount.Round(places).Equal(row.Amount) {
+		problems = append(problems, fmt.Sprintf("amount %s has more than %d decimal places", row.Amount, places))
+	}
+	if row.PayoutPurpose == "" {
+		problems = append(problems, "payout purpose is required")
+	}
+
+	country, method := strings.ToUpper(row.Country), row.PaymentMethod
+	switch {
+	case row.BeneficiaryID != "" && row.Beneficiary != nil:
+		problems = append(problems, "set either beneficiary_id or beneficiary details, not both")
+	case row.BeneficiaryID != "":
+		b, err := v.beneficiary(ctx, row.BeneficiaryID)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil || b.Status == "deleted" {
+			problems = append(problems, fmt.Sprintf("beneficiary %s not found", row.BeneficiaryID))
+			break
+		}
+		if !strings.EqualFold(b.Currency, currency) {
+			problems = append(problems, fmt.Sprintf("beneficiary %s receives %s, not %s", row.BeneficiaryID, b.Currency, currency))
+		}
+		if country == "" {
+			country = strings.ToUpper(b.Country)
+		}
+		if method == "" {
+			method = b.PaymentMethod
+		}
+	case row.Beneficiary != nil:
+		if country == "" && row.Beneficiary.ContactDetails != nil {
+			country = strings.ToUpper(row.Beneficiary.ContactDetails.Country)
+		}
+	default:
+		problems = append(problems, "beneficiary_id or beneficiary details are required")
+	}
+	if len(problems) > 0 {
+		return problems, nil
+	}
+	if country == "" {
+		// Payment methods and limits depend on the country, so the row cannot be checked without one
+		return append(problems, "beneficiary country required"), nil
+	}
+
+	methods, err := v.paymentMethods(ctx, currency, country)
+	if err != nil {
+		return nil, err
+	}
+	if problem := checkLimits(methods, method, row.Amount, currency, country); problem != "" {
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// checkLimits checks amount against the named method, or against any method when name is empty
+func checkLimits(methods []PaymentMethod, name string, amount money.Decimal, currency, country string) string {
+	var problem string
+	for _, m := range methods {
+		if name != "" && !strings.EqualFold(m.PaymentMethodID, name) && !strings.EqualFold(m.PaymentMethodName, name) {
+			continue
+		}
+		switch {
+		case m.MinAmount != nil && amount.LessThan(*m.MinAmount):
+			problem = fmt.Sprintf("amount %s is below the %s minimum of %s", amount, m.PaymentMethodID, m.MinAmount)
+		case m.MaxAmount != nil && amount.GreaterThan(*m.MaxAmount):
+			problem = fmt.Sprintf("amount %s is above the %s maximum of %s", amount, m.PaymentMethodID, m.MaxAmount)
+		default:
+			return ""
+		}
+		if name != "" {
+			return problem
+		}
+	}
+	if problem == "" {
+		if name == "" {
+			return fmt.Sprintf("no payment methods available for %s to %s", currency, country)
+		}
+		return fmt.Sprintf("payment method %s is not available for %s to %s", name, currency, country)
+	}
+	return problem
+}
+
+func (v *bulkValidator) beneficiary(ctx context.Context, id string) (*Beneficiary, error) {
+	if b, ok := v.beneficiaries[id]; ok {
+		return b, nil
+	}
+	b, err := v.client.Beneficiaries.Get(ctx, id)
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
+		return nil, err
+	}
+	v.beneficiaries[id] = b
+	return b, nil
+}
+
+func (v *bulkValidator) paymentMethods(ctx context.Context, currency, country string) ([]PaymentMethod, error) {
+	key := currency + "/" + country
+	if methods, ok := v.methods[key]; ok {
+		return methods, nil
+	}
+	methods, err := v.client.Beneficiaries.ListPaymentMethods(ctx, currency, country)
+	if err != nil {
+		return nil, err
+	}
+	v.methods[key] = methods
+	return methods, nil
+}
+
+func (v *bulkValidator) balance(ctx context.Context, currency string) (money.Decimal, error) {
+	if available, ok := v.available[currency]; ok {
+		return available, nil
+	}
+	var available money.Decimal
+	balance, err := v.client.Balances.Get(ctx, currency)
+	switch {
+	case err == nil:
+		available = balance.AvailableBalance
+	case !errors.Is(err, common.ErrNotFound):
+		return money.Decimal{}, err
+	}
+	v.available[currency] = available
+	return available, nil
+}
+
+// skip marks rows with the given status as SKIPPED
+func (r *BulkPayoutReport) skip(status string, reason error) {
+	for i := range r.Results {
+		if r.Results[i].Status == status {
+			r.Results[i].Status, r.Results[i].Err = BulkRowSkipped, reason
+		}
+	}
+}
+
+// Count returns the number of rows with the given status
+func (r *BulkPayoutReport) Count(status string) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// PayoutIDs returns the IDs of the payouts created, e.g. to pass to PayoutTracker.Track
+func (r *BulkPayoutReport) PayoutIDs() []string {
+	var ids []string
+	for _, result := range r.Results {
+		if result.PayoutID != "" {
+			ids = append(ids, result.PayoutID)
+		}
+	}
+	return ids
+}
+
+// Totals returns the total amount per currency of rows with the given status
+func (r *BulkPayoutReport) Totals(status string) map[string]money.Decimal {
+	totals := make(map[string]money.Decimal)
+	for _, result := range r.Results {
+		if result.Status == status {
+			currency := strings.ToUpper(result.Row.Currency)
+			totals[currency] = totals[currency].Add(result.Row.Amount)
+		}
+	}
+	return totals
+}
+
+var bulkReportColumns = []string{
+	"line", "status", "beneficiary_id", "currency", "amount", "reference",
+	"payout_id", "short_reference_id", "idempotency_key", "error",
+}
+
+// WriteCSV writes one line per row with its status, payout ID and error
+func (r *BulkPayoutReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(bulkReportColumns); err != nil {
+		return err
+	}
+	for _, result := range r.Results {
+		var errText string
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+		record := []string{
+			strconv.Itoa(result.Row.Line), result.Status, result.Row.BeneficiaryID, strings.ToUpper(result.Row.Currency),
+			result.Row.Amount.String(), result.Row.Reference, result.PayoutID, result.ShortReferenceID,
+			result.IdempotencyKey, errText,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type bulkResultJSON struct {
+	Line             int           `json:"line"`
+	Status           string        `json:"status"`
+	BeneficiaryID    string        `json:"beneficiary_id,omitempty"`
+	Currency         string        `json:"currency"`
+	Amount           money.Decimal `json:"amount"`
+	Reference        string        `json:"reference,omitempty"`
+	PayoutID         string        `json:"payout_id,omitempty"`
+	ShortReferenceID string        `json:"short_reference_id,omitempty"`
+	IdempotencyKey   string        `json:"idempotency_key"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// WriteJSON writes the report as a JSON object with batch_id, dry_run and results
+func (r *BulkPayoutReport) WriteJSON(w io.Writer) error {
+	out := struct {
+		BatchID string           `json:"batch_id"`
+		DryRun  bool             `json:"dry_run"`
+		Results []bulkResultJSON `json:"results"`
+	}{BatchID: r.BatchID, DryRun: r.DryRun, Results: make([]bulkResultJSON, len(r.Results))}
+	for i, result := range r.Results {
+		out.Results[i] = bulkResultJSON{
+			Line:             result.Row.Line,
+			Status:           result.Status,
+			BeneficiaryID:    result.Row.BeneficiaryID,
+			Currency:         strings.ToUpper(result.Row.Currency),
+			Amount:           result.Row.Amount,
+			Reference:        result.Row.Reference,
+			PayoutID:         result.PayoutID,
+			ShortReferenceID: result.ShortReferenceID,
+			IdempotencyKey:   result.IdempotencyKey,
+		}
+		if result.Err != nil {
+			out.Results[i].Error = result.Err.Error()
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// ParseBulkPayoutsJSON reads a JSON array of rows using the CreatePayoutRequest field names
+// plus country and payment_method
+func ParseBulkPayoutsJSON(r io.Reader) ([]BulkPayoutRow, error) {
+	var rows []BulkPayoutRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to parse bulk payouts: %w", err)
+	}
+	for i := range rows {
+		rows[i].Line = i + 1
+	}
+	return rows, nil
+}
+
+// bulkCSVColumns maps CSV headers to the row field they set
+var bulkCSVColumns = map[string]func(row *BulkPayoutRow, value string){
+	"beneficiary_id":  func(row *BulkPayoutRow, v string) { row.BeneficiaryID = v },
+	"currency":        func(row *BulkPayoutRow, v string) { row.Currency = v },
+	"payout_purpose":  func(row *BulkPayoutRow, v string) { row.PayoutPurpose = v },
+	"purpose":         func(row *BulkPayoutRow, v string) { row.PayoutPurpose = v },
+	"reference":       func(row *BulkPayoutRow, v string) { row.Reference = v },
+	"description":     func(row *BulkPayoutRow, v string) { row.Description = v },
+	"idempotency_key": func(row *BulkPayoutRow, v string) { row.IdempotencyKey = v },
+	"country":         func(row *BulkPayoutRow, v string) { row.Country = v },
+	"payment_method":  func(row *BulkPayoutRow, v string) { row.PaymentMethod = v },
+
+	"beneficiary_name": func(row *BulkPayoutRow, v string) { inlineBeneficiary(row).BeneficiaryName = v },
+	"account_number":   func(row *BulkPayoutRow, v string) { inlineBank(row).AccountNumber = v },
+	"account_name":     func(row *BulkPayoutRow, v string) { inlineBank(row).AccountName = v },
+	"account_type":     func(row *BulkPayoutRow, v string) { inlineBank(row).AccountType = v },
+	"bank_code":        func(row *BulkPayoutRow, v string) { inlineBank(row).BankCode = v },
+	"bank_name":        func(row *BulkPayoutRow, v string) { inlineBank(row).BankName = v },
+	"branch_code":      func(row *BulkPayoutRow, v string) { inlineBank(row).BranchCode = v },
+	"routing_number":   func(row *BulkPayoutRow, v string) { inlineBank(row).RoutingNumber = v },
+	"swift_code":       func(row *BulkPayoutRow, v string) { inlineBank(row).SwiftCode = v },
+	"iban":             func(row *BulkPayoutRow, v string) { inlineBank(row).IBAN = v },
+	"wallet_provider":  func(row *BulkPayoutRow, v string) { inlineWallet(row).WalletProvider = v },
+	"wallet_number":    func(row *BulkPayoutRow, v string) { inlineWallet(row).WalletNumber = v },
+	"wallet_name":      func(row *BulkPayoutRow, v string) { inlineWallet(row).WalletName = v },
+	"email":            func(row *BulkPayoutRow, v string) { inlineContact(row).Email = v },
+	"phone_number":     func(row *BulkPayoutRow, v string) { inlineContact(row).PhoneNumber = v },
+	"address":          func(row *BulkPayoutRow, v string) { inlineContact(row).Address = v },
+	"city":             func(row *BulkPayoutRow, v string) { inlineContact(row).City = v },
+	"postal_code":      func(row *BulkPayoutRow, v string) { inlineContact(row).PostalCode = v },
+}
+
+func inlineBeneficiary(row *BulkPayoutRow) *PayoutBeneficiary {
+	if row.Beneficiary == nil {
+		row.Beneficiary = &PayoutBeneficiary{}
+	}
+	return row.Beneficiary
+}
+
+func inlineBank(row *BulkPayoutRow) *PayoutBankDetails {
+	b := inlineBeneficiary(row)
+	if b.BankDetails == nil {
+		b.BankDetails = &PayoutBankDetails{}
+	}
+	return b.BankDetails
+}
+
+func inlineWallet(row *BulkPayoutRow) *WalletDetails {
+	b := inlineBeneficiary(row)
+	if b.WalletDetails == nil {
+		b.WalletDetails = &WalletDetails{}
+	}
+	return b.WalletDetails
+}
+
+func inlineContact(row *BulkPayoutRow) *PayoutContactDetails {
+	b := inlineBeneficiary(row)
+	if b.ContactDetails == nil {
+		b.ContactDetails = &PayoutContactDetails{}
+	}
+	return b.ContactDetails
+}
+
+// ParseBulkPayoutsCSV reads rows from a CSV file with a header line. Recognised columns are
+// beneficiary_id, currency, amount, payout_purpose (or purpose), reference, description,
+// idempotency_key, country, payment_method and, for inline beneficiaries, beneficiary_name,
+// the PayoutBankDetails, WalletDetails and PayoutContactDetails fields. Empty cells are ignored.
+func ParseBulkPayoutsCSV(r io.Reader) ([]BulkPayoutRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bulk payouts header: %w", err)
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := bulkCSVColumns[name]; !ok && name != "amount" {
+			return nil, fmt.Errorf("failed to read bulk payouts header: unknown column %q", name)
+		}
+		header[i] = name
+	}
+
+	var rows []BulkPayoutRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bulk payouts: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := BulkPayoutRow{Line: line}
+		empty := true
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value == "" || i >= len(header) {
+				continue
+			}
+			empty = false
+			if header[i] == "amount" {
+				if row.Amount, err = money.Parse(value); err != nil {
+					return nil, fmt.Errorf("failed to read bulk payouts: line %d: invalid amount %q", line, value)
+				}
+				continue
+			}
+			bulkCSVColumns[header[i]](&row, value)
+		}
+		if empty {
+			continue
+		}
+		if row.Beneficiary != nil && row.Country != "" {
+			if contact := inlineContact(&row); contact.Country == "" {
+				contact.Country = row.Country
+			}
+		}
+		rows = append(rows, row)
+	}
+}
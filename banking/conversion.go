@@ -3,8 +3,10 @@ package banking
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // ConversionClient handles conversion operations
@@ -14,43 +16,45 @@ type ConversionClient struct {
 
 // Conversion represents a currency conversion
 type Conversion struct {
-	ConversionID     string `json:"conversion_id"`
-	ShortReferenceID string `json:"short_reference_id"`
-	CurrencyFrom     string `json:"currency_from"`
-	CurrencyTo       string `json:"currency_to"`
-	AmountFrom       string `json:"amount_from"`
-	AmountTo         string `json:"amount_to"`
-	Rate             string `json:"rate"`
-	ConversionStatus string `json:"conversion_status"` // COMPLETED, PENDING, FAILED
-	CreateTime       string `json:"create_time"`
-	CompletedTime    string `json:"completed_time,omitempty"`
-	SettlementDate   string `json:"settlement_date,omitempty"`
+	ConversionID     string        `json:"conversion_id"`
+	ShortReferenceID string        `json:"short_reference_id"`
+	CurrencyFrom     string        `json:"currency_from"`
+	CurrencyTo       string        `json:"currency_to"`
+	AmountFrom       money.Decimal `json:"amount_from"`
+	AmountTo         money.Decimal `json:"amount_to"`
+	Rate             money.Decimal `json:"rate"`
+	ConversionStatus string        `json:"conversion_status"` // COMPLETED, PENDING, FAILED
+	CreateTime       string        `json:"create_time"`
+	CompletedTime    string        `json:"completed_time,omitempty"`
+	SettlementDate   string        `json:"settlement_date,omitempty"`
 }
 
 // CreateConversionRequest represents a conversion creation request
 type CreateConversionRequest struct {
-	CurrencyFrom   string `json:"currency_from"`   // required
-	CurrencyTo     string `json:"currency_to"`     // required
-	AmountFrom     string `json:"amount_from"`     // required
-	SettlementDate string `json:"settlement_date"` // optional, format: YYYY-MM-DD
-	QuoteID        string `json:"quote_id"`        // optional, if provided, conversion will use quoted rate
+	CurrencyFrom   string        `json:"currency_from"`   // required
+	CurrencyTo     string        `json:"currency_to"`     // required
+	AmountFrom     money.Decimal `json:"amount_from"`     // required
+	SettlementDate string        `json:"settlement_date"` // optional, format: YYYY-MM-DD
+	QuoteID        string        `json:"quote_id"`        // optional, if provided, conversion will use quoted rate
+	IdempotencyKey string        `json:"-"`               // optional, generated when empty
 }
 
 // CreateConversionResponse represents a conversion creation response
 type CreateConversionResponse struct {
 	ConversionID     string `json:"conversion_id"`
 	ShortReferenceID string `json:"short_reference_id"`
+	IdempotencyKey   string `json:"-"` // idempotency key used for the request
 }
 
 // ListConversionsRequest represents a conversion list request
 type ListConversionsRequest struct {
-	PageSize         int    `json:"page_size"`         // required, 10-100
-	PageNumber       int    `json:"page_number"`       // required, >=1
-	StartTime        string `json:"start_time"`        // optional, ISO8601
-	EndTime          string `json:"end_time"`          // optional, ISO8601
-	ConversionStatus string `json:"conversion_status"` // optional: COMPLETED, PENDING, FAILED
-	CurrencyFrom     string `json:"currency_from"`     // optional
-	CurrencyTo       string `json:"currency_to"`       // optional
+	PageSize         int    `json:"page_size" url:"page_size"`                 // required, 10-100
+	PageNumber       int    `json:"page_number" url:"page_number"`             // required, >=1
+	StartTime        string `json:"start_time" url:"start_time"`               // optional, ISO8601
+	EndTime          string `json:"end_time" url:"end_time"`                   // optional, ISO8601
+	ConversionStatus string `json:"conversion_status" url:"conversion_status"` // optional: COMPLETED, PENDING, FAILED
+	CurrencyFrom     string `json:"currency_from" url:"currency_from"`         // optional
+	CurrencyTo       string `json:"currency_to" url:"currency_to"`             // optional
 }
 
 // ListConversionsResponse represents a conversion list response
@@ -62,22 +66,22 @@ type ListConversionsResponse struct {
 
 // CreateQuoteRequest represents a quote creation request
 type CreateQuoteRequest struct {
-	CurrencyFrom   string `json:"currency_from"`   // required
-	CurrencyTo     string `json:"currency_to"`     // required
-	AmountFrom     string `json:"amount_from"`     // required
-	SettlementDate string `json:"settlement_date"` // optional, format: YYYY-MM-DD
+	CurrencyFrom   string        `json:"currency_from"`   // required
+	CurrencyTo     string        `json:"currency_to"`     // required
+	AmountFrom     money.Decimal `json:"amount_from"`     // required
+	SettlementDate string        `json:"settlement_date"` // optional, format: YYYY-MM-DD
 }
 
 // CreateQuoteResponse represents a quote creation response
 type CreateQuoteResponse struct {
-	QuoteID        string `json:"quote_id"`
-	CurrencyFrom   string `json:"currency_from"`
-	CurrencyTo     string `json:"currency_to"`
-	AmountFrom     string `json:"amount_from"`
-	AmountTo       string `json:"amount_to"`
-	Rate           string `json:"rate"`
-	SettlementDate string `json:"settlement_date,omitempty"`
-	ExpiresAt      string `json:"expires_at"` // ISO8601 timestamp
+	QuoteID        string        `json:"quote_id"`
+	CurrencyFrom   string        `json:"currency_from"`
+	CurrencyTo     string        `json:"currency_to"`
+	AmountFrom     money.Decimal `json:"amount_from"`
+	AmountTo       money.Decimal `json:"amount_to"`
+	Rate           money.Decimal `json:"rate"`
+	SettlementDate string        `json:"settlement_date,omitempty"`
+	ExpiresAt      string        `json:"expires_at"` // ISO8601 timestamp
 }
 
 // ConversionDate represents available conversion dates for a currency pair
@@ -90,42 +94,55 @@ type ConversionDate struct {
 
 // List lists conversions
 func (c *ConversionClient) List(ctx context.Context, req *ListConversionsRequest) (*ListConversionsResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.conversions.list")
 	var resp ListConversionsResponse
-	path := fmt.Sprintf("/v1/conversion?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.StartTime != "" {
-		path += fmt.Sprintf("&start_time=%s", req.StartTime)
-	}
-	if req.EndTime != "" {
-		path += fmt.Sprintf("&end_time=%s", req.EndTime)
-	}
-	if req.ConversionStatus != "" {
-		path += fmt.Sprintf("&conversion_status=%s", req.ConversionStatus)
-	}
-	if req.CurrencyFrom != "" {
-		path += fmt.Sprintf("&currency_from=%s", req.CurrencyFrom)
-	}
-	if req.CurrencyTo != "" {
-		path += fmt.Sprintf("&currency_to=%s", req.CurrencyTo)
+	path, err := common.AppendQuery("/v1/conversion", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversions: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list conversions: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all conversions matching req, starting at req.PageNumber
+func (c *ConversionClient) Iter(req *ListConversionsRequest) *common.Iterator[Conversion] {
+	var query ListConversionsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Conversion], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Conversion]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all conversions matching req, fetching every page
+func (c *ConversionClient) ListAll(ctx context.Context, req *ListConversionsRequest) ([]Conversion, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Create creates a new conversion
 func (c *ConversionClient) Create(ctx context.Context, req *CreateConversionRequest) (*CreateConversionResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.conversions.create")
+	ctx, key := common.EnsureIdempotencyKey(ctx, req.IdempotencyKey)
 	var resp CreateConversionResponse
 	if err := c.client.Post(ctx, "/v1/conversion", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create conversion: %w", err)
 	}
+	resp.IdempotencyKey = key
 	return &resp, nil
 }
 
 // Get retrieves a specific conversion
 func (c *ConversionClient) Get(ctx context.Context, conversionID string) (*Conversion, error) {
+	ctx = common.WithOperation(ctx, "banking.conversions.get")
 	var resp Conversion
 	path := fmt.Sprintf("/v1/conversion/%s", conversionID)
 	if err := c.client.Get(ctx, path, &resp); err != nil {
@@ -136,8 +153,10 @@ func (c *ConversionClient) Get(ctx context.Context, conversionID string) (*Conve
 
 // ListConversionDates retrieves available conversion dates for a currency pair
 func (c *ConversionClient) ListConversionDates(ctx context.Context, currencyFrom, currencyTo string) ([]ConversionDate, error) {
+	ctx = common.WithOperation(ctx, "banking.conversions.list_conversion_dates")
 	var resp []ConversionDate
-	path := fmt.Sprintf("/v1/conversion/conversion_dates?currency_from=%s&currency_to=%s", currencyFrom, currencyTo)
+	query := url.Values{"currency_from": {currencyFrom}, "currency_to": {currencyTo}}
+	path := "/v1/conversion/conversion_dates?" + query.Encode()
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list conversion dates: %w", err)
 	}
@@ -146,6 +165,7 @@ func (c *ConversionClient) ListConversionDates(ctx context.Context, currencyFrom
 
 // CreateQuote creates a new conversion quote
 func (c *ConversionClient) CreateQuote(ctx context.Context, req *CreateQuoteRequest) (*CreateQuoteResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.conversions.create_quote")
 	var resp CreateQuoteResponse
 	if err := c.client.Post(ctx, "/v1/conversion/quote", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create quote: %w", err)
@@ -0,0 +1,334 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+)
+
+// Settlement date strategies
+const (
+	SettlementEarliest  = "EARLIEST"  // first date whose cutoff has not passed
+	SettlementOptimized = "OPTIMIZED" // the date marked optimized, falling back to the earliest
+)
+
+// Scheduled conversion statuses
+const (
+	ScheduledStatusPending   = "SCHEDULED" // waiting for ExecuteAt, or to retry after a transient error
+	ScheduledStatusSubmitted = "SUBMITTED" // conversion created
+	ScheduledStatusFailed    = "FAILED"    // conversion rejected by the API with a non-retryable error
+	ScheduledStatusMissed    = "MISSED"    // final cutoff passed before it was submitted
+)
+
+// Defaults used by ConversionScheduler when options are zero
+const (
+	DefaultCutoffLead        = 5 * time.Minute
+	DefaultSchedulerInterval = time.Minute
+)
+
+// Errors returned when choosing settlement dates and scheduling conversions
+var (
+	ErrNoSettlementDate  = errors.New("uqpay: no conversion date available")
+	ErrFirstCutoffMissed = errors.New("uqpay: first conversion cutoff missed")
+	ErrCutoffMissed      = errors.New("uqpay: conversion cutoff missed")
+)
+
+// SettlementDate is a conversion date with parsed cutoffs
+type SettlementDate struct {
+	Date         string    // YYYY-MM-DD
+	FirstCutoff  time.Time // submit before this for standard processing
+	SecondCutoff time.Time // final cutoff for the date
+	Optimized    bool
+}
+
+// UntilFirstCutoff returns the time left before the first cutoff, negative once it has passed
+func (d SettlementDate) UntilFirstCutoff(now time.Time) time.Duration {
+	return d.FirstCutoff.Sub(now)
+}
+
+// UntilSecondCutoff returns the time left before the final cutoff, negative once it has passed
+func (d SettlementDate) UntilSecondCutoff(now time.Time) time.Duration {
+	return d.SecondCutoff.Sub(now)
+}
+
+// Available reports whether a conversion can still settle on this date
+func (d SettlementDate) Available(now time.Time) bool {
+	return now.Before(d.SecondCutoff)
+}
+
+// SettlementDates retrieves the conversion dates for a currency pair with parsed cutoffs,
+// sorted by date. Dates with a missing or unparsable cutoff are skipped, since they cannot
+// be scheduled against.
+func (c *ConversionClient) SettlementDates(ctx context.Context, currencyFrom, currencyTo string) ([]SettlementDate, error) {
+	dates, err := c.ListConversionDates(ctx, currencyFrom, currencyTo)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SettlementDate, 0, len(dates))
+	for _, d := range dates {
+		first, err := time.Parse(time.RFC3339, d.FirstCutoff)
+		if err != nil {
+			continue
+		}
+		second, err := time.Parse(time.RFC3339, d.SecondCutoff)
+		if err != nil {
+			continue
+		}
+		out = append(out, SettlementDate{Date: d.Date, FirstCutoff: first, SecondCutoff: second, Optimized: d.OptimizedDate})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out, nil
+}
+
+// PickSettlementDate chooses a date from dates, sorted as returned by SettlementDates, that
+// is still available at now. strategy is SettlementEarliest (the default) or SettlementOptimized.
+func PickSettlementDate(dates []SettlementDate, strategy string, now time.Time) (SettlementDate, error) {
+	if strategy == SettlementOptimized {
+		for _, d := range dates {
+			if d.Optimized && d.Available(now) {
+				return d, nil
+			}
+		}
+	}
+	for _, d := range dates {
+		if d.Available(now) {
+			return d, nil
+		}
+	}
+	return SettlementDate{}, ErrNoSettlementDate
+}
+
+// ScheduledConversion is a conversion queued by a ConversionScheduler
+type ScheduledConversion struct {
+	Request        CreateConversionRequest // SettlementDate and IdempotencyKey are filled in
+	SettlementDate SettlementDate
+	ExecuteAt      time.Time // Lead before the first cutoff
+	Status         string    // ScheduledStatusPending, ScheduledStatusSubmitted, ScheduledStatusFailed or ScheduledStatusMissed
+	Conversion     *CreateConversionResponse
+	Err            error // ErrCutoffMissed, the API error, or the transient error of the last attempt
+
+	retryAt time.Time // earliest next attempt after a transient error
+	warned  bool      // OnWarning has been called
+}
+
+// dueAt returns when the conversion should next be attempted
+func (c *ScheduledConversion) dueAt() time.Time {
+	if c.retryAt.After(c.ExecuteAt) {
+		return c.retryAt
+	}
+	return c.ExecuteAt
+}
+
+// ConversionSchedulerOptions configures a ConversionScheduler
+type ConversionSchedulerOptions struct {
+	Strategy string        // used when a request has no SettlementDate, SettlementEarliest when empty
+	Lead     time.Duration // submit this long before the first cutoff, DefaultCutoffLead when zero
+	Interval time.Duration // longest sleep between checks in Run and delay before retrying a transient error, DefaultSchedulerInterval when zero
+
+	// OnWarning is called once when a conversion is submitted after its first cutoff but
+	// before the final one, with an error wrapping ErrFirstCutoffMissed
+	OnWarning func(conversion ScheduledConversion, err error)
+	// OnResult is called when a conversion is submitted, fails or misses its final cutoff
+	OnResult func(conversion ScheduledConversion)
+
+	Now func() time.Time // clock used for cutoffs, time.Now when nil
+}
+
+// ConversionScheduler queues conversions and submits each one shortly before the cutoff of
+// its settlement date.
+//
+// Callbacks run on the goroutine calling Schedule, RunDue or Run, one at a time.
+type ConversionScheduler struct {
+	client *ConversionClient
+	opts   ConversionSchedulerOptions
+
+	mu      sync.Mutex
+	pending []*ScheduledConversion
+}
+
+// NewConversionScheduler returns a scheduler that submits conversions through client
+func NewConversionScheduler(client *ConversionClient, opts ConversionSchedulerOptions) *ConversionScheduler {
+	if opts.Strategy == "" {
+		opts.Strategy = SettlementEarliest
+	}
+	if opts.Lead <= 0 {
+		opts.Lead = DefaultCutoffLead
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultSchedulerInterval
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &ConversionScheduler{client: client, opts: opts}
+}
+
+// Schedule queues req for its SettlementDate, or for a date chosen with Strategy when empty.
+// It fails with ErrCutoffMissed if the date's final cutoff has passed and ErrNoSettlementDate
+// if the date is not offered for the pair.
+func (s *ConversionScheduler) Schedule(ctx context.Context, req CreateConversionRequest) (*ScheduledConversion, error) {
+	dates, err := s.client.SettlementDates(ctx, req.CurrencyFrom, req.CurrencyTo)
+	if err != nil {
+		return nil, err
+	}
+	now := s.opts.Now()
+
+	var date SettlementDate
+	if req.SettlementDate == "" {
+		if date, err = PickSettlementDate(dates, s.opts.Strategy, now); err != nil {
+			return nil, fmt.Errorf("%w for %s/%s", err, req.CurrencyFrom, req.CurrencyTo)
+		}
+	} else {
+		found := false
+		for _, d := range dates {
+			if d.Date == req.SettlementDate {
+				date, found = d, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s for %s/%s", ErrNoSettlementDate, req.SettlementDate, req.CurrencyFrom, req.CurrencyTo)
+		}
+		if !date.Available(now) {
+			return nil, fmt.Errorf("%w: %s closed at %s", ErrCutoffMissed, date.Date, date.SecondCutoff.Format(time.RFC3339))
+		}
+	}
+
+	req.SettlementDate = date.Date
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = common.NewIdempotencyKey()
+	}
+	scheduled := &ScheduledConversion{
+		Request:        req,
+		SettlementDate: date,
+		ExecuteAt:      date.FirstCutoff.Add(-s.opts.Lead),
+		Status:         ScheduledStatusPending,
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, scheduled)
+	s.mu.Unlock()
+	return scheduled, nil
+}
+
+// Pending returns copies of the conversions that have not been submitted, by ExecuteAt.
+// Conversions waiting to retry a transient error keep their Err.
+func (s *ConversionScheduler) Pending() []ScheduledConversion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]ScheduledConversion, 0, len(s.pending))
+	for _, p := range s.pending {
+		out = append(out, *p)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ExecuteAt.Before(out[j].ExecuteAt) })
+	return out
+}
+
+// RunDue submits every pending conversion whose ExecuteAt has passed and returns them with
+// their status. Conversions whose final cutoff has passed are not submitted. A conversion
+// that fails with a transient error (a network error, 429 or 5xx) stays pending with Err set
+// and is retried after Interval until its final cutoff.
+func (s *ConversionScheduler) RunDue(ctx context.Context) []ScheduledConversion {
+	now := s.opts.Now()
+	s.mu.Lock()
+	var due []*ScheduledConversion
+	remaining := s.pending[:0]
+	for _, p := range s.pending {
+		if now.Before(p.dueAt()) {
+			remaining = append(remaining, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	s.pending = remaining
+	s.mu.Unlock()
+
+	results := make([]ScheduledConversion, 0, len(due))
+	for _, p := range due {
+		s.execute(ctx, p, now)
+		if p.Status == ScheduledStatusPending {
+			s.mu.Lock()
+			s.pending = append(s.pending, p)
+			s.mu.Unlock()
+		} else if s.opts.OnResult != nil {
+			s.opts.OnResult(*p)
+		}
+		results = append(results, *p)
+	}
+	return results
+}
+
+// Run calls RunDue until ctx is done, sleeping until the next ExecuteAt or for at most
+// Interval. It returns ctx.Err().
+func (s *ConversionScheduler) Run(ctx context.Context) error {
+	for {
+		s.RunDue(ctx)
+		wait := s.opts.Interval
+		if next, ok := s.nextDue(); ok {
+			if next := next.Sub(s.opts.Now()); next < wait {
+				wait = next
+			}
+		}
+		if err := common.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// nextDue returns the earliest time a pending conversion is due
+func (s *ConversionScheduler) nextDue() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, p := range s.pending {
+		if due := p.dueAt(); next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func (s *ConversionScheduler) execute(ctx context.Context, p *ScheduledConversion, now time.Time) {
+	if !p.SettlementDate.Available(now) {
+		p.Status = ScheduledStatusMissed
+		p.Err = fmt.Errorf("%w: %s closed at %s", ErrCutoffMissed, p.SettlementDate.Date, p.SettlementDate.SecondCutoff.Format(time.RFC3339))
+		return
+	}
+	if !now.Before(p.SettlementDate.FirstCutoff) && !p.warned {
+		p.warned = true
+		s.warn(p)
+	}
+	// The request keeps its idempotency key, so a retry cannot create a second conversion
+	req := p.Request
+	resp, err := s.client.Create(ctx, &req)
+	switch {
+	case err == nil:
+		p.Status, p.Conversion, p.Err = ScheduledStatusSubmitted, resp, nil
+	case transientError(err):
+		p.Err, p.retryAt = err, now.Add(s.opts.Interval)
+	default:
+		p.Status, p.Err = ScheduledStatusFailed, err
+	}
+}
+
+// transientError reports whether a failed request may succeed if retried: network errors,
+// timeouts, cancellations, 429 and 5xx responses
+func transientError(err error) bool {
+	var apiErr *common.APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return errors.Is(err, common.ErrRateLimited) || apiErr.StatusCode >= 500
+}
+
+func (s *ConversionScheduler) warn(p *ScheduledConversion) {
+	if s.opts.OnWarning != nil {
+		err := fmt.Errorf("%w: %s passed at %s", ErrFirstCutoffMissed, p.SettlementDate.Date, p.SettlementDate.FirstCutoff.Format(time.RFC3339))
+		s.opts.OnWarning(*p, err)
+	}
+}
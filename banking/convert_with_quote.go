@@ -0,0 +1,208 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// Conversion statuses
+const (
+	ConversionStatusPending   = "PENDING"
+	ConversionStatusCompleted = "COMPLETED"
+	ConversionStatusFailed    = "FAILED"
+)
+
+// Defaults used by ConvertWithQuote when options are zero
+const (
+	DefaultMinQuoteValidity = 10 * time.Second
+	DefaultMaxQuotes        = 3
+)
+
+// Errors returned by ConvertWithQuote and WaitForConversion
+var (
+	ErrConversionFailed = errors.New("uqpay: conversion did not complete")
+	ErrQuoteExpired     = errors.New("uqpay: quote expired before it could be executed")
+	ErrSlippageExceeded = errors.New("uqpay: quoted rate is worse than the allowed slippage")
+	ErrNoReferenceRate  = errors.New("uqpay: no reference rate for currency pair")
+)
+
+// ConversionError is returned by WaitForConversion when the conversion fails or does not finish in time
+type ConversionError struct {
+	ConversionID string
+	Conversion   *Conversion // last conversion seen, nil if none was retrieved
+	Err          error       // ErrConversionFailed, common.ErrWaitTimeout, a context error or the API error
+}
+
+// Error implements the error interface
+func (e *ConversionError) Error() string {
+	if e.Conversion != nil {
+		return fmt.Sprintf("conversion %s (%s): %v", e.ConversionID, e.Conversion.ConversionStatus, e.Err)
+	}
+	return fmt.Sprintf("conversion %s: %v", e.ConversionID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForConversion polls a conversion until it is COMPLETED or FAILED; other statuses are
+// polled again. It returns the conversion when COMPLETED and a *ConversionError otherwise.
+// opts may be nil.
+func (c *ConversionClient) WaitForConversion(ctx context.Context, conversionID string, opts *common.WaitOptions) (*Conversion, error) {
+	var last *Conversion
+	err := common.Poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		conversion, err := c.Get(ctx, conversionID)
+		if err != nil {
+			return false, err
+		}
+		last = conversion
+		return conversion.ConversionStatus == ConversionStatusCompleted || conversion.ConversionStatus == ConversionStatusFailed, nil
+	})
+	if err == nil && last.ConversionStatus != ConversionStatusCompleted {
+		err = ErrConversionFailed
+	}
+	if err != nil {
+		return last, &ConversionError{ConversionID: conversionID, Conversion: last, Err: err}
+	}
+	return last, nil
+}
+
+// ConvertOptions configures ConvertWithQuote
+type ConvertOptions struct {
+	// Approve inspects every quote, including re-quotes, before it is executed. Returning an
+	// error aborts the conversion with that error. Optional.
+	Approve func(ctx context.Context, quote *CreateQuoteResponse) error
+
+	// MaxSlippage is the largest fraction by which the quoted rate may be worse than the
+	// reference rate from ExchangeRatesClient.List, e.g. 0.005 for 0.5%. Zero disables the check.
+	MaxSlippage money.Decimal
+
+	MinQuoteValidity time.Duration       // re-quote when less than this is left before ExpiresAt, DefaultMinQuoteValidity when zero
+	MaxQuotes        int                 // quotes requested before giving up with ErrQuoteExpired, DefaultMaxQuotes when zero
+	Wait             *common.WaitOptions // polling for the conversion to complete; nil uses the defaults
+	Now              func() time.Time    // clock used to check expiry, time.Now when nil
+}
+
+// ConvertResult is the outcome of ConvertWithQuote
+type ConvertResult struct {
+	Quote         *CreateQuoteResponse // quote the conversion was executed with
+	Conversion    *Conversion          // completed conversion
+	ReferenceRate *money.Decimal       // reference rate checked against, nil without MaxSlippage
+	Quotes        int                  // number of quotes requested
+}
+
+// ConvertWithQuote quotes a conversion, checks the rate against the reference rate and the
+// approval callback, executes it with the quote and waits for it to complete. Quotes that are
+// close to expiry, or expire before they are executed, are replaced with a fresh quote.
+func (c *ConversionClient) ConvertWithQuote(ctx context.Context, req *CreateQuoteRequest, opts *ConvertOptions) (*ConvertResult, error) {
+	var o ConvertOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MinQuoteValidity <= 0 {
+		o.MinQuoteValidity = DefaultMinQuoteValidity
+	}
+	if o.MaxQuotes <= 0 {
+		o.MaxQuotes = DefaultMaxQuotes
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+
+	result := &ConvertResult{}
+	for result.Quotes < o.MaxQuotes {
+		quote, err := c.CreateQuote(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		result.Quotes++
+		result.Quote = quote
+
+		if o.MaxSlippage.Sign() > 0 {
+			reference, err := c.referenceRate(ctx, quote.CurrencyFrom, quote.CurrencyTo)
+			if err != nil {
+				return result, err
+			}
+			result.ReferenceRate = &reference
+			if err := checkSlippage(quote.Rate, reference, o.MaxSlippage); err != nil {
+				return result, err
+			}
+		}
+		if o.Approve != nil {
+			if err := o.Approve(ctx, quote); err != nil {
+				return result, err
+			}
+		}
+		if !quoteValidFor(quote, o.Now(), o.MinQuoteValidity) {
+			continue
+		}
+
+		created, err := c.Create(ctx, &CreateConversionRequest{
+			CurrencyFrom:   quote.CurrencyFrom,
+			CurrencyTo:     quote.CurrencyTo,
+			AmountFrom:     quote.AmountFrom,
+			SettlementDate: quote.SettlementDate,
+			QuoteID:        quote.QuoteID,
+		})
+		if isQuoteExpired(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		conversion, err := c.WaitForConversion(ctx, created.ConversionID, o.Wait)
+		result.Conversion = conversion
+		return result, err
+	}
+	return result, fmt.Errorf("%w: %d quotes requested", ErrQuoteExpired, result.Quotes)
+}
+
+// referenceRate returns the rate for selling from and buying to, from the published rates
+func (c *ConversionClient) referenceRate(ctx context.Context, from, to string) (money.Decimal, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	rates := &ExchangeRatesClient{client: c.client}
+	resp, err := rates.List(ctx, &ListRatesRequest{CurrencyPairs: []string{from + "/" + to, to + "/" + from}})
+	if err != nil {
+		return money.Decimal{}, err
+	}
+	rate, ok := lookupRate(publishedRates(resp.Data.Rates, time.Time{}), from, to, DefaultRatePrecision)
+	if !ok || rate.Sell.Sign() <= 0 {
+		return money.Decimal{}, fmt.Errorf("%w: %s/%s", ErrNoReferenceRate, from, to)
+	}
+	return rate.Sell, nil
+}
+
+// checkSlippage fails when rate is more than maxSlippage below reference
+func checkSlippage(rate, reference, maxSlippage money.Decimal) error {
+	slippage, err := reference.Sub(rate).Div(reference, 8)
+	if err != nil {
+		return err
+	}
+	if slippage.GreaterThan(maxSlippage) {
+		return fmt.Errorf("%w: rate %s is %s below reference %s, limit %s", ErrSlippageExceeded, rate, slippage, reference, maxSlippage)
+	}
+	return nil
+}
+
+// quoteValidFor reports whether quote stays valid for at least d after now. Quotes with an
+// unparseable expiry are assumed valid and left for the API to reject.
+func quoteValidFor(quote *CreateQuoteResponse, now time.Time, d time.Duration) bool {
+	expiresAt, err := time.Parse(time.RFC3339, quote.ExpiresAt)
+	if err != nil {
+		return true
+	}
+	return expiresAt.Sub(now) >= d
+}
+
+// isQuoteExpired reports whether err is the API rejecting an expired quote
+func isQuoteExpired(err error) bool {
+	var apiErr *common.APIError
+	return errors.As(err, &apiErr) && strings.Contains(strings.ToLower(apiErr.Code), "expired")
+}
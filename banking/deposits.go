@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // DepositsClient handles deposit operations
@@ -14,27 +15,27 @@ type DepositsClient struct {
 
 // Deposit represents a deposit transaction
 type Deposit struct {
-	DepositID        string `json:"deposit_id"`
-	ShortReferenceID string `json:"short_reference_id"`
-	Currency         string `json:"currency"`
-	Amount           string `json:"amount"`
-	DepositStatus    string `json:"deposit_status"`
-	PaymentMethod    string `json:"payment_method"`
-	PayerName        string `json:"payer_name"`
-	PayerEmail       string `json:"payer_email"`
-	Description      string `json:"description"`
-	CreateTime       string `json:"create_time"`
-	CompletedTime    string `json:"completed_time"`
+	DepositID        string        `json:"deposit_id"`
+	ShortReferenceID string        `json:"short_reference_id"`
+	Currency         string        `json:"currency"`
+	Amount           money.Decimal `json:"amount"`
+	DepositStatus    string        `json:"deposit_status"`
+	PaymentMethod    string        `json:"payment_method"`
+	PayerName        string        `json:"payer_name"`
+	PayerEmail       string        `json:"payer_email"`
+	Description      string        `json:"description"`
+	CreateTime       string        `json:"create_time"`
+	CompletedTime    string        `json:"completed_time"`
 }
 
 // ListDepositsRequest represents a deposit list request
 type ListDepositsRequest struct {
-	PageSize      int    `json:"page_size"`      // required, 10-100
-	PageNumber    int    `json:"page_number"`    // required, >=1
-	StartTime     string `json:"start_time"`     // optional, ISO8601
-	EndTime       string `json:"end_time"`       // optional, ISO8601
-	DepositStatus string `json:"deposit_status"` // optional: PENDING, COMPLETED, FAILED
-	Currency      string `json:"currency"`       // optional
+	PageSize      int    `json:"page_size" url:"page_size"`           // required, 10-100
+	PageNumber    int    `json:"page_number" url:"page_number"`       // required, >=1
+	StartTime     string `json:"start_time" url:"start_time"`         // optional, ISO8601
+	EndTime       string `json:"end_time" url:"end_time"`             // optional, ISO8601
+	DepositStatus string `json:"deposit_status" url:"deposit_status"` // optional: PENDING, COMPLETED, FAILED
+	Currency      string `json:"currency" url:"currency"`             // optional
 }
 
 // ListDepositsResponse represents a deposit list response
@@ -46,30 +47,43 @@ type ListDepositsResponse struct {
 
 // List lists deposits
 func (c *DepositsClient) List(ctx context.Context, req *ListDepositsRequest) (*ListDepositsResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.deposits.list")
 	var resp ListDepositsResponse
-	path := fmt.Sprintf("/v1/deposit?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.StartTime != "" {
-		path += fmt.Sprintf("&start_time=%s", req.StartTime)
-	}
-	if req.EndTime != "" {
-		path += fmt.Sprintf("&end_time=%s", req.EndTime)
-	}
-	if req.DepositStatus != "" {
-		path += fmt.Sprintf("&deposit_status=%s", req.DepositStatus)
-	}
-	if req.Currency != "" {
-		path += fmt.Sprintf("&currency=%s", req.Currency)
+	path, err := common.AppendQuery("/v1/deposit", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deposits: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list deposits: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all deposits matching req, starting at req.PageNumber
+func (c *DepositsClient) Iter(req *ListDepositsRequest) *common.Iterator[Deposit] {
+	var query ListDepositsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Deposit], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Deposit]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all deposits matching req, fetching every page
+func (c *DepositsClient) ListAll(ctx context.Context, req *ListDepositsRequest) ([]Deposit, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Get retrieves a specific deposit
 func (c *DepositsClient) Get(ctx context.Context, depositID string) (*Deposit, error) {
+	ctx = common.WithOperation(ctx, "banking.deposits.get")
 	var resp Deposit
 	path := fmt.Sprintf("/v1/deposit/%s", depositID)
 	if err := c.client.Get(ctx, path, &resp); err != nil {
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // ExchangeRatesClient handles exchange rate operations
@@ -15,14 +16,14 @@ type ExchangeRatesClient struct {
 
 // RateItem represents an exchange rate for a currency pair
 type RateItem struct {
-	CurrencyPair string `json:"currency_pair"` // e.g., "USD/EUR"
-	BuyPrice     string `json:"buy_price"`     // Price for buying the base currency
-	SellPrice    string `json:"sell_price"`    // Price for selling the base currency
+	CurrencyPair string        `json:"currency_pair"` // e.g., "USD/EUR"
+	BuyPrice     money.Decimal `json:"buy_price"`     // Price for buying the base currency
+	SellPrice    money.Decimal `json:"sell_price"`    // Price for selling the base currency
 }
 
 // ListRatesRequest represents a request to list exchange rates
 type ListRatesRequest struct {
-	CurrencyPairs []string `json:"currency_pairs,omitempty"` // optional: filter by specific currency pairs (e.g., ["USD/EUR", "GBP/USD"])
+	CurrencyPairs []string `json:"currency_pairs,omitempty" url:"currency_pairs,comma"` // optional: filter by specific currency pairs (e.g., ["USD/EUR", "GBP/USD"])
 }
 
 // ListRatesResponse represents a response containing exchange rates
@@ -37,8 +38,9 @@ type ListRatesResponse struct {
 // List retrieves current exchange rates
 // Optionally filter by specific currency pairs
 func (c *ExchangeRatesClient) List(ctx context.Context, req *ListRatesRequest) (*ListRatesResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.exchange_rates.list")
 	var resp ListRatesResponse
-	path := "/v1/exchange/rates"
+	query := &ListRatesRequest{}
 
 	// Add currency_pairs query parameter if specified
 	if req != nil && len(req.CurrencyPairs) > 0 {
@@ -58,8 +60,12 @@ func (c *ExchangeRatesClient) List(ctx context.Context, req *ListRatesRequest) (
 				}
 			}
 		}
-		pairs := strings.Join(out, ",")
-		path += fmt.Sprintf("?currency_pairs=%s", pairs)
+		query.CurrencyPairs = out
+	}
+
+	path, err := common.AppendQuery("/v1/exchange/rates", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
 	}
 
 	if err := c.client.Get(ctx, path, &resp); err != nil {
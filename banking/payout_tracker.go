@@ -0,0 +1,263 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+)
+
+// Payout statuses
+const (
+	PayoutStatusPending    = "PENDING"
+	PayoutStatusProcessing = "PROCESSING"
+	PayoutStatusCompleted  = "COMPLETED"
+	PayoutStatusFailed     = "FAILED"
+	PayoutStatusCancelled  = "CANCELLED"
+)
+
+// Defaults used by PayoutTracker when options are zero
+const (
+	DefaultTrackerConcurrency = 4
+	DefaultTrackerInterval    = 5 * time.Second
+)
+
+// ErrPayoutFailed is wrapped by PayoutError when a payout ends FAILED or CANCELLED
+var ErrPayoutFailed = errors.New("uqpay: payout did not complete")
+
+// IsTerminalPayoutStatus reports whether a payout status is final
+func IsTerminalPayoutStatus(status string) bool {
+	switch status {
+	case PayoutStatusCompleted, PayoutStatusFailed, PayoutStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// PayoutError is returned by WaitForPayout when the payout fails or does not finish in time
+type PayoutError struct {
+	PayoutID string
+	Payout   *PayoutDetailResponse // last payout seen, nil if none was retrieved
+	Err      error                 // ErrPayoutFailed, common.ErrWaitTimeout, a context error or the API error
+}
+
+// Error implements the error interface
+func (e *PayoutError) Error() string {
+	if e.Payout == nil {
+		return fmt.Sprintf("payout %s: %v", e.PayoutID, e.Err)
+	}
+	if e.Payout.FailureReason != "" {
+		return fmt.Sprintf("payout %s (%s: %s): %v", e.PayoutID, e.Payout.PayoutStatus, e.Payout.FailureReason, e.Err)
+	}
+	return fmt.Sprintf("payout %s (%s): %v", e.PayoutID, e.Payout.PayoutStatus, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *PayoutError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForPayout polls a payout until it reaches a terminal status.
+// It returns the payout when COMPLETED and a *PayoutError otherwise. opts may be nil.
+func (c *PayoutsClient) WaitForPayout(ctx context.Context, payoutID string, opts *common.WaitOptions) (*PayoutDetailResponse, error) {
+	var last *PayoutDetailResponse
+	err := common.Poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		payout, err := c.Get(ctx, payoutID)
+		if err != nil {
+			return false, err
+		}
+		last = payout
+		return IsTerminalPayoutStatus(payout.PayoutStatus), nil
+	})
+	if err == nil && last.PayoutStatus != PayoutStatusCompleted {
+		err = ErrPayoutFailed
+	}
+	if err != nil {
+		return last, &PayoutError{PayoutID: payoutID, Payout: last, Err: err}
+	}
+	return last, nil
+}
+
+// PayoutStatusChange describes a payout observed in a new status
+type PayoutStatusChange struct {
+	PayoutID string
+	Previous string                // last known status, empty on the first observation
+	Payout   *PayoutDetailResponse // current payout, including Status and FailureReason
+}
+
+// PayoutTrackerOptions configures a PayoutTracker
+type PayoutTrackerOptions struct {
+	Concurrency int           // maximum concurrent Get calls, DefaultTrackerConcurrency when zero
+	Interval    time.Duration // delay between polling rounds, DefaultTrackerInterval when zero
+
+	// OnChange is called when a payout is first seen or its status changes
+	OnChange func(change PayoutStatusChange)
+	// OnError is called when a payout cannot be retrieved. Payouts that are not found
+	// stop being tracked; other errors are retried on the next round.
+	OnError func(payoutID string, err error)
+}
+
+// PayoutTracker watches many payouts until they reach a terminal status.
+//
+// Callbacks run on the goroutine calling Run, Wait or PollOnce, one at a time. Persist
+// Snapshot to survive restarts and pass it to Resume on the new tracker.
+type PayoutTracker struct {
+	client *PayoutsClient
+	opts   PayoutTrackerOptions
+
+	mu      sync.Mutex
+	pending map[string]string // payout ID -> last known status
+}
+
+// NewPayoutTracker returns a tracker that polls payouts through client
+func NewPayoutTracker(client *PayoutsClient, opts PayoutTrackerOptions) *PayoutTracker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultTrackerConcurrency
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultTrackerInterval
+	}
+	return &PayoutTracker{client: client, opts: opts, pending: make(map[string]string)}
+}
+
+// Track starts tracking payouts; IDs already tracked are left unchanged
+func (t *PayoutTracker) Track(payoutIDs ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range payoutIDs {
+		if _, ok := t.pending[id]; !ok {
+			t.pending[id] = ""
+		}
+	}
+}
+
+// Resume tracks payouts with their last known statuses, as returned by Snapshot, so that
+// OnChange only fires for changes made since the snapshot was taken
+func (t *PayoutTracker) Resume(snapshot map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, status := range snapshot {
+		if !IsTerminalPayoutStatus(status) {
+			t.pending[id] = status
+		}
+	}
+}
+
+// Snapshot returns the payouts still being tracked and their last known statuses
+func (t *PayoutTracker) Snapshot() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := make(map[string]string, len(t.pending))
+	for id, status := range t.pending {
+		snapshot[id] = status
+	}
+	return snapshot
+}
+
+// Pending returns the IDs of payouts that have not reached a terminal status, sorted
+func (t *PayoutTracker) Pending() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]string, 0, len(t.pending))
+	for id := range t.pending {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Run polls tracked payouts every Interval until ctx is done, returning ctx.Err().
+// Payouts may be added with Track while it runs.
+func (t *PayoutTracker) Run(ctx context.Context) error {
+	return common.Poll(ctx, t.waitOptions(), func(ctx context.Context) (bool, error) {
+		t.PollOnce(ctx)
+		return false, nil
+	})
+}
+
+// Wait polls tracked payouts until all of them reach a terminal status or ctx is done
+func (t *PayoutTracker) Wait(ctx context.Context) error {
+	return common.Poll(ctx, t.waitOptions(), func(ctx context.Context) (bool, error) {
+		t.PollOnce(ctx)
+		return len(t.Pending()) == 0, nil
+	})
+}
+
+// waitOptions polls every Interval, without backoff or timeout
+func (t *PayoutTracker) waitOptions() *common.WaitOptions {
+	return &common.WaitOptions{Interval: t.opts.Interval, MaxInterval: t.opts.Interval, Timeout: -1}
+}
+
+type payoutPollResult struct {
+	id     string
+	payout *PayoutDetailResponse
+	err    error
+}
+
+// PollOnce retrieves every tracked payout once, with at most Concurrency requests in
+// flight, and reports changes. Payouts in a terminal status stop being tracked.
+func (t *PayoutTracker) PollOnce(ctx context.Context) {
+	ids := t.Pending()
+	results := make(chan payoutPollResult, len(ids))
+	sem := make(chan struct{}, t.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, id := range ids {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			payout, err := t.client.Get(ctx, id)
+			results <- payoutPollResult{id: id, payout: payout, err: err}
+		}(id)
+	}
+	wg.Wait()
+	close(results)
+
+	for r := range results {
+		t.apply(ctx, r)
+	}
+}
+
+func (t *PayoutTracker) apply(ctx context.Context, r payoutPollResult) {
+	if r.err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(r.err, common.ErrNotFound) {
+			t.mu.Lock()
+			delete(t.pending, r.id)
+			t.mu.Unlock()
+		}
+		if t.opts.OnError != nil {
+			t.opts.OnError(r.id, r.err)
+		}
+		return
+	}
+
+	t.mu.Lock()
+	previous, tracked := t.pending[r.id]
+	if tracked {
+		if IsTerminalPayoutStatus(r.payout.PayoutStatus) {
+			delete(t.pending, r.id)
+		} else {
+			t.pending[r.id] = r.payout.PayoutStatus
+		}
+	}
+	t.mu.Unlock()
+
+	if tracked && previous != r.payout.PayoutStatus && t.opts.OnChange != nil {
+		t.opts.OnChange(PayoutStatusChange{PayoutID: r.id, Previous: previous, Payout: r.payout})
+	}
+}
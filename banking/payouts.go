@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // PayoutsClient handles payout operations
@@ -17,8 +18,8 @@ type Payout struct {
 	PayoutID         string            `json:"payout_id"`
 	ShortReferenceID string            `json:"short_reference_id"`
 	Currency         string            `json:"currency"`
-	Amount           string            `json:"amount"`
-	Fee              string            `json:"fee"`
+	Amount           money.Decimal     `json:"amount"`
+	Fee              money.Decimal     `json:"fee"`
 	PayoutPurpose    string            `json:"payout_purpose"`
 	PayoutStatus     string            `json:"payout_status"` // PENDING, PROCESSING, COMPLETED, FAILED, CANCELLED
 	Beneficiary      PayoutBeneficiary `json:"beneficiary"`
@@ -75,13 +76,16 @@ type CreatePayoutRequest struct {
 	Beneficiary *PayoutBeneficiary `json:"beneficiary,omitempty"`
 
 	// Required fields
-	Currency      string `json:"currency"`       // required, e.g., "USD", "KES", "UGX"
-	Amount        string `json:"amount"`         // required, decimal string
-	PayoutPurpose string `json:"payout_purpose"` // required, e.g., "salary", "vendor_payment", "refund"
+	Currency      string        `json:"currency"`       // required, e.g., "USD", "KES", "UGX"
+	Amount        money.Decimal `json:"amount"`         // required, decimal string
+	PayoutPurpose string        `json:"payout_purpose"` // required, e.g., "salary", "vendor_payment", "refund"
 
 	// Optional fields
 	Description string `json:"description,omitempty"`
 	Reference   string `json:"reference,omitempty"` // Client's internal reference
+
+	// IdempotencyKey is sent as x-idempotency-key; generated when empty
+	IdempotencyKey string `json:"-"`
 }
 
 // CreatePayoutResponse represents a payout creation response
@@ -90,17 +94,18 @@ type CreatePayoutResponse struct {
 	ShortReferenceID string `json:"short_reference_id"`
 	Status           string `json:"status"`
 	CreateTime       string `json:"create_time"`
+	IdempotencyKey   string `json:"-"` // idempotency key used for the request
 }
 
 // ListPayoutsRequest represents a payout list request
 type ListPayoutsRequest struct {
-	PageSize      int    `json:"page_size"`      // required, 10-100
-	PageNumber    int    `json:"page_number"`    // required, >=1
-	StartTime     string `json:"start_time"`     // optional, ISO8601
-	EndTime       string `json:"end_time"`       // optional, ISO8601
-	PayoutStatus  string `json:"payout_status"`  // optional: PENDING, PROCESSING, COMPLETED, FAILED, CANCELLED, ALL
-	Currency      string `json:"currency"`       // optional, filter by currency
-	BeneficiaryID string `json:"beneficiary_id"` // optional, filter by beneficiary
+	PageSize      int    `json:"page_size" url:"page_size"`           // required, 10-100
+	PageNumber    int    `json:"page_number" url:"page_number"`       // required, >=1
+	StartTime     string `json:"start_time" url:"start_time"`         // optional, ISO8601
+	EndTime       string `json:"end_time" url:"end_time"`             // optional, ISO8601
+	PayoutStatus  string `json:"payout_status" url:"payout_status"`   // optional: PENDING, PROCESSING, COMPLETED, FAILED, CANCELLED, ALL
+	Currency      string `json:"currency" url:"currency"`             // optional, filter by currency
+	BeneficiaryID string `json:"beneficiary_id" url:"beneficiary_id"` // optional, filter by beneficiary
 }
 
 // ListPayoutsResponse represents a payout list response
@@ -119,51 +124,64 @@ type PayoutDetailResponse struct {
 
 // TransactionDetails represents additional transaction information
 type TransactionDetails struct {
-	TransactionID     string `json:"transaction_id,omitempty"`
-	ProcessingTime    string `json:"processing_time,omitempty"`
-	SettlementTime    string `json:"settlement_time,omitempty"`
-	ExchangeRate      string `json:"exchange_rate,omitempty"`
-	ProcessorResponse string `json:"processor_response,omitempty"`
+	TransactionID     string         `json:"transaction_id,omitempty"`
+	ProcessingTime    string         `json:"processing_time,omitempty"`
+	SettlementTime    string         `json:"settlement_time,omitempty"`
+	ExchangeRate      *money.Decimal `json:"exchange_rate,omitempty"`
+	ProcessorResponse string         `json:"processor_response,omitempty"`
 }
 
 // Create creates a new payout
 func (c *PayoutsClient) Create(ctx context.Context, req *CreatePayoutRequest) (*CreatePayoutResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.payouts.create")
+	ctx, key := common.EnsureIdempotencyKey(ctx, req.IdempotencyKey)
 	var resp CreatePayoutResponse
 	if err := c.client.Post(ctx, "/v1/payouts", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create payout: %w", err)
 	}
+	resp.IdempotencyKey = key
 	return &resp, nil
 }
 
 // List lists payouts with filters and pagination
 func (c *PayoutsClient) List(ctx context.Context, req *ListPayoutsRequest) (*ListPayoutsResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.payouts.list")
 	var resp ListPayoutsResponse
-	path := fmt.Sprintf("/v1/payouts?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.StartTime != "" {
-		path += fmt.Sprintf("&start_time=%s", req.StartTime)
-	}
-	if req.EndTime != "" {
-		path += fmt.Sprintf("&end_time=%s", req.EndTime)
-	}
-	if req.PayoutStatus != "" {
-		path += fmt.Sprintf("&payout_status=%s", req.PayoutStatus)
-	}
-	if req.Currency != "" {
-		path += fmt.Sprintf("&currency=%s", req.Currency)
-	}
-	if req.BeneficiaryID != "" {
-		path += fmt.Sprintf("&beneficiary_id=%s", req.BeneficiaryID)
+	path, err := common.AppendQuery("/v1/payouts", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payouts: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list payouts: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all payouts matching req, starting at req.PageNumber
+func (c *PayoutsClient) Iter(req *ListPayoutsRequest) *common.Iterator[Payout] {
+	var query ListPayoutsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Payout], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Payout]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all payouts matching req, fetching every page
+func (c *PayoutsClient) ListAll(ctx context.Context, req *ListPayoutsRequest) ([]Payout, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Get retrieves a specific payout by ID
 func (c *PayoutsClient) Get(ctx context.Context, payoutID string) (*PayoutDetailResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.payouts.get")
 	var resp PayoutDetailResponse
 	path := fmt.Sprintf("/v1/payouts/%s", payoutID)
 	if err := c.client.Get(ctx, path, &resp); err != nil {
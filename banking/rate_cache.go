@@ -0,0 +1,368 @@
+package banking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// Where a cached rate comes from
+const (
+	RateSourceDirect  = "DIRECT"  // published by the API
+	RateSourceInverse = "INVERSE" // inverse of the published reverse pair
+	RateSourceCross   = "CROSS"   // triangulated through a pivot currency
+)
+
+// Defaults used by RateCache when options are zero
+const (
+	DefaultRateRefreshInterval = time.Minute
+	DefaultRateMaxAge          = 5 * time.Minute
+	DefaultRatePrecision       = 8
+)
+
+// ErrRateUnavailable is returned when a pair is neither published nor derivable from cached rates
+var ErrRateUnavailable = errors.New("uqpay: exchange rate unavailable")
+
+// Rate is a buy/sell price for a currency pair
+type Rate struct {
+	Pair        string        // "BASE/QUOTE", e.g. "SGD/EUR"
+	Buy         money.Decimal // quote currency paid to buy one unit of base
+	Sell        money.Decimal // quote currency received for selling one unit of base
+	Source      string        // RateSourceDirect, RateSourceInverse or RateSourceCross
+	Via         string        // pivot currency of a cross rate
+	LastUpdated time.Time     // when the API last updated the rates this one is based on
+	Stale       bool          // LastUpdated is older than MaxAge
+}
+
+// RateChange describes a published rate that was added, changed or removed by a refresh
+type RateChange struct {
+	Pair     string
+	Previous *Rate // nil when the pair is new
+	Current  *Rate // nil when the pair is no longer available
+}
+
+// RateCacheOptions configures a RateCache
+type RateCacheOptions struct {
+	// Pairs limits the rates fetched, e.g. "USD/EUR". Rates against each pivot currency are
+	// fetched too so pairs the API does not publish can be derived. All rates when empty.
+	Pairs []string
+
+	Pivots    []string      // currencies used to triangulate cross rates, "USD" when empty
+	Interval  time.Duration // refresh interval used by Run, DefaultRateRefreshInterval when zero
+	MaxAge    time.Duration // rates older than this are stale, DefaultRateMaxAge when zero
+	Precision int32         // decimal places of derived rates, DefaultRatePrecision when zero
+
+	OnError func(err error)  // called by Run when a refresh fails; cached rates are kept
+	Now     func() time.Time // clock used for staleness, time.Now when nil
+}
+
+// RateCache keeps exchange rates in memory, refreshing them on a schedule
+type RateCache struct {
+	client *ExchangeRatesClient
+	opts   RateCacheOptions
+
+	mu          sync.RWMutex
+	rates       map[string]*Rate // published rates by "BASE/QUOTE"
+	unavailable []string
+	lastUpdated time.Time
+
+	subMu       sync.Mutex
+	subscribers map[int]func(RateChange)
+	nextSub     int
+}
+
+// NewRateCache returns an empty cache that fetches rates through client. Call Refresh or Run
+// to populate it.
+func NewRateCache(client *ExchangeRatesClient, opts RateCacheOptions) *RateCache {
+	if len(opts.Pivots) == 0 {
+		opts.Pivots = []string{"USD"}
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultRateRefreshInterval
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultRateMaxAge
+	}
+	if opts.Precision <= 0 {
+		opts.Precision = DefaultRatePrecision
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &RateCache{
+		client:      client,
+		opts:        opts,
+		rates:       make(map[string]*Rate),
+		subscribers: make(map[int]func(RateChange)),
+	}
+}
+
+// Refresh fetches the latest rates and notifies subscribers of changes. On error the cached
+// rates are left unchanged.
+func (c *RateCache) Refresh(ctx context.Context) error {
+	var req *ListRatesRequest
+	if len(c.opts.Pairs) > 0 {
+		req = &ListRatesRequest{CurrencyPairs: c.requestPairs()}
+	}
+	resp, err := c.client.List(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to refresh exchange rates: %w", err)
+	}
+
+	lastUpdated, err := time.Parse(time.RFC3339, resp.Data.LastUpdated)
+	if err != nil {
+		lastUpdated = c.opts.Now()
+	}
+	rates := publishedRates(resp.Data.Rates, lastUpdated)
+
+	c.mu.Lock()
+	previous := c.rates
+	c.rates, c.unavailable, c.lastUpdated = rates, resp.Data.UnavailableCurrencyPairs, lastUpdated
+	c.mu.Unlock()
+
+	c.notify(diffRates(previous, rates))
+	return nil
+}
+
+// Run refreshes the rates immediately and then every Interval until ctx is done,
+// returning ctx.Err(). Failed refreshes are reported to OnError.
+func (c *RateCache) Run(ctx context.Context) error {
+	for {
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil && c.opts.OnError != nil {
+			c.opts.OnError(err)
+		}
+		if err := common.Sleep(ctx, c.opts.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Rate returns the buy/sell price of base in quote currency. Pairs the API does not publish
+// are derived from the inverse pair or through a pivot currency.
+func (c *RateCache) Rate(base, quote string) (*Rate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rate, ok := c.lookup(base, quote)
+	if !ok {
+		for _, pivot := range c.opts.Pivots {
+			pivot = strings.ToUpper(pivot)
+			if pivot == base || pivot == quote {
+				continue
+			}
+			if rate, ok = c.cross(base, pivot, quote); ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, base, quote)
+	}
+	rate.Stale = c.isStale()
+	return rate, nil
+}
+
+// Rates returns a copy of the published rates, sorted by pair
+func (c *RateCache) Rates() []Rate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rates := make([]Rate, 0, len(c.rates))
+	for _, rate := range c.rates {
+		r := *rate
+		r.Stale = c.isStale()
+		rates = append(rates, r)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Pair < rates[j].Pair })
+	return rates
+}
+
+// Unavailable returns the pairs the API reported as unavailable on the last refresh
+func (c *RateCache) Unavailable() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.unavailable...)
+}
+
+// LastUpdated returns when the API last updated the cached rates, zero before the first refresh
+func (c *RateCache) LastUpdated() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastUpdated
+}
+
+// Stale reports whether the cached rates are older than MaxAge or have never been fetched
+func (c *RateCache) Stale() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isStale()
+}
+
+func (c *RateCache) isStale() bool {
+	return c.lastUpdated.IsZero() || c.opts.Now().Sub(c.lastUpdated) > c.opts.MaxAge
+}
+
+// Subscribe registers fn to be called for every published rate that changes on a refresh.
+// Calls happen on the goroutine running Refresh. It returns a function that unsubscribes.
+func (c *RateCache) Subscribe(fn func(RateChange)) (unsubscribe func()) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	id := c.nextSub
+	c.nextSub++
+	c.subscribers[id] = fn
+	return func() {
+		c.subMu.Lock()
+		defer c.subMu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+func (c *RateCache) notify(changes []RateChange) {
+	if len(changes) == 0 {
+		return
+	}
+	c.subMu.Lock()
+	ids := make([]int, 0, len(c.subscribers))
+	for id := range c.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	subscribers := make([]func(RateChange), len(ids))
+	for i, id := range ids {
+		subscribers[i] = c.subscribers[id]
+	}
+	c.subMu.Unlock()
+
+	for _, change := range changes {
+		for _, fn := range subscribers {
+			fn(change)
+		}
+	}
+}
+
+// requestPairs returns the configured pairs plus each currency against every pivot
+func (c *RateCache) requestPairs() []string {
+	seen := make(map[string]bool)
+	var pairs []string
+	add := func(base, quote string) {
+		pair := base + "/" + quote
+		if base != quote && !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+	for _, p := range c.opts.Pairs {
+		base, quote, ok := splitPair(p)
+		if !ok {
+			continue
+		}
+		add(base, quote)
+		for _, pivot := range c.opts.Pivots {
+			pivot = strings.ToUpper(pivot)
+			add(pivot, base)
+			add(pivot, quote)
+		}
+	}
+	return pairs
+}
+
+// lookup returns a published rate or the inverse of the published reverse pair. c.mu is held.
+func (c *RateCache) lookup(base, quote string) (*Rate, bool) {
+	return lookupRate(c.rates, base, quote, c.opts.Precision)
+}
+
+// publishedRates indexes rates returned by the API by "BASE/QUOTE"
+func publishedRates(items []RateItem, lastUpdated time.Time) map[string]*Rate {
+	rates := make(map[string]*Rate, len(items))
+	for _, item := range items {
+		base, quote, ok := splitPair(item.CurrencyPair)
+		if !ok {
+			continue
+		}
+		pair := base + "/" + quote
+		rates[pair] = &Rate{Pair: pair, Buy: item.BuyPrice, Sell: item.SellPrice, Source: RateSourceDirect, LastUpdated: lastUpdated}
+	}
+	return rates
+}
+
+// lookupRate returns a copy of the published base/quote rate, or the inverse of the published
+// quote/base rate rounded to precision decimal places
+func lookupRate(rates map[string]*Rate, base, quote string, precision int32) (*Rate, bool) {
+	if rate, ok := rates[base+"/"+quote]; ok {
+		r := *rate
+		return &r, true
+	}
+	reverse, ok := rates[quote+"/"+base]
+	if !ok || reverse.Buy.Sign() <= 0 || reverse.Sell.Sign() <= 0 {
+		return nil, false
+	}
+	one := money.NewFromInt(1)
+	// Buying base means selling the reverse pair's base, and vice versa
+	buy, _ := one.Div(reverse.Sell, precision)
+	sell, _ := one.Div(reverse.Buy, precision)
+	return &Rate{Pair: base + "/" + quote, Buy: buy, Sell: sell, Source: RateSourceInverse, LastUpdated: reverse.LastUpdated}, true
+}
+
+// cross triangulates base/quote as base/pivot then pivot/quote. c.mu is held.
+func (c *RateCache) cross(base, pivot, quote string) (*Rate, bool) {
+	first, ok := c.lookup(base, pivot)
+	if !ok {
+		return nil, false
+	}
+	second, ok := c.lookup(pivot, quote)
+	if !ok {
+		return nil, false
+	}
+	lastUpdated := first.LastUpdated
+	if second.LastUpdated.Before(lastUpdated) {
+		lastUpdated = second.LastUpdated
+	}
+	return &Rate{
+		Pair:        base + "/" + quote,
+		Buy:         first.Buy.Mul(second.Buy).Round(c.opts.Precision),
+		Sell:        first.Sell.Mul(second.Sell).Round(c.opts.Precision),
+		Source:      RateSourceCross,
+		Via:         pivot,
+		LastUpdated: lastUpdated,
+	}, true
+}
+
+// diffRates lists the pairs added, changed or removed between two sets of published rates
+func diffRates(previous, current map[string]*Rate) []RateChange {
+	var changes []RateChange
+	for pair, rate := range current {
+		old, ok := previous[pair]
+		switch {
+		case !ok:
+			changes = append(changes, RateChange{Pair: pair, Current: rate})
+		case !old.Buy.Equal(rate.Buy) || !old.Sell.Equal(rate.Sell):
+			changes = append(changes, RateChange{Pair: pair, Previous: old, Current: rate})
+		}
+	}
+	for pair, old := range previous {
+		if _, ok := current[pair]; !ok {
+			changes = append(changes, RateChange{Pair: pair, Previous: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Pair < changes[j].Pair })
+	return changes
+}
+
+// splitPair parses "USD/EUR" or "USDEUR" into upper-case currency codes
+func splitPair(pair string) (base, quote string, ok bool) {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	if parts := strings.Split(pair, "/"); len(parts) == 2 {
+		return parts[0], parts[1], len(parts[0]) == 3 && len(parts[1]) == 3
+	}
+	if len(pair) == 6 {
+		return pair[:3], pair[3:], true
+	}
+	return "", "", false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // TransfersClient handles transfer operations
@@ -14,26 +15,26 @@ type TransfersClient struct {
 
 // Transfer represents a transfer between accounts
 type Transfer struct {
-	TransferID       string `json:"transfer_id"`
-	ShortReferenceID string `json:"short_reference_id"`
-	SourceAccountID  string `json:"source_account_id"`
-	TargetAccountID  string `json:"target_account_id"`
-	Currency         string `json:"currency"`
-	Amount           string `json:"amount"`
-	Reason           string `json:"reason"`
-	TransferStatus   string `json:"transfer_status"`
-	CreateTime       string `json:"create_time"`
-	CompletedTime    string `json:"completed_time"`
+	TransferID       string        `json:"transfer_id"`
+	ShortReferenceID string        `json:"short_reference_id"`
+	SourceAccountID  string        `json:"source_account_id"`
+	TargetAccountID  string        `json:"target_account_id"`
+	Currency         string        `json:"currency"`
+	Amount           money.Decimal `json:"amount"`
+	Reason           string        `json:"reason"`
+	TransferStatus   string        `json:"transfer_status"`
+	CreateTime       string        `json:"create_time"`
+	CompletedTime    string        `json:"completed_time"`
 }
 
 // ListTransfersRequest represents a transfer list request
 type ListTransfersRequest struct {
-	PageSize       int    `json:"page_size"`       // 10-100
-	PageNumber     int    `json:"page_number"`     // >=1
-	StartTime      string `json:"start_time"`      // optional, ISO8601
-	EndTime        string `json:"end_time"`        // optional, ISO8601
-	TransferStatus string `json:"transfer_status"` // optional: completed, failed
-	Currency       string `json:"currency"`        // optional
+	PageSize       int    `json:"page_size" url:"page_size"`             // 10-100
+	PageNumber     int    `json:"page_number" url:"page_number"`         // >=1
+	StartTime      string `json:"start_time" url:"start_time"`           // optional, ISO8601
+	EndTime        string `json:"end_time" url:"end_time"`               // optional, ISO8601
+	TransferStatus string `json:"transfer_status" url:"transfer_status"` // optional: completed, failed
+	Currency       string `json:"currency" url:"currency"`               // optional
 }
 
 // ListTransfersResponse represents a transfer list response
@@ -45,54 +46,72 @@ type ListTransfersResponse struct {
 
 // CreateTransferRequest represents a transfer creation request
 type CreateTransferRequest struct {
-	SourceAccountID string `json:"source_account_id"` // required, UUID
-	TargetAccountID string `json:"target_account_id"` // required, UUID
-	Currency        string `json:"currency"`          // required
-	Amount          string `json:"amount"`            // required
-	Reason          string `json:"reason"`            // required
+	SourceAccountID string        `json:"source_account_id"` // required, UUID
+	TargetAccountID string        `json:"target_account_id"` // required, UUID
+	Currency        string        `json:"currency"`          // required
+	Amount          money.Decimal `json:"amount"`            // required
+	Reason          string        `json:"reason"`            // required
+	IdempotencyKey  string        `json:"-"`                 // optional, generated when empty
 }
 
 // CreateTransferResponse represents a transfer creation response
 type CreateTransferResponse struct {
 	TransferID       string `json:"transfer_id"`
 	ShortReferenceID string `json:"short_reference_id"`
+	IdempotencyKey   string `json:"-"` // idempotency key used for the request
 }
 
 // List lists transfers
 func (c *TransfersClient) List(ctx context.Context, req *ListTransfersRequest) (*ListTransfersResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.transfers.list")
 	var resp ListTransfersResponse
-	path := fmt.Sprintf("/v1/transfer?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.StartTime != "" {
-		path += fmt.Sprintf("&start_time=%s", req.StartTime)
-	}
-	if req.EndTime != "" {
-		path += fmt.Sprintf("&end_time=%s", req.EndTime)
-	}
-	if req.TransferStatus != "" {
-		path += fmt.Sprintf("&transfer_status=%s", req.TransferStatus)
-	}
-	if req.Currency != "" {
-		path += fmt.Sprintf("&currency=%s", req.Currency)
+	path, err := common.AppendQuery("/v1/transfer", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list transfers: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all transfers matching req, starting at req.PageNumber
+func (c *TransfersClient) Iter(req *ListTransfersRequest) *common.Iterator[Transfer] {
+	var query ListTransfersRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Transfer], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Transfer]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all transfers matching req, fetching every page
+func (c *TransfersClient) ListAll(ctx context.Context, req *ListTransfersRequest) ([]Transfer, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Create creates a new transfer
 func (c *TransfersClient) Create(ctx context.Context, req *CreateTransferRequest) (*CreateTransferResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.transfers.create")
+	ctx, key := common.EnsureIdempotencyKey(ctx, req.IdempotencyKey)
 	var resp CreateTransferResponse
 	if err := c.client.Post(ctx, "/v1/transfer", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create transfer: %w", err)
 	}
+	resp.IdempotencyKey = key
 	return &resp, nil
 }
 
 // Get retrieves a specific transfer
 func (c *TransfersClient) Get(ctx context.Context, transferID string) (*Transfer, error) {
+	ctx = common.WithOperation(ctx, "banking.transfers.get")
 	var resp Transfer
 	path := fmt.Sprintf("/v1/transfer/%s", transferID)
 	if err := c.client.Get(ctx, path, &resp); err != nil {
@@ -38,8 +38,8 @@ type CurrencyBankDetail struct {
 
 // ListVirtualAccountsRequest represents a virtual account list request
 type ListVirtualAccountsRequest struct {
-	PageSize   int `json:"page_size"`   // required, 10-100
-	PageNumber int `json:"page_number"` // required, >=1
+	PageSize   int `json:"page_size" url:"page_size"`     // required, 10-100
+	PageNumber int `json:"page_number" url:"page_number"` // required, >=1
 }
 
 // ListVirtualAccountsResponse represents a virtual account list response
@@ -57,16 +57,43 @@ type CreateVirtualAccountRequest struct {
 
 // List lists virtual accounts
 func (c *VirtualAccountsClient) List(ctx context.Context, req *ListVirtualAccountsRequest) (*ListVirtualAccountsResponse, error) {
+	ctx = common.WithOperation(ctx, "banking.virtual_accounts.list")
 	var resp ListVirtualAccountsResponse
-	path := fmt.Sprintf("/v1/virtual/accounts?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
+	path, err := common.AppendQuery("/v1/virtual/accounts", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list virtual accounts: %w", err)
+	}
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list virtual accounts: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all virtual accounts matching req, starting at req.PageNumber
+func (c *VirtualAccountsClient) Iter(req *ListVirtualAccountsRequest) *common.Iterator[VirtualAccount] {
+	var query ListVirtualAccountsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[VirtualAccount], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[VirtualAccount]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all virtual accounts matching req, fetching every page
+func (c *VirtualAccountsClient) ListAll(ctx context.Context, req *ListVirtualAccountsRequest) ([]VirtualAccount, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Create creates a new virtual account
 func (c *VirtualAccountsClient) Create(ctx context.Context, req *CreateVirtualAccountRequest) (*VirtualAccount, error) {
+	ctx = common.WithOperation(ctx, "banking.virtual_accounts.create")
 	var resp VirtualAccount
 	if err := c.client.Post(ctx, "/v1/virtual/accounts", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create virtual account: %w", err)
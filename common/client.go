@@ -4,17 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 
-	"github.com/google/uuid"
 	"github.com/jackillll/uqpay-sdk-go/configuration"
 )
 
 // TokenProvider provides auth tokens
 type TokenProvider interface {
-	GetToken() (string, error)
+	GetToken(ctx context.Context) (string, error)
+}
+
+// TokenInvalidator is implemented by token providers that can discard a token the API rejected.
+// When a request fails with 401, APIClient invalidates the token and retries the request once.
+type TokenInvalidator interface {
+	InvalidateToken(token string)
 }
 
 // APIClient handles HTTP requests to UQPAY API
@@ -22,6 +28,27 @@ type APIClient struct {
 	Config        *configuration.Configuration
 	TokenProvider TokenProvider
 	HTTPClient    *http.Client
+	RetryPolicy   *RetryPolicy
+	Middleware    []Middleware
+	UserAgent     string      // sent as the User-Agent header when set
+	Header        http.Header // extra headers sent with every request, e.g. OnBehalfOfHeader
+}
+
+// OnBehalfOfHeader names the Connect sub-account a request acts for
+const OnBehalfOfHeader = "x-on-behalf-of"
+
+type accountIDKey struct{}
+
+// WithAccountID returns a context that makes requests act on behalf of a Connect sub-account,
+// overriding the client's account. An empty ID acts as the master account.
+func WithAccountID(ctx context.Context, accountID string) context.Context {
+	return context.WithValue(ctx, accountIDKey{}, accountID)
+}
+
+// AccountIDFromContext returns the account ID set on the context and whether one was set
+func AccountIDFromContext(ctx context.Context) (string, bool) {
+	accountID, ok := ctx.Value(accountIDKey{}).(string)
+	return accountID, ok
 }
 
 // NewAPIClient creates a new API client
@@ -35,63 +62,201 @@ func NewAPIClient(config *configuration.Configuration, tokenProvider TokenProvid
 		Config:        config,
 		TokenProvider: tokenProvider,
 		HTTPClient:    httpClient,
+		RetryPolicy:   DefaultRetryPolicy(),
 	}
 }
 
-// Do executes an HTTP request
-func (c *APIClient) Do(ctx context.Context, method, path string, body, response interface{}) error {
-	url := c.Config.Environment.BaseURL + path
+// WithAccount returns a copy of the client whose requests act on behalf of a Connect
+// sub-account. The copy shares the token provider, HTTP client and retry policy; an empty
+// ID returns a copy acting as the master account.
+func (c *APIClient) WithAccount(accountID string) *APIClient {
+	scoped := *c
+	scoped.Middleware = append([]Middleware(nil), c.Middleware...)
+	scoped.Header = c.Header.Clone()
+	if scoped.Header == nil {
+		scoped.Header = http.Header{}
+	}
+	if accountID == "" {
+		scoped.Header.Del(OnBehalfOfHeader)
+	} else {
+		scoped.Header.Set(OnBehalfOfHeader, accountID)
+	}
+	return &scoped
+}
+
+// Use appends middleware to the client's chain
+func (c *APIClient) Use(middleware ...Middleware) {
+	c.Middleware = append(c.Middleware, middleware...)
+}
 
-	var reqBody io.Reader
+// Do executes an HTTP request, retrying transient failures according to the retry policy.
+// The idempotency key is taken from the context (see WithIdempotencyKey) or generated, and
+// the same key is sent on every attempt so retried writes cannot execute twice.
+// Every attempt passes through the client's middleware chain.
+func (c *APIClient) Do(ctx context.Context, method, path string, body, response interface{}) error {
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonData)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	newBody := func() (io.Reader, string, error) {
+		if jsonData == nil {
+			return nil, "application/json", nil
+		}
+		return bytes.NewReader(jsonData), "application/json", nil
 	}
+	return c.do(ctx, method, path, newBody, true, response)
+}
 
-	// Get auth token
-	token, err := c.TokenProvider.GetToken()
-	if err != nil {
-		return fmt.Errorf("failed to get token: %w", err)
-	}
+// bodyFunc returns a fresh request body and its content type for each attempt
+type bodyFunc func() (io.Reader, string, error)
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-auth-token", token)
-	req.Header.Set("x-idempotency-key", uuid.New().String())
+// do runs the request loop shared by JSON and multipart requests. Retries are only attempted
+// when the body can be produced again.
+func (c *APIClient) do(ctx context.Context, method, path string, newBody bodyFunc, replayable bool, response interface{}) error {
+	url := c.Config.Environment.BaseURL + path
 
-	// Execute request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	policy := retryPolicyFromContext(ctx)
+	if policy == nil {
+		policy = c.RetryPolicy
+	}
+	if !replayable {
+		policy = NoRetry()
+	}
+	idempotencyKey := IdempotencyKeyFromContext(ctx)
+	if idempotencyKey == "" {
+		idempotencyKey = NewIdempotencyKey()
 	}
-	defer resp.Body.Close()
+	roundTrip := Chain(c.send(response), c.Middleware...)
+	reauthenticated := false
 
-	// Check for errors
-	if resp.StatusCode >= 400 {
-		var apiErr APIError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	for attempt := 1; ; attempt++ {
+		reqBody, contentType, err := newBody()
+		if err != nil {
+			return fmt.Errorf("failed to create request body: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			closeBody(reqBody)
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Get auth token
+		token, err := c.TokenProvider.GetToken(ctx)
+		if err != nil {
+			closeBody(reqBody)
+			return fmt.Errorf("failed to get token: %w", err)
+		}
+
+		// Set headers
+		for name, values := range c.Header {
+			req.Header[name] = append([]string(nil), values...)
+		}
+		if accountID, ok := AccountIDFromContext(ctx); ok {
+			req.Header.Del(OnBehalfOfHeader)
+			if accountID != "" {
+				req.Header.Set(OnBehalfOfHeader, accountID)
+			}
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("x-auth-token", token)
+		req.Header.Set("x-idempotency-key", idempotencyKey)
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+
+		// Execute request; a middleware may return without sending, so release streamed bodies here
+		resp, err := roundTrip(req)
+		closeBody(reqBody)
+		if err == nil {
+			return nil
 		}
-		apiErr.StatusCode = resp.StatusCode
-		return &apiErr
-	}
 
-	// Decode response
-	if response != nil {
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized && replayable && !reauthenticated {
+			// The token may have been revoked or expired early: fetch a new one and retry once
+			if invalidator, ok := c.TokenProvider.(TokenInvalidator); ok {
+				invalidator.InvalidateToken(token)
+				reauthenticated = true
+				attempt--
+				continue
+			}
+		}
+
+		if resp != nil && resp.StatusCode != 0 {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) {
+				apiErr.Method = method
+				apiErr.Path = path
+				apiErr.IdempotencyKey = idempotencyKey
+			}
+			if !policy.retryableStatus(resp.StatusCode) || attempt >= policy.attempts() {
+				return err
+			}
+			if Sleep(ctx, policy.delayFor(attempt, resp.Header)) != nil {
+				return err
+			}
+			continue
+		}
+
+		reqErr := &RequestError{Method: method, Path: path, IdempotencyKey: idempotencyKey, Err: err}
+		if ctx.Err() != nil || attempt >= policy.attempts() {
+			return reqErr
+		}
+		if Sleep(ctx, policy.delayFor(attempt, nil)) != nil {
+			return reqErr
 		}
 	}
+}
 
-	return nil
+// closeBody closes a request body that needs releasing, such as the read end of a pipe
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// send returns the innermost RoundTripFunc, which executes the request and decodes the
+// response into result
+func (c *APIClient) send(result interface{}) RoundTripFunc {
+	return func(req *http.Request) (*Response, error) {
+		httpResp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer httpResp.Body.Close()
+
+		data, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		resp := &Response{
+			Operation:  OperationFromContext(req.Context()),
+			StatusCode: httpResp.StatusCode,
+			Header:     httpResp.Header,
+			Body:       data,
+		}
+
+		// Check for errors
+		if httpResp.StatusCode >= 400 {
+			return resp, newAPIError(httpResp.StatusCode, httpResp.Header, data)
+		}
+
+		// Decode response
+		if result != nil {
+			if err := json.Unmarshal(data, result); err != nil {
+				return resp, fmt.Errorf("failed to decode response: %w", err)
+			}
+			resp.Result = result
+		}
+
+		return resp, nil
+	}
 }
 
 // Get sends a GET request
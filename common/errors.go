@@ -1,17 +1,165 @@
 package common
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by APIError through errors.Is
+var (
+	ErrNotFound            = errors.New("uqpay: not found")
+	ErrRateLimited         = errors.New("uqpay: rate limited")
+	ErrInsufficientBalance = errors.New("uqpay: insufficient balance")
+	ErrConflict            = errors.New("uqpay: conflict")
+	ErrValidation          = errors.New("uqpay: validation failed")
+)
+
+// requestIDHeaders lists the response headers that may carry the server request ID
+var requestIDHeaders = []string{"x-request-id", "x-trace-id", "request-id", "trace-id"}
+
+// FieldError represents a single entry of the details/errors array of an error response
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// UnmarshalJSON accepts both objects and plain message strings
+func (e *FieldError) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*e = FieldError{Message: message}
+		return nil
+	}
+
+	var raw struct {
+		Field   string `json:"field"`
+		Param   string `json:"param"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = FieldError{Field: raw.Field, Code: raw.Code, Message: raw.Message}
+	if e.Field == "" {
+		e.Field = raw.Param
+	}
+	if e.Message == "" {
+		e.Message = raw.Reason
+	}
+	return nil
+}
+
+// String formats the field error for display
+func (e FieldError) String() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
 
 // APIError represents an API error response
 type APIError struct {
-	Code       string `json:"code"`
-	Message    string `json:"message"`
-	StatusCode int    `json:"-"`
+	Code       string       `json:"code"`
+	Message    string       `json:"message"`
+	RequestID  string       `json:"request_id,omitempty"`
+	Details    []FieldError `json:"details,omitempty"`
+	Errors     []FieldError `json:"errors,omitempty"`
+	StatusCode int          `json:"-"`
+
+	Method         string      `json:"-"` // HTTP method of the failed request
+	Path           string      `json:"-"` // API path of the failed request
+	IdempotencyKey string      `json:"-"` // idempotency key sent with the failed request
+	Header         http.Header `json:"-"` // response headers
+	RawBody        []byte      `json:"-"` // undecoded response body
+}
+
+// newAPIError builds an APIError from an error response, keeping the raw body when it cannot be decoded
+func newAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		apiErr = &APIError{}
+	}
+	apiErr.StatusCode = statusCode
+	apiErr.Header = header
+	apiErr.RawBody = body
+
+	if apiErr.RequestID == "" {
+		for _, name := range requestIDHeaders {
+			if id := header.Get(name); id != "" {
+				apiErr.RequestID = id
+				break
+			}
+		}
+	}
+	return apiErr
 }
 
 // Error implements the error interface
 func (e *APIError) Error() string {
-	return fmt.Sprintf("%s: %s (HTTP %d)", e.Code, e.Message, e.StatusCode)
+	var msg string
+	switch {
+	case e.Code != "":
+		msg = fmt.Sprintf("%s: %s (HTTP %d)", e.Code, e.Message, e.StatusCode)
+	case e.Message != "":
+		msg = fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+	default:
+		msg = fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+
+	if fields := e.FieldErrors(); len(fields) > 0 {
+		parts := make([]string, len(fields))
+		for i, f := range fields {
+			parts[i] = f.String()
+		}
+		msg += " [" + strings.Join(parts, "; ") + "]"
+	}
+	if e.RequestID != "" {
+		msg += " (request_id: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// FieldErrors returns the field-level errors from both the details and errors arrays
+func (e *APIError) FieldErrors() []FieldError {
+	if len(e.Errors) == 0 {
+		return e.Details
+	}
+	if len(e.Details) == 0 {
+		return e.Errors
+	}
+	fields := make([]FieldError, 0, len(e.Details)+len(e.Errors))
+	fields = append(fields, e.Details...)
+	return append(fields, e.Errors...)
+}
+
+// Is maps the error code and HTTP status to the package sentinel errors
+func (e *APIError) Is(target error) bool {
+	code := strings.ToLower(e.Code)
+	insufficient := strings.Contains(code, "insufficient")
+
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || strings.Contains(code, "not_found")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || strings.Contains(code, "rate_limit")
+	case ErrInsufficientBalance:
+		return insufficient
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict || strings.Contains(code, "duplicate") || strings.Contains(code, "conflict")
+	case ErrValidation:
+		if insufficient {
+			return false
+		}
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity ||
+			strings.Contains(code, "invalid") || strings.Contains(code, "validation")
+	}
+	return false
 }
 
 // IsNotFound returns true if the error is a 404
@@ -28,3 +176,21 @@ func (e *APIError) IsUnauthorized() bool {
 func (e *APIError) IsBadRequest() bool {
 	return e.StatusCode == 400
 }
+
+// RequestError is returned when a request could not be sent or no response was received
+type RequestError struct {
+	Method         string
+	Path           string
+	IdempotencyKey string
+	Err            error
+}
+
+// Error implements the error interface
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.Err)
+}
+
+// Unwrap returns the underlying transport error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
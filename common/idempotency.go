@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey returns a context that makes the next request use the given idempotency key.
+// Persist the key before sending so a restarted process can resend the same operation safely.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key set on the context, if any
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// NewIdempotencyKey generates a random idempotency key
+func NewIdempotencyKey() string {
+	return uuid.New().String()
+}
+
+// EnsureIdempotencyKey resolves the idempotency key for a request: an explicit key wins,
+// then one already on the context, otherwise a new key is generated.
+// It returns a context carrying the key together with the key itself.
+func EnsureIdempotencyKey(ctx context.Context, key string) (context.Context, string) {
+	if key == "" {
+		key = IdempotencyKeyFromContext(ctx)
+	}
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+	return WithIdempotencyKey(ctx, key), key
+}
+
+// IdempotencyKeyFromError returns the idempotency key of the request that produced err
+func IdempotencyKeyFromError(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IdempotencyKey
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.IdempotencyKey
+	}
+	return ""
+}
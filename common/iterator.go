@@ -0,0 +1,133 @@
+package common
+
+import "context"
+
+// Page size bounds accepted by the list endpoints
+const (
+	MinPageSize     = 10
+	MaxPageSize     = 100
+	DefaultPageSize = MaxPageSize
+)
+
+// Page is a single page of a list response
+type Page[T any] struct {
+	TotalPages int
+	TotalItems int
+	Data       []T
+}
+
+// PageFetcher fetches one page of results
+type PageFetcher[T any] func(ctx context.Context, pageNumber, pageSize int) (*Page[T], error)
+
+// Iterator walks every item of a paginated list endpoint, fetching pages on demand.
+//
+//	it := client.Issuing.Cards.Iter(&issuing.ListCardsRequest{})
+//	for it.Next(ctx) {
+//		card := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle error
+//	}
+type Iterator[T any] struct {
+	fetch      PageFetcher[T]
+	pageNumber int
+	pageSize   int
+
+	items []T
+	index int
+	item  T
+	done  bool
+	err   error
+}
+
+// NewIterator creates an iterator starting at startPage (>= 1) with the given page size,
+// which is clamped to the 10-100 range the API accepts (0 selects the maximum)
+func NewIterator[T any](fetch PageFetcher[T], startPage, pageSize int) *Iterator[T] {
+	if startPage < 1 {
+		startPage = 1
+	}
+	return &Iterator[T]{
+		fetch:      fetch,
+		pageNumber: startPage,
+		pageSize:   ClampPageSize(pageSize),
+	}
+}
+
+// ClampPageSize bounds a page size to the range accepted by the API
+func ClampPageSize(pageSize int) int {
+	switch {
+	case pageSize <= 0:
+		return DefaultPageSize
+	case pageSize < MinPageSize:
+		return MinPageSize
+	case pageSize > MaxPageSize:
+		return MaxPageSize
+	}
+	return pageSize
+}
+
+// Next advances to the next item, fetching the next page when needed.
+// It returns false when there are no more items, an error occurred or ctx is done.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for it.index >= len(it.items) {
+		if it.done {
+			return false
+		}
+		page, err := it.fetch(ctx, it.pageNumber, it.pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = page.Data
+		it.index = 0
+		// Stop on an empty page, the last reported page, or a short page when totals are missing
+		if len(page.Data) == 0 ||
+			(page.TotalPages > 0 && it.pageNumber >= page.TotalPages) ||
+			(page.TotalPages == 0 && len(page.Data) < it.pageSize) {
+			it.done = true
+		}
+		it.pageNumber++
+	}
+
+	it.item = it.items[it.index]
+	it.index++
+	return true
+}
+
+// Item returns the current item
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Collect gathers up to limit items (all remaining items when limit <= 0)
+func (it *Iterator[T]) Collect(ctx context.Context, limit int) ([]T, error) {
+	var items []T
+	for (limit <= 0 || len(items) < limit) && it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
+
+// ForEach calls fn for every remaining item, stopping at the first error returned by fn
+func (it *Iterator[T]) ForEach(ctx context.Context, fn func(item T) error) error {
+	for it.Next(ctx) {
+		if err := fn(it.Item()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
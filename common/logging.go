@@ -0,0 +1,32 @@
+package common
+
+import (
+	"net/http"
+	"time"
+)
+
+// Logger is the logging interface used by the SDK; *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LoggingMiddleware logs one line per attempt with the operation, method, path, status and
+// duration. Headers and bodies are never logged, so tokens and card data stay out of logs.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			operation := OperationFromContext(req.Context())
+			switch {
+			case resp != nil:
+				logger.Printf("uqpay: %s %s %s -> %d (%s)", operation, req.Method, req.URL.Path, resp.StatusCode, elapsed)
+			case err != nil:
+				logger.Printf("uqpay: %s %s %s -> error: %v (%s)", operation, req.Method, req.URL.Path, err, elapsed)
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"context"
+	"net/http"
+)
+
+// Response is the outcome of a single attempt of an API call as seen by middleware
+type Response struct {
+	Operation  string      // operation name, e.g. "issuing.cards.create"
+	StatusCode int         // HTTP status code
+	Header     http.Header // response headers
+	Body       []byte      // raw response body
+	Result     interface{} // decoded response value, nil when the call failed or has no result
+}
+
+// RoundTripFunc performs one attempt of an API call. API failures are returned as *APIError
+// together with the Response; transport failures return a nil Response.
+type RoundTripFunc func(req *http.Request) (*Response, error)
+
+// Middleware wraps a RoundTripFunc to add logging, tracing, header injection and similar behavior
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Chain composes middleware so that the first one is the outermost
+func Chain(final RoundTripFunc, middleware ...Middleware) RoundTripFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			final = middleware[i](final)
+		}
+	}
+	return final
+}
+
+type operationKey struct{}
+
+// WithOperation returns a context carrying the operation name of the call being made
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// OperationFromContext returns the operation name set on the context, if any
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
+}
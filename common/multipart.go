@@ -0,0 +1,146 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrFileTooLarge is returned when an uploaded file exceeds MultipartFile.MaxSize
+var ErrFileTooLarge = errors.New("file exceeds maximum upload size")
+
+// MultipartFile is the file part of a multipart/form-data request
+type MultipartFile struct {
+	FieldName   string    // form field name, "file" when empty
+	FileName    string    // file name sent in the Content-Disposition header
+	ContentType string    // part content type, application/octet-stream when empty
+	Reader      io.Reader // file content; an io.Seeker allows the request to be retried
+	MaxSize     int64     // maximum size in bytes, 0 for no limit
+}
+
+// PostMultipart sends a multipart/form-data POST with the given form fields and file.
+// The body is streamed through an io.Pipe rather than buffered in memory. Requests are only
+// retried when the file reader implements io.Seeker, since the content must be sent again.
+func (c *APIClient) PostMultipart(ctx context.Context, path string, fields map[string]string, file *MultipartFile, response interface{}) error {
+	if file == nil || file.Reader == nil {
+		return fmt.Errorf("multipart file is required")
+	}
+	if size, ok := readerSize(file.Reader); ok && file.MaxSize > 0 && size > file.MaxSize {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrFileTooLarge, size, file.MaxSize)
+	}
+
+	seeker, replayable := file.Reader.(io.Seeker)
+	var start int64
+	if replayable {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			replayable = false
+		}
+		start = offset
+	}
+
+	// done is closed when the writer goroutine of the latest attempt has stopped reading the
+	// file. The pipe reader is closed after every attempt, so the writer exits promptly.
+	var done chan struct{}
+	newBody := func() (io.Reader, string, error) {
+		if done != nil {
+			<-done
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, "", err
+			}
+		}
+
+		pr, pw := io.Pipe()
+		writer := multipart.NewWriter(pw)
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			pw.CloseWithError(writeMultipart(writer, fields, file))
+		}(done)
+		return pr, writer.FormDataContentType(), nil
+	}
+
+	err := c.do(ctx, "POST", path, newBody, replayable, response)
+	if done != nil {
+		// Do not return while the file may still be read, the caller is free to close it
+		<-done
+	}
+	return err
+}
+
+// writeMultipart writes the form fields followed by the file part and closes the writer
+func writeMultipart(writer *multipart.Writer, fields map[string]string, file *MultipartFile) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := writer.WriteField(k, fields[k]); err != nil {
+			return fmt.Errorf("failed to write field %s: %w", k, err)
+		}
+	}
+
+	fieldName := file.FieldName
+	if fieldName == "" {
+		fieldName = "file"
+	}
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(fieldName), escapeQuotes(file.FileName)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+
+	src := file.Reader
+	if file.MaxSize > 0 {
+		src = io.LimitReader(file.Reader, file.MaxSize+1)
+	}
+	n, err := io.Copy(part, src)
+	if err != nil {
+		return fmt.Errorf("failed to copy file content: %w", err)
+	}
+	if file.MaxSize > 0 && n > file.MaxSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrFileTooLarge, file.MaxSize)
+	}
+
+	return writer.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// readerSize reports the remaining size of readers that expose it, such as *os.File,
+// *bytes.Reader and *strings.Reader
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - offset, true
+	}
+	return 0, false
+}
@@ -0,0 +1,165 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryValues encodes a struct into url.Values using `url:"name"` struct tags.
+//
+// Zero values are skipped; non-nil pointers are always encoded, even when they point to a
+// zero value. Slices are encoded as repeated parameters, or as a single comma-separated
+// parameter with the "comma" option (`url:"currency_pairs,comma"`). time.Time values are
+// encoded as RFC 3339. Fields without a url tag, or tagged "-", are ignored. A url.Values
+// argument is returned as is.
+func QueryValues(v interface{}) (url.Values, error) {
+	values := url.Values{}
+	if v == nil {
+		return values, nil
+	}
+	if q, ok := v.(url.Values); ok {
+		return q, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("query: expected struct, got %s", rv.Kind())
+	}
+
+	if err := encodeStruct(values, rv); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// AppendQuery encodes v with QueryValues and appends it to path
+func AppendQuery(path string, v interface{}) (string, error) {
+	values, err := QueryValues(v)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return path, nil
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + values.Encode(), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func encodeStruct(values url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		tag, hasTag := field.Tag.Lookup("url")
+		if field.Anonymous && !hasTag {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := encodeStruct(values, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !hasTag || tag == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		comma := opts == "comma"
+
+		// A set pointer is always encoded; anything else is skipped when zero
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		} else if fv.IsZero() {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type().Elem().Kind() != reflect.Uint8 {
+			items := make([]string, 0, fv.Len())
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formatValue(fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("query: field %s: %w", field.Name, err)
+				}
+				items = append(items, s)
+			}
+			if len(items) == 0 {
+				continue
+			}
+			if comma {
+				values.Add(name, strings.Join(items, ","))
+			} else {
+				for _, item := range items {
+					values.Add(name, item)
+				}
+			}
+			continue
+		}
+
+		s, err := formatValue(fv)
+		if err != nil {
+			return fmt.Errorf("query: field %s: %w", field.Name, err)
+		}
+		values.Add(name, s)
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Defaults used by Poll when WaitOptions fields are zero
+const (
+	DefaultWaitInterval    = time.Second
+	DefaultWaitMaxInterval = 15 * time.Second
+	DefaultWaitTimeout     = 5 * time.Minute
+)
+
+// ErrWaitTimeout is returned when a waiter gives up before reaching a terminal state
+var ErrWaitTimeout = errors.New("uqpay: timed out waiting for terminal state")
+
+// WaitOptions controls how waiters poll for an asynchronous operation to finish
+type WaitOptions struct {
+	Interval    time.Duration // delay before the second poll, doubled on each poll
+	MaxInterval time.Duration // upper bound for a single delay
+	Timeout     time.Duration // overall limit; negative waits until ctx is done
+	Jitter      float64       // fraction of each delay that is randomised, 0-1
+}
+
+func (o *WaitOptions) withDefaults() WaitOptions {
+	var opts WaitOptions
+	if o != nil {
+		opts = *o
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWaitInterval
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = DefaultWaitMaxInterval
+	}
+	if opts.MaxInterval < opts.Interval {
+		opts.MaxInterval = opts.Interval
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultWaitTimeout
+	}
+	return opts
+}
+
+// Poll calls check immediately and then with exponential backoff until it reports done or
+// returns an error. It returns ErrWaitTimeout when opts.Timeout elapses first and the
+// context error when ctx is cancelled.
+func Poll(ctx context.Context, opts *WaitOptions, check func(ctx context.Context) (bool, error)) error {
+	o := opts.withDefaults()
+	pollCtx := ctx
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+	// Reuse the retry backoff so polling and retries space out requests the same way
+	backoff := &RetryPolicy{BaseDelay: o.Interval, MaxDelay: o.MaxInterval, Jitter: o.Jitter}
+
+	for poll := 1; ; poll++ {
+		done, err := check(pollCtx)
+		if err != nil {
+			if pollCtx.Err() != nil {
+				return waitError(ctx)
+			}
+			return err
+		}
+		if done {
+			return nil
+		}
+		if err := Sleep(pollCtx, backoff.backoff(poll)); err != nil {
+			return waitError(ctx)
+		}
+	}
+}
+
+// waitError distinguishes the caller cancelling ctx from the wait timing out
+func waitError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrWaitTimeout
+}
@@ -1,17 +1,31 @@
 package configuration
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Environment names accepted by FromEnv and FromFile
+const (
+	EnvironmentSandbox    = "sandbox"
+	EnvironmentProduction = "production"
+	EnvironmentCustom     = "custom"
+)
 
 // Environment represents the UQPAY API environment
 type Environment struct {
-	BaseURL     string
+	Name         string // sandbox, production or custom
+	BaseURL      string
 	FilesBaseURL string
 }
 
 // Sandbox returns the sandbox environment
 func Sandbox() *Environment {
 	return &Environment{
-		BaseURL:     "https://api-sandbox.uqpaytech.com/api",
+		Name:         EnvironmentSandbox,
+		BaseURL:      "https://api-sandbox.uqpaytech.com/api",
 		FilesBaseURL: "https://files.uqpaytech.com/api",
 	}
 }
@@ -19,7 +33,8 @@ func Sandbox() *Environment {
 // Production returns the production environment
 func Production() *Environment {
 	return &Environment{
-		BaseURL:     "https://api.uqpay.com/api",
+		Name:         EnvironmentProduction,
+		BaseURL:      "https://api.uqpay.com/api",
 		FilesBaseURL: "https://files.uqpay.com/api",
 	}
 }
@@ -30,4 +45,49 @@ type Configuration struct {
 	APIKey      string
 	Environment *Environment
 	HTTPClient  *http.Client
+	Timeout     time.Duration // HTTP timeout, 0 for the SDK default
+	Retry       *RetryConfig  // nil for the SDK default retry policy
+	AccountID   string        // Connect sub-account to act on behalf of, empty for the master account
+}
+
+// RetryConfig holds retry settings loaded from the environment or a config file
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// String describes the configuration with the API key redacted, so it is safe to log.
+// It has a value receiver so that values and structs embedding one by value are redacted too.
+func (c Configuration) String() string {
+	apiKey := ""
+	if c.APIKey != "" {
+		apiKey = "[REDACTED]"
+	}
+	parts := []string{
+		"ClientID=" + c.ClientID,
+		"APIKey=" + apiKey,
+	}
+	if c.Environment != nil {
+		parts = append(parts,
+			"Environment="+c.Environment.Name,
+			"BaseURL="+c.Environment.BaseURL,
+			"FilesBaseURL="+c.Environment.FilesBaseURL)
+	}
+	if c.Timeout > 0 {
+		parts = append(parts, "Timeout="+c.Timeout.String())
+	}
+	if c.Retry != nil {
+		parts = append(parts, fmt.Sprintf("Retry={MaxAttempts=%d BaseDelay=%s MaxDelay=%s}",
+			c.Retry.MaxAttempts, c.Retry.BaseDelay, c.Retry.MaxDelay))
+	}
+	if c.AccountID != "" {
+		parts = append(parts, "AccountID="+c.AccountID)
+	}
+	return "Configuration{" + strings.Join(parts, " ") + "}"
+}
+
+// GoString redacts the API key for the %#v verb as well
+func (c Configuration) GoString() string {
+	return c.String()
 }
@@ -0,0 +1,212 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by FromEnv
+const (
+	EnvClientID         = "UQPAY_CLIENT_ID"
+	EnvAPIKey           = "UQPAY_API_KEY"
+	EnvEnvironment      = "UQPAY_ENVIRONMENT" // sandbox (default), production or custom
+	EnvBaseURL          = "UQPAY_BASE_URL"
+	EnvFilesBaseURL     = "UQPAY_FILES_BASE_URL"
+	EnvTimeout          = "UQPAY_TIMEOUT" // Go duration, e.g. "30s"
+	EnvRetryMaxAttempts = "UQPAY_RETRY_MAX_ATTEMPTS"
+	EnvRetryBaseDelay   = "UQPAY_RETRY_BASE_DELAY"
+	EnvRetryMaxDelay    = "UQPAY_RETRY_MAX_DELAY"
+	EnvAccountID        = "UQPAY_ACCOUNT_ID"
+)
+
+// ErrInvalidConfig is wrapped by every error returned by FromEnv and FromFile
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// fileConfig is the layout of a config file. Durations are Go duration strings.
+type fileConfig struct {
+	ClientID     string           `json:"client_id"`
+	APIKey       string           `json:"api_key"`
+	Environment  string           `json:"environment"`
+	BaseURL      string           `json:"base_url"`
+	FilesBaseURL string           `json:"files_base_url"`
+	Timeout      string           `json:"timeout"`
+	Retry        *retryFileConfig `json:"retry"`
+	AccountID    string           `json:"account_id"`
+}
+
+type retryFileConfig struct {
+	MaxAttempts intValue `json:"max_attempts"`
+	BaseDelay   string   `json:"base_delay"`
+	MaxDelay    string   `json:"max_delay"`
+}
+
+// intValue is an int that also decodes from a string, as YAML scalars are decoded as strings
+type intValue int
+
+func (v *intValue) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("expected an integer, got %s", data)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+// FromEnv builds a Configuration from UQPAY_* environment variables
+func FromEnv() (*Configuration, error) {
+	fc := fileConfig{
+		ClientID:     os.Getenv(EnvClientID),
+		APIKey:       os.Getenv(EnvAPIKey),
+		Environment:  os.Getenv(EnvEnvironment),
+		BaseURL:      os.Getenv(EnvBaseURL),
+		FilesBaseURL: os.Getenv(EnvFilesBaseURL),
+		Timeout:      os.Getenv(EnvTimeout),
+		AccountID:    os.Getenv(EnvAccountID),
+	}
+
+	maxAttempts := os.Getenv(EnvRetryMaxAttempts)
+	baseDelay := os.Getenv(EnvRetryBaseDelay)
+	maxDelay := os.Getenv(EnvRetryMaxDelay)
+	if maxAttempts != "" || baseDelay != "" || maxDelay != "" {
+		fc.Retry = &retryFileConfig{BaseDelay: baseDelay, MaxDelay: maxDelay}
+		if maxAttempts != "" {
+			n, err := strconv.Atoi(maxAttempts)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be an integer, got %q", ErrInvalidConfig, EnvRetryMaxAttempts, maxAttempts)
+			}
+			fc.Retry.MaxAttempts = intValue(n)
+		}
+	}
+
+	return fc.build(map[string]string{
+		"client_id":        EnvClientID,
+		"api_key":          EnvAPIKey,
+		"environment":      EnvEnvironment,
+		"base_url":         EnvBaseURL,
+		"files_base_url":   EnvFilesBaseURL,
+		"timeout":          EnvTimeout,
+		"retry.base_delay": EnvRetryBaseDelay,
+		"retry.max_delay":  EnvRetryMaxDelay,
+	})
+}
+
+// FromFile builds a Configuration from a JSON (.json) or YAML (.yaml, .yml) file with the keys
+// client_id, api_key, environment, base_url, files_base_url, timeout, retry (max_attempts,
+// base_delay, max_delay) and account_id. YAML support covers nested mappings of scalars,
+// which is all the config layout needs.
+func FromFile(path string) (*Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		values, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+		}
+		if data, err = json.Marshal(values); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported config file type %q", ErrInvalidConfig, ext)
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return fc.build(nil)
+}
+
+// build validates fc and converts it to a Configuration. names maps config keys to the
+// names used in error messages.
+func (fc *fileConfig) build(names map[string]string) (*Configuration, error) {
+	name := func(key string) string {
+		if n, ok := names[key]; ok {
+			return n
+		}
+		return key
+	}
+
+	var missing []string
+	if fc.ClientID == "" {
+		missing = append(missing, name("client_id"))
+	}
+	if fc.APIKey == "" {
+		missing = append(missing, name("api_key"))
+	}
+
+	var env *Environment
+	switch strings.ToLower(fc.Environment) {
+	case "", EnvironmentSandbox:
+		env = Sandbox()
+	case EnvironmentProduction:
+		env = Production()
+	case EnvironmentCustom:
+		env = &Environment{Name: EnvironmentCustom}
+		if fc.BaseURL == "" {
+			missing = append(missing, name("base_url"))
+		}
+		if fc.FilesBaseURL == "" {
+			missing = append(missing, name("files_base_url"))
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s must be sandbox, production or custom, got %q",
+			ErrInvalidConfig, name("environment"), fc.Environment)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidConfig, strings.Join(missing, ", "))
+	}
+
+	// Explicit URLs override the named environment
+	if fc.BaseURL != "" {
+		env.BaseURL = fc.BaseURL
+	}
+	if fc.FilesBaseURL != "" {
+		env.FilesBaseURL = fc.FilesBaseURL
+	}
+
+	config := &Configuration{
+		ClientID:    fc.ClientID,
+		APIKey:      fc.APIKey,
+		Environment: env,
+		AccountID:   fc.AccountID,
+	}
+
+	var err error
+	if config.Timeout, err = parseDuration(fc.Timeout, name("timeout")); err != nil {
+		return nil, err
+	}
+	if fc.Retry != nil {
+		config.Retry = &RetryConfig{MaxAttempts: int(fc.Retry.MaxAttempts)}
+		if config.Retry.BaseDelay, err = parseDuration(fc.Retry.BaseDelay, name("retry.base_delay")); err != nil {
+			return nil, err
+		}
+		if config.Retry.MaxDelay, err = parseDuration(fc.Retry.MaxDelay, name("retry.max_delay")); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// parseDuration parses an optional, non-negative Go duration
+func parseDuration(value, name string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %s must be a duration such as \"30s\", got %q", ErrInvalidConfig, name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,134 @@
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a non-blank, non-comment line of a YAML document
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML parses the YAML subset used by config files: nested mappings of scalars, with
+// # comments. Scalars are returned as strings so that values such as numeric client IDs keep
+// their exact text. Sequences, anchors and multi-line scalars
+// are not supported.
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || strings.HasPrefix(text, "#") || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(raw) - len(text), text: text})
+	}
+
+	values, rest, err := parseYAMLMapping(lines, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("line %d: unexpected indentation", rest[0].number)
+	}
+	return values, nil
+}
+
+// parseYAMLMapping parses consecutive "key: value" lines at the given indent and returns
+// the lines left over
+func parseYAMLMapping(lines []yamlLine, indent int) (map[string]interface{}, []yamlLine, error) {
+	values := make(map[string]interface{})
+	for len(lines) > 0 {
+		line := lines[0]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+		if strings.HasPrefix(line.text, "- ") || line.text == "-" {
+			return nil, nil, fmt.Errorf("line %d: sequences are not supported", line.number)
+		}
+
+		key, value, ok := strings.Cut(line.text, ":")
+		if !ok || (value != "" && value[0] != ' ') {
+			return nil, nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		key = strings.TrimSpace(key)
+		lines = lines[1:]
+
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "#") {
+			// Nested mapping, or an empty value
+			if len(lines) > 0 && lines[0].indent > indent {
+				nested, rest, err := parseYAMLMapping(lines, lines[0].indent)
+				if err != nil {
+					return nil, nil, err
+				}
+				values[key] = nested
+				lines = rest
+			} else {
+				values[key] = nil
+			}
+			continue
+		}
+
+		scalar, err := parseYAMLScalar(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line.number, err)
+		}
+		values[key] = scalar
+	}
+	return values, lines, nil
+}
+
+// parseYAMLScalar parses a quoted or plain scalar, dropping a trailing comment
+func parseYAMLScalar(value string) (interface{}, error) {
+	switch value[0] {
+	case '"', '\'':
+		end := closingQuote(value)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string %s", value)
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, fmt.Errorf("unexpected %q after string", rest)
+		}
+		if value[0] == '"' {
+			return strconv.Unquote(value[:end+1])
+		}
+		return strings.ReplaceAll(value[1:end], "''", "'"), nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	if value == "null" || value == "~" {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// closingQuote returns the index of the quote closing the string that value starts with, or -1.
+// Double-quoted strings escape with a backslash, single-quoted ones by doubling the quote.
+func closingQuote(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] != quote:
+		case quote == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		default:
+			return i
+		}
+	}
+	return -1
+}
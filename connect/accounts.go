@@ -3,6 +3,7 @@ package connect
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
 )
@@ -110,9 +111,9 @@ type AccountRequirements struct {
 
 // ListAccountsRequest represents an accounts list request
 type ListAccountsRequest struct {
-	PageSize   int    `json:"page_size,omitempty"`
-	PageNumber int    `json:"page_number,omitempty"`
-	Status     string `json:"status,omitempty"`
+	PageSize   int    `json:"page_size,omitempty" url:"page_size"`
+	PageNumber int    `json:"page_number,omitempty" url:"page_number"`
+	Status     string `json:"status,omitempty" url:"status"`
 }
 
 // ListAccountsResponse represents an accounts list response
@@ -155,6 +156,7 @@ type GetAdditionalDocumentsResponse struct {
 
 // CreateSubAccount creates a new sub-account using the new API endpoint
 func (c *AccountsClient) CreateSubAccount(ctx context.Context, req *CreateAccountRequest) (*Account, error) {
+	ctx = common.WithOperation(ctx, "connect.accounts.create_sub_account")
 	// Validate discriminated union
 	if req.EntityType == EntityTypeIndividual && req.Individual == nil {
 		return nil, fmt.Errorf("individual details required for INDIVIDUAL entity type")
@@ -172,8 +174,9 @@ func (c *AccountsClient) CreateSubAccount(ctx context.Context, req *CreateAccoun
 
 // GetAdditionalDocuments retrieves additional required documents for an account
 func (c *AccountsClient) GetAdditionalDocuments(ctx context.Context, accountID string) (*GetAdditionalDocumentsResponse, error) {
+	ctx = common.WithOperation(ctx, "connect.accounts.get_additional_documents")
 	var resp GetAdditionalDocumentsResponse
-	path := fmt.Sprintf("/v1/accounts/get_additional?account_id=%s", accountID)
+	path := "/v1/accounts/get_additional?" + url.Values{"account_id": {accountID}}.Encode()
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to get additional documents: %w", err)
 	}
@@ -182,6 +185,7 @@ func (c *AccountsClient) GetAdditionalDocuments(ctx context.Context, accountID s
 
 // Create creates a new account using the legacy API endpoint
 func (c *AccountsClient) Create(ctx context.Context, req *CreateAccountRequest) (*Account, error) {
+	ctx = common.WithOperation(ctx, "connect.accounts.create")
 	// Validate discriminated union
 	if req.EntityType == EntityTypeIndividual && req.Individual == nil {
 		return nil, fmt.Errorf("individual details required for INDIVIDUAL entity type")
@@ -199,32 +203,43 @@ func (c *AccountsClient) Create(ctx context.Context, req *CreateAccountRequest)
 
 // List lists accounts with optional filters
 func (c *AccountsClient) List(ctx context.Context, req *ListAccountsRequest) (*ListAccountsResponse, error) {
+	ctx = common.WithOperation(ctx, "connect.accounts.list")
 	var resp ListAccountsResponse
-	path := "/v1/accounts?"
-
-	if req.PageSize > 0 {
-		path += fmt.Sprintf("page_size=%d&", req.PageSize)
-	}
-	if req.PageNumber > 0 {
-		path += fmt.Sprintf("page_number=%d&", req.PageNumber)
-	}
-	if req.Status != "" {
-		path += fmt.Sprintf("status=%s&", req.Status)
-	}
-
-	// Remove trailing '?' or '&'
-	if path[len(path)-1] == '?' || path[len(path)-1] == '&' {
-		path = path[:len(path)-1]
+	path, err := common.AppendQuery("/v1/accounts", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list accounts: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all accounts matching req, starting at req.PageNumber
+func (c *AccountsClient) Iter(req *ListAccountsRequest) *common.Iterator[Account] {
+	var query ListAccountsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Account], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Account]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all accounts matching req, fetching every page
+func (c *AccountsClient) ListAll(ctx context.Context, req *ListAccountsRequest) ([]Account, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // Update updates an existing account
 func (c *AccountsClient) Update(ctx context.Context, accountID string, req *UpdateAccountRequest) (*Account, error) {
+	ctx = common.WithOperation(ctx, "connect.accounts.update")
 	var account Account
 	path := fmt.Sprintf("/v1/accounts/%s", accountID)
 	if err := c.client.Post(ctx, path, req, &account); err != nil {
@@ -235,6 +250,7 @@ func (c *AccountsClient) Update(ctx context.Context, accountID string, req *Upda
 
 // Get retrieves an account by ID
 func (c *AccountsClient) Get(ctx context.Context, accountID string) (*Account, error) {
+	ctx = common.WithOperation(ctx, "connect.accounts.get")
 	var account Account
 	path := fmt.Sprintf("/v1/accounts/%s", accountID)
 	if err := c.client.Get(ctx, path, &account); err != nil {
@@ -34,8 +34,8 @@ type Cardholder struct {
 
 // ListCardholdersRequest represents a cardholder list request
 type ListCardholdersRequest struct {
-	PageSize   int `json:"page_size"`
-	PageNumber int `json:"page_number"`
+	PageSize   int `json:"page_size" url:"page_size"`
+	PageNumber int `json:"page_number" url:"page_number"`
 }
 
 // ListCardholdersResponse represents a cardholder list response
@@ -47,6 +47,7 @@ type ListCardholdersResponse struct {
 
 // Create creates a new cardholder
 func (c *CardholdersClient) Create(ctx context.Context, req *CreateCardholderRequest) (*Cardholder, error) {
+	ctx = common.WithOperation(ctx, "issuing.cardholders.create")
 	var cardholder Cardholder
 	if err := c.client.Post(ctx, "/v1/issuing/cardholders", req, &cardholder); err != nil {
 		return nil, fmt.Errorf("failed to create cardholder: %w", err)
@@ -56,6 +57,7 @@ func (c *CardholdersClient) Create(ctx context.Context, req *CreateCardholderReq
 
 // Get retrieves a cardholder by ID
 func (c *CardholdersClient) Get(ctx context.Context, cardholderID string) (*Cardholder, error) {
+	ctx = common.WithOperation(ctx, "issuing.cardholders.get")
 	var cardholder Cardholder
 	path := fmt.Sprintf("/v1/issuing/cardholders/%s", cardholderID)
 	if err := c.client.Get(ctx, path, &cardholder); err != nil {
@@ -66,10 +68,36 @@ func (c *CardholdersClient) Get(ctx context.Context, cardholderID string) (*Card
 
 // List lists cardholders
 func (c *CardholdersClient) List(ctx context.Context, req *ListCardholdersRequest) (*ListCardholdersResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cardholders.list")
 	var resp ListCardholdersResponse
-	path := fmt.Sprintf("/v1/issuing/cardholders?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
+	path, err := common.AppendQuery("/v1/issuing/cardholders", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cardholders: %w", err)
+	}
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list cardholders: %w", err)
 	}
 	return &resp, nil
 }
+
+// Iter returns an iterator over all cardholders matching req, starting at req.PageNumber
+func (c *CardholdersClient) Iter(req *ListCardholdersRequest) *common.Iterator[Cardholder] {
+	var query ListCardholdersRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Cardholder], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Cardholder]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all cardholders matching req, fetching every page
+func (c *CardholdersClient) ListAll(ctx context.Context, req *ListCardholdersRequest) ([]Cardholder, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
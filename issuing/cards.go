@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // CardsClient handles card operations
@@ -18,7 +19,7 @@ type CardsClient struct {
 
 // CreateCardRequest represents a card creation request
 type CreateCardRequest struct {
-	CardLimit        *float64          `json:"card_limit,omitempty"`
+	CardLimit        *money.Number     `json:"card_limit,omitempty"`
 	CardCurrency     string            `json:"card_currency"`
 	CardholderID     string            `json:"cardholder_id"`
 	CardProductID    string            `json:"card_product_id"`
@@ -29,8 +30,8 @@ type CreateCardRequest struct {
 
 // SpendingControl represents spending control rules for a card
 type SpendingControl struct {
-	Amount   float64 `json:"amount"`
-	Interval string  `json:"interval"` // PER_TRANSACTION
+	Amount   money.Number `json:"amount"`
+	Interval string       `json:"interval"` // PER_TRANSACTION
 }
 
 // RiskControls represents user-customized risk control settings
@@ -42,8 +43,8 @@ type RiskControls struct {
 
 // CardUpdateRequest represents a card update request
 type CardUpdateRequest struct {
-	CardLimit          *float64          `json:"card_limit,omitempty"`
-	NoPINPaymentAmount *float64          `json:"no_pin_payment_amount,omitempty"`
+	CardLimit          *money.Number     `json:"card_limit,omitempty"`
+	NoPINPaymentAmount *money.Number     `json:"no_pin_payment_amount,omitempty"`
 	SpendingControls   []SpendingControl `json:"spending_controls,omitempty"`
 	RiskControls       *RiskControls     `json:"risk_controls,omitempty"`
 	Metadata           map[string]string `json:"metadata,omitempty"`
@@ -57,15 +58,16 @@ type UpdateCardStatusRequest struct {
 
 // CardOrderRequest represents a card recharge/withdraw request
 type CardOrderRequest struct {
-	Amount float64 `json:"amount"`
+	Amount         money.Number `json:"amount"`
+	IdempotencyKey string       `json:"-"` // optional, generated when empty
 }
 
 // ActivateCardRequest represents a card activation request
 type ActivateCardRequest struct {
-	CardID             string   `json:"card_id"`
-	ActivationCode     string   `json:"activation_code"`
-	PIN                string   `json:"pin"`
-	NoPINPaymentAmount *float64 `json:"no_pin_payment_amount,omitempty"`
+	CardID             string        `json:"card_id"`
+	ActivationCode     string        `json:"activation_code"`
+	PIN                string        `json:"pin"`
+	NoPINPaymentAmount *money.Number `json:"no_pin_payment_amount,omitempty"`
 }
 
 // SetPINRequest represents a card PIN reset request
@@ -90,11 +92,11 @@ type BulkCardCreationRequest struct {
 
 // ListCardsRequest represents a card list request
 type ListCardsRequest struct {
-	PageSize     int     `json:"page_size"`
-	PageNumber   int     `json:"page_number"`
-	CardNumber   *string `json:"card_number,omitempty"`
-	CardStatus   *string `json:"card_status,omitempty"`
-	CardholderID *string `json:"cardholder_id,omitempty"`
+	PageSize     int     `json:"page_size" url:"page_size"`
+	PageNumber   int     `json:"page_number" url:"page_number"`
+	CardNumber   *string `json:"card_number,omitempty" url:"card_number"`
+	CardStatus   *string `json:"card_status,omitempty" url:"card_status"`
+	CardholderID *string `json:"cardholder_id,omitempty" url:"cardholder_id"`
 }
 
 // ============================================================================
@@ -136,16 +138,16 @@ type RetrieveCardResponse struct {
 	FormFactor         string            `json:"form_factor"`
 	ModeType           string            `json:"mode_type"`
 	CardProductID      string            `json:"card_product_id"`
-	CardLimit          float64           `json:"card_limit"`
-	AvailableBalance   string            `json:"available_balance"`
+	CardLimit          money.Decimal     `json:"card_limit"`
+	AvailableBalance   money.Decimal     `json:"available_balance"`
 	Cardholder         CardholderInfo    `json:"cardholder"`
 	SpendingControls   []SpendingControl `json:"spending_controls,omitempty"`
-	NoPINPaymentAmount string            `json:"no_pin_payment_amount"`
+	NoPINPaymentAmount money.Decimal     `json:"no_pin_payment_amount"`
 	RiskControls       *RiskControls     `json:"risk_controls,omitempty"`
 	Metadata           map[string]string `json:"metadata,omitempty"`
 	CardStatus         string            `json:"card_status"`
 	UpdateReason       *string           `json:"update_reason,omitempty"`
-	ConsumedAmount     *string           `json:"consumed_amount,omitempty"`
+	ConsumedAmount     *money.Decimal    `json:"consumed_amount,omitempty"`
 }
 
 // CardholderInfo represents cardholder information in card response
@@ -171,15 +173,18 @@ type SecureCardInfo struct {
 
 // CardOrder represents a card order
 type CardOrder struct {
-	CardID       string  `json:"card_id"`
-	CardOrderID  string  `json:"card_order_id"`
-	OrderType    string  `json:"order_type"`
-	Amount       float64 `json:"amount"`
-	CardCurrency string  `json:"card_currency"`
-	CreateTime   string  `json:"create_time"`
-	UpdateTime   string  `json:"update_time"`
-	CompleteTime string  `json:"complete_time"`
-	OrderStatus  string  `json:"order_status"`
+	CardID       string        `json:"card_id"`
+	CardOrderID  string        `json:"card_order_id"`
+	OrderType    string        `json:"order_type"`
+	Amount       money.Decimal `json:"amount"`
+	CardCurrency string        `json:"card_currency"`
+	CreateTime   string        `json:"create_time"`
+	UpdateTime   string        `json:"update_time"`
+	CompleteTime string        `json:"complete_time"`
+	OrderStatus  string        `json:"order_status"`
+
+	// IdempotencyKey is the key used by Recharge or Withdraw; empty for GetOrder
+	IdempotencyKey string `json:"-"`
 }
 
 // ActivateCardResponse represents the response after activating a card
@@ -220,6 +225,7 @@ type ListCardsResponse struct {
 
 // Create creates a new card
 func (c *CardsClient) Create(ctx context.Context, req *CreateCardRequest) (*CardCreationResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.create")
 	var resp CardCreationResponse
 	if err := c.client.Post(ctx, "/v1/issuing/cards", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to create card: %w", err)
@@ -229,6 +235,7 @@ func (c *CardsClient) Create(ctx context.Context, req *CreateCardRequest) (*Card
 
 // Update updates the specified issuing card
 func (c *CardsClient) Update(ctx context.Context, cardID string, req *CardUpdateRequest) (*CardUpdatedResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.update")
 	var resp CardUpdatedResponse
 	path := fmt.Sprintf("/v1/issuing/cards/%s", cardID)
 	if err := c.client.Post(ctx, path, req, &resp); err != nil {
@@ -239,6 +246,7 @@ func (c *CardsClient) Update(ctx context.Context, cardID string, req *CardUpdate
 
 // Get retrieves a card by ID
 func (c *CardsClient) Get(ctx context.Context, cardID string) (*RetrieveCardResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.get")
 	var card RetrieveCardResponse
 	path := fmt.Sprintf("/v1/issuing/cards/%s", cardID)
 	if err := c.client.Get(ctx, path, &card); err != nil {
@@ -249,6 +257,7 @@ func (c *CardsClient) Get(ctx context.Context, cardID string) (*RetrieveCardResp
 
 // GetSecure retrieves secure card information
 func (c *CardsClient) GetSecure(ctx context.Context, cardID string) (*SecureCardInfo, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.get_secure")
 	var info SecureCardInfo
 	path := fmt.Sprintf("/v1/issuing/cards/%s/secure", cardID)
 	if err := c.client.Get(ctx, path, &info); err != nil {
@@ -259,27 +268,43 @@ func (c *CardsClient) GetSecure(ctx context.Context, cardID string) (*SecureCard
 
 // List lists cards with pagination and filters
 func (c *CardsClient) List(ctx context.Context, req *ListCardsRequest) (*ListCardsResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.list")
 	var resp ListCardsResponse
-	path := fmt.Sprintf("/v1/issuing/cards?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-
-	if req.CardNumber != nil {
-		path += fmt.Sprintf("&card_number=%s", *req.CardNumber)
-	}
-	if req.CardStatus != nil {
-		path += fmt.Sprintf("&card_status=%s", *req.CardStatus)
-	}
-	if req.CardholderID != nil {
-		path += fmt.Sprintf("&cardholder_id=%s", *req.CardholderID)
+	path, err := common.AppendQuery("/v1/issuing/cards", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cards: %w", err)
 	}
-
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list cards: %w", err)
 	}
 	return &resp, nil
 }
 
+// Iter returns an iterator over all cards matching req, starting at req.PageNumber
+func (c *CardsClient) Iter(req *ListCardsRequest) *common.Iterator[RetrieveCardResponse] {
+	var query ListCardsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[RetrieveCardResponse], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[RetrieveCardResponse]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all cards matching req, fetching every page
+func (c *CardsClient) ListAll(ctx context.Context, req *ListCardsRequest) ([]RetrieveCardResponse, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
+
 // UpdateStatus updates card status
 func (c *CardsClient) UpdateStatus(ctx context.Context, cardID string, req *UpdateCardStatusRequest) (*CardStatusResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.update_status")
 	var resp CardStatusResponse
 	path := fmt.Sprintf("/v1/issuing/cards/%s/status", cardID)
 	if err := c.client.Post(ctx, path, req, &resp); err != nil {
@@ -290,26 +315,33 @@ func (c *CardsClient) UpdateStatus(ctx context.Context, cardID string, req *Upda
 
 // Recharge recharges a card
 func (c *CardsClient) Recharge(ctx context.Context, cardID string, req *CardOrderRequest) (*CardOrder, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.recharge")
+	ctx, key := common.EnsureIdempotencyKey(ctx, req.IdempotencyKey)
 	var order CardOrder
 	path := fmt.Sprintf("/v1/issuing/cards/%s/recharge", cardID)
 	if err := c.client.Post(ctx, path, req, &order); err != nil {
 		return nil, fmt.Errorf("failed to recharge card: %w", err)
 	}
+	order.IdempotencyKey = key
 	return &order, nil
 }
 
 // Withdraw withdraws funds from a card
 func (c *CardsClient) Withdraw(ctx context.Context, cardID string, req *CardOrderRequest) (*CardOrder, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.withdraw")
+	ctx, key := common.EnsureIdempotencyKey(ctx, req.IdempotencyKey)
 	var order CardOrder
 	path := fmt.Sprintf("/v1/issuing/cards/%s/withdraw", cardID)
 	if err := c.client.Post(ctx, path, req, &order); err != nil {
 		return nil, fmt.Errorf("failed to withdraw from card: %w", err)
 	}
+	order.IdempotencyKey = key
 	return &order, nil
 }
 
 // GetOrder retrieves a card order by order ID
 func (c *CardsClient) GetOrder(ctx context.Context, orderID string) (*CardOrder, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.get_order")
 	var order CardOrder
 	path := fmt.Sprintf("/v1/issuing/cards/%s/order", orderID)
 	if err := c.client.Get(ctx, path, &order); err != nil {
@@ -320,6 +352,7 @@ func (c *CardsClient) GetOrder(ctx context.Context, orderID string) (*CardOrder,
 
 // Activate activates a physical card
 func (c *CardsClient) Activate(ctx context.Context, req *ActivateCardRequest) (*ActivateCardResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.activate")
 	var resp ActivateCardResponse
 	if err := c.client.Post(ctx, "/v1/issuing/cards/activate", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to activate card: %w", err)
@@ -329,6 +362,7 @@ func (c *CardsClient) Activate(ctx context.Context, req *ActivateCardRequest) (*
 
 // ResetPIN resets the PIN for a physical card
 func (c *CardsClient) ResetPIN(ctx context.Context, req *SetPINRequest) (*SetPINResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.reset_pin")
 	var resp SetPINResponse
 	if err := c.client.Post(ctx, "/v1/issuing/cards/pin", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to reset card PIN: %w", err)
@@ -338,6 +372,7 @@ func (c *CardsClient) ResetPIN(ctx context.Context, req *SetPINRequest) (*SetPIN
 
 // Assign assigns a physical card or bulk created virtual card to a cardholder
 func (c *CardsClient) Assign(ctx context.Context, req *AssignCardRequest) (*AssignCardResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.assign")
 	var resp AssignCardResponse
 	if err := c.client.Post(ctx, "/v1/issuing/cards/assign", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to assign card: %w", err)
@@ -347,6 +382,7 @@ func (c *CardsClient) Assign(ctx context.Context, req *AssignCardRequest) (*Assi
 
 // BulkCreate creates virtual cards in bulk
 func (c *CardsClient) BulkCreate(ctx context.Context, req *BulkCardCreationRequest) (*BulkCardCreationResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.cards.bulk_create")
 	var resp BulkCardCreationResponse
 	if err := c.client.Post(ctx, "/v1/issuing/cards/bulk", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to bulk create cards: %w", err)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // ProductsClient handles product operations
@@ -14,8 +15,8 @@ type ProductsClient struct {
 
 // NoPinPaymentLimit represents a no-pin payment limit
 type NoPinPaymentLimit struct {
-	Amount   string `json:"amount"` // API returns string
-	Currency string `json:"currency"`
+	Amount   money.Decimal `json:"amount"` // API returns string
+	Currency string        `json:"currency"`
 }
 
 // CardProduct represents a card product
@@ -35,8 +36,8 @@ type CardProduct struct {
 
 // ListProductsRequest represents a product list request
 type ListProductsRequest struct {
-	PageSize   int `json:"page_size"`
-	PageNumber int `json:"page_number"`
+	PageSize   int `json:"page_size" url:"page_size"`
+	PageNumber int `json:"page_number" url:"page_number"`
 }
 
 // ListProductsResponse represents a product list response
@@ -48,10 +49,36 @@ type ListProductsResponse struct {
 
 // List lists card products
 func (c *ProductsClient) List(ctx context.Context, req *ListProductsRequest) (*ListProductsResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.products.list")
 	var resp ListProductsResponse
-	path := fmt.Sprintf("/v1/issuing/products?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
+	path, err := common.AppendQuery("/v1/issuing/products", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
 	return &resp, nil
 }
+
+// Iter returns an iterator over all card products matching req, starting at req.PageNumber
+func (c *ProductsClient) Iter(req *ListProductsRequest) *common.Iterator[CardProduct] {
+	var query ListProductsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[CardProduct], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[CardProduct]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all card products matching req, fetching every page
+func (c *ProductsClient) ListAll(ctx context.Context, req *ListProductsRequest) ([]CardProduct, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
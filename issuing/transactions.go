@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 // TransactionsClient handles transaction operations
@@ -14,23 +15,23 @@ type TransactionsClient struct {
 
 // Transaction represents a transaction
 type Transaction struct {
-	TransactionID       string `json:"transaction_id"`
-	CardID              string `json:"card_id"`
-	TransactionType     string `json:"transaction_type"`
-	TransactionAmount   string `json:"transaction_amount"` // API returns string, not float
-	TransactionCurrency string `json:"transaction_currency"`
-	BillingAmount       string `json:"billing_amount"` // API returns string, not float
-	BillingCurrency     string `json:"billing_currency"`
-	MerchantName        string `json:"merchant_name"`
-	TransactionStatus   string `json:"transaction_status"`
-	TransactionTime     string `json:"transaction_time"`
+	TransactionID       string        `json:"transaction_id"`
+	CardID              string        `json:"card_id"`
+	TransactionType     string        `json:"transaction_type"`
+	TransactionAmount   money.Decimal `json:"transaction_amount"` // API returns string, not float
+	TransactionCurrency string        `json:"transaction_currency"`
+	BillingAmount       money.Decimal `json:"billing_amount"` // API returns string, not float
+	BillingCurrency     string        `json:"billing_currency"`
+	MerchantName        string        `json:"merchant_name"`
+	TransactionStatus   string        `json:"transaction_status"`
+	TransactionTime     string        `json:"transaction_time"`
 }
 
 // ListTransactionsRequest represents a transaction list request
 type ListTransactionsRequest struct {
-	PageSize   int    `json:"page_size"`
-	PageNumber int    `json:"page_number"`
-	CardID     string `json:"card_id,omitempty"`
+	PageSize   int    `json:"page_size" url:"page_size"`
+	PageNumber int    `json:"page_number" url:"page_number"`
+	CardID     string `json:"card_id,omitempty" url:"card_id"`
 }
 
 // ListTransactionsResponse represents a transaction list response
@@ -42,6 +43,7 @@ type ListTransactionsResponse struct {
 
 // Get retrieves a transaction by ID
 func (c *TransactionsClient) Get(ctx context.Context, transactionID string) (*Transaction, error) {
+	ctx = common.WithOperation(ctx, "issuing.transactions.get")
 	var transaction Transaction
 	path := fmt.Sprintf("/v1/issuing/transactions/%s", transactionID)
 	if err := c.client.Get(ctx, path, &transaction); err != nil {
@@ -52,13 +54,36 @@ func (c *TransactionsClient) Get(ctx context.Context, transactionID string) (*Tr
 
 // List lists transactions
 func (c *TransactionsClient) List(ctx context.Context, req *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	ctx = common.WithOperation(ctx, "issuing.transactions.list")
 	var resp ListTransactionsResponse
-	path := fmt.Sprintf("/v1/issuing/transactions?page_size=%d&page_number=%d", req.PageSize, req.PageNumber)
-	if req.CardID != "" {
-		path = fmt.Sprintf("%s&card_id=%s", path, req.CardID)
+	path, err := common.AppendQuery("/v1/issuing/transactions", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
 	}
 	if err := c.client.Get(ctx, path, &resp); err != nil {
 		return nil, fmt.Errorf("failed to list transactions: %w", err)
 	}
 	return &resp, nil
 }
+
+// Iter returns an iterator over all transactions matching req, starting at req.PageNumber
+func (c *TransactionsClient) Iter(req *ListTransactionsRequest) *common.Iterator[Transaction] {
+	var query ListTransactionsRequest
+	if req != nil {
+		query = *req
+	}
+	fetch := func(ctx context.Context, pageNumber, pageSize int) (*common.Page[Transaction], error) {
+		query.PageNumber, query.PageSize = pageNumber, pageSize
+		resp, err := c.List(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page[Transaction]{TotalPages: resp.TotalPages, TotalItems: resp.TotalItems, Data: resp.Data}, nil
+	}
+	return common.NewIterator(fetch, query.PageNumber, query.PageSize)
+}
+
+// ListAll returns all transactions matching req, fetching every page
+func (c *TransactionsClient) ListAll(ctx context.Context, req *ListTransactionsRequest) ([]Transaction, error) {
+	return c.Iter(req).Collect(ctx, 0)
+}
@@ -0,0 +1,128 @@
+package issuing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+)
+
+// Card order statuses
+const (
+	OrderStatusPending    = "PENDING"
+	OrderStatusProcessing = "PROCESSING"
+	OrderStatusSuccess    = "SUCCESS"
+	OrderStatusFailed     = "FAILED"
+	OrderStatusCancelled  = "CANCELLED"
+	OrderStatusRejected   = "REJECTED"
+)
+
+// Card statuses
+const (
+	CardStatusPending   = "PENDING"
+	CardStatusActive    = "ACTIVE"
+	CardStatusFrozen    = "FROZEN"
+	CardStatusCancelled = "CANCELLED"
+)
+
+// Errors wrapped by OrderError and CardStatusError
+var (
+	ErrOrderFailed           = errors.New("uqpay: card order failed")
+	ErrCardStatusUnreachable = errors.New("uqpay: card can no longer reach the requested status")
+)
+
+// IsTerminalOrderStatus reports whether a card order status is final
+func IsTerminalOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusSuccess, OrderStatusFailed, OrderStatusCancelled, OrderStatusRejected:
+		return true
+	}
+	return false
+}
+
+// OrderError is returned by WaitForOrder when the order fails or does not finish in time
+type OrderError struct {
+	OrderID string
+	Order   *CardOrder // last order seen, nil if none was retrieved
+	Err     error      // ErrOrderFailed, common.ErrWaitTimeout, a context error or the API error
+}
+
+// Error implements the error interface
+func (e *OrderError) Error() string {
+	if e.Order != nil {
+		return fmt.Sprintf("card order %s (%s): %v", e.OrderID, e.Order.OrderStatus, e.Err)
+	}
+	return fmt.Sprintf("card order %s: %v", e.OrderID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *OrderError) Unwrap() error {
+	return e.Err
+}
+
+// CardStatusError is returned by WaitForCardStatus when the card does not reach the status
+type CardStatusError struct {
+	CardID string
+	Status string                // requested status
+	Card   *RetrieveCardResponse // last card seen, nil if none was retrieved
+	Err    error                 // ErrCardStatusUnreachable, common.ErrWaitTimeout, a context error or the API error
+}
+
+// Error implements the error interface
+func (e *CardStatusError) Error() string {
+	if e.Card != nil {
+		return fmt.Sprintf("card %s is %s, waiting for %s: %v", e.CardID, e.Card.CardStatus, e.Status, e.Err)
+	}
+	return fmt.Sprintf("card %s waiting for %s: %v", e.CardID, e.Status, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *CardStatusError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForOrder polls a card order until it reaches a terminal status.
+// It returns the order on SUCCESS and an *OrderError otherwise. opts may be nil.
+func (c *CardsClient) WaitForOrder(ctx context.Context, orderID string, opts *common.WaitOptions) (*CardOrder, error) {
+	var last *CardOrder
+	err := common.Poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		order, err := c.GetOrder(ctx, orderID)
+		if err != nil {
+			return false, err
+		}
+		last = order
+		return IsTerminalOrderStatus(order.OrderStatus), nil
+	})
+	if err == nil && last.OrderStatus != OrderStatusSuccess {
+		err = ErrOrderFailed
+	}
+	if err != nil {
+		return last, &OrderError{OrderID: orderID, Order: last, Err: err}
+	}
+	return last, nil
+}
+
+// WaitForCardStatus polls a card until it has the given status, e.g. CardStatusActive.
+// It fails early with ErrCardStatusUnreachable once the card is cancelled. opts may be nil.
+func (c *CardsClient) WaitForCardStatus(ctx context.Context, cardID, status string, opts *common.WaitOptions) (*RetrieveCardResponse, error) {
+	var last *RetrieveCardResponse
+	err := common.Poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		card, err := c.Get(ctx, cardID)
+		if err != nil {
+			return false, err
+		}
+		last = card
+		if card.CardStatus == status {
+			return true, nil
+		}
+		if card.CardStatus == CardStatusCancelled {
+			return false, ErrCardStatusUnreachable
+		}
+		return false, nil
+	})
+	if err != nil {
+		return last, &CardStatusError{CardID: cardID, Status: status, Card: last, Err: err}
+	}
+	return last, nil
+}
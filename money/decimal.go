@@ -0,0 +1,345 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrDivisionByZero is returned by Div when the divisor is zero
+var ErrDivisionByZero = errors.New("money: division by zero")
+
+// Decimal is an exact, arbitrary-precision decimal number used for amounts, balances and rates.
+//
+// The zero value is 0. Decimals are immutable; every operation returns a new value.
+// Compare values with Cmp or Equal rather than ==, since 1.0 and 1.00 differ in scale.
+// Decimal is sent as a JSON string ("100.50") and accepts both strings and numbers when decoding.
+type Decimal struct {
+	value *big.Int // unscaled value, nil means zero
+	scale int32    // number of digits after the decimal point, >= 0
+}
+
+// Zero is the zero Decimal
+var Zero = Decimal{}
+
+var bigTen = big.NewInt(10)
+
+// maxExponent is the largest exponent magnitude Parse accepts
+const maxExponent = 1 << 10
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) unscaled() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+// NewFromUnscaled returns unscaled * 10^-scale, e.g. NewFromUnscaled(12345, 2) is 123.45
+func NewFromUnscaled(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{value: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return Decimal{value: big.NewInt(unscaled), scale: scale}
+}
+
+// NewFromInt returns the Decimal for an integer
+func NewFromInt(v int64) Decimal {
+	return Decimal{value: big.NewInt(v)}
+}
+
+// NewFromFloat returns the shortest Decimal that round-trips to f
+func NewFromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		// NaN and infinities have no decimal representation
+		return Zero
+	}
+	return d
+}
+
+// Parse parses a decimal string such as "100", "-0.05", "1.5e3" or "+12.30"
+func Parse(s string) (Decimal, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+		}
+		// Bound the exponent before it reaches pow10: "1e200000000" would otherwise build a huge number
+		if e > maxExponent || e < -maxExponent {
+			return Zero, fmt.Errorf("money: decimal %q out of range", orig)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || hasDot && strings.Contains(fracPart, ".") {
+		return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+	digits := intPart + fracPart
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+		}
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+	if neg {
+		value.Neg(value)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale < 0 {
+		value.Mul(value, pow10(int32(-scale)))
+		scale = 0
+	}
+	if scale > 1<<20 {
+		return Zero, fmt.Errorf("money: decimal %q out of range", orig)
+	}
+	return Decimal{value: value, scale: int32(scale)}, nil
+}
+
+// MustParse is like Parse but panics on invalid input. Intended for constants and tests.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// rescale returns the unscaled value of d at a scale >= d.scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := d.unscaled()
+	if scale == d.scale {
+		return v
+	}
+	return new(big.Int).Mul(v, pow10(scale-d.scale))
+}
+
+func maxScale(a, b Decimal) int32 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+// Add returns d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := maxScale(d, other)
+	return Decimal{value: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	scale := maxScale(d, other)
+	return Decimal{value: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// Mul returns d * other
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.unscaled(), other.unscaled()), scale: d.scale + other.scale}
+}
+
+// Div returns d / other rounded half away from zero to the given number of decimal places
+func (d Decimal) Div(other Decimal, places int32) (Decimal, error) {
+	if other.IsZero() {
+		return Zero, ErrDivisionByZero
+	}
+	if places < 0 {
+		places = 0
+	}
+	// Compute with one extra digit, then round
+	// d/other = (dv * 10^(os - ds)) / ov; scaled by 10^(places+1)
+	num := new(big.Int).Mul(d.unscaled(), pow10(places+1+other.scale))
+	den := new(big.Int).Mul(other.unscaled(), pow10(d.scale))
+	q := new(big.Int).Quo(num, den)
+	return Decimal{value: q, scale: places + 1}.Round(places), nil
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.unscaled()), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.unscaled()), scale: d.scale}
+}
+
+// Round rounds d half away from zero to the given number of decimal places
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return d
+	}
+
+	divisor := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.unscaled(), divisor, new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(divisor) >= 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{value: q, scale: places}
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	return d.unscaled().Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// IsNegative reports whether d < 0
+func (d Decimal) IsNegative() bool {
+	return d.Sign() < 0
+}
+
+// Cmp compares d and other and returns -1, 0 or +1
+func (d Decimal) Cmp(other Decimal) int {
+	scale := maxScale(d, other)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+// Equal reports whether d and other are numerically equal
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// LessThan reports whether d < other
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+// GreaterThan reports whether d > other
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// Float64 returns the nearest float64 to d, for display and approximate maths only
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d in plain notation, keeping its scale ("100.50")
+func (d Decimal) String() string {
+	v := d.unscaled()
+	digits := new(big.Int).Abs(v).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		cut := len(digits) - int(d.scale)
+		digits = digits[:cut] + "." + digits[cut:]
+	}
+	if v.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// StringFixed formats d rounded and padded to exactly the given number of decimal places
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+	r := d.Round(places)
+	if r.scale < places {
+		r = Decimal{value: r.rescale(places), scale: places}
+	}
+	return r.String()
+}
+
+// MarshalJSON encodes d as a JSON string
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON decodes a JSON string or number. null and "" decode to zero.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = Zero
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if strings.TrimSpace(s) == "" {
+			*d = Zero
+			return nil
+		}
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Number is a Decimal sent as a JSON number rather than a string, for API fields that expect
+// numeric amounts. Convert with Number(d) and Decimal().
+type Number Decimal
+
+// Decimal returns n as a Decimal
+func (n Number) Decimal() Decimal {
+	return Decimal(n)
+}
+
+// String formats n in plain notation
+func (n Number) String() string {
+	return Decimal(n).String()
+}
+
+// MarshalJSON encodes n as a JSON number
+func (n Number) MarshalJSON() ([]byte, error) {
+	return []byte(Decimal(n).String()), nil
+}
+
+// UnmarshalJSON decodes a JSON string or number
+func (n *Number) UnmarshalJSON(data []byte) error {
+	return (*Decimal)(n).UnmarshalJSON(data)
+}
@@ -0,0 +1,140 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// currencyDigits lists ISO 4217 currencies whose minor unit is not 2 decimal places
+var currencyDigits = map[string]int32{
+	// No minor unit
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// Three decimal places
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// Four decimal places
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimal places used by a currency (JPY 0, USD 2, KWD 3).
+// Unknown currencies default to 2.
+func MinorUnits(currency string) int32 {
+	if digits, ok := currencyDigits[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Money is an amount in a specific currency
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// New returns a Money value for the given amount and currency
+func New(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses an amount string in the given currency
+func ParseMoney(amount, currency string) (Money, error) {
+	d, err := Parse(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(d, currency), nil
+}
+
+// FromMinorUnits returns the Money value for an amount in minor units, e.g. 1050 USD cents is 10.50 USD
+func FromMinorUnits(units int64, currency string) Money {
+	return New(NewFromUnscaled(units, MinorUnits(currency)), currency)
+}
+
+// MinorUnits returns the amount in minor units after rounding to the currency precision
+func (m Money) MinorUnits() (int64, error) {
+	digits := MinorUnits(m.Currency)
+	v := m.Amount.Round(digits).rescale(digits)
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("money: %s overflows int64 minor units", m)
+	}
+	return v.Int64(), nil
+}
+
+// Round rounds the amount half away from zero to the currency precision
+func (m Money) Round() Money {
+	return Money{Amount: m.Amount.Round(MinorUnits(m.Currency)), Currency: m.Currency}
+}
+
+func (m Money) checkCurrency(other Money) error {
+	if !strings.EqualFold(m.Currency, other.Currency) {
+		return fmt.Errorf("money: currency mismatch: %s and %s", m.Currency, other.Currency)
+	}
+	return nil
+}
+
+// Add returns m + other; both must be in the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+// Sub returns m - other; both must be in the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+// Cmp compares m and other, which must be in the same currency
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+// IsZero reports whether the amount is 0
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// Format formats the amount with the currency precision and thousands separators, e.g. "1,234.50"
+func (m Money) Format() string {
+	s := m.Amount.StringFixed(MinorUnits(m.Currency))
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	out := b.String()
+	if hasFrac {
+		out += "." + frac
+	}
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+// String formats m as "<amount> <currency>" using the currency precision, e.g. "10.50 USD"
+func (m Money) String() string {
+	return m.Amount.StringFixed(MinorUnits(m.Currency)) + " " + m.Currency
+}
+
+// Sum adds amounts of the same currency
+func Sum(currency string, amounts ...Decimal) Money {
+	total := Zero
+	for _, a := range amounts {
+		total = total.Add(a)
+	}
+	return New(total, currency)
+}
@@ -0,0 +1,126 @@
+package uqpay
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/auth"
+	"github.com/jackillll/uqpay-sdk-go/common"
+)
+
+// DefaultTimeout is the HTTP timeout used when neither WithHTTPClient nor WithTimeout is given
+const DefaultTimeout = 15 * time.Second
+
+// Option configures a Client created by NewClient
+type Option func(*options)
+
+type options struct {
+	httpClient    *http.Client
+	timeout       time.Duration
+	userAgent     string
+	retryPolicy   *common.RetryPolicy
+	logger        common.Logger
+	baseURL       string
+	filesBaseURL  string
+	tokenProvider common.TokenProvider
+	tokenStore    auth.TokenStore
+	middleware    []common.Middleware
+	accountID     string
+}
+
+// WithHTTPClient sets the HTTP client used for API and token requests, e.g. for a custom
+// transport, proxy or TLS configuration
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of each HTTP request. Combined with WithHTTPClient, it is
+// applied to a copy of the given client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy sets the retry policy; use common.NoRetry() to disable retries
+func WithRetryPolicy(policy *common.RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithLogger logs every request attempt, see common.LoggingMiddleware
+func WithLogger(logger common.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithBaseURL overrides the environment's API base URL
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithFilesBaseURL overrides the environment's Files API base URL
+func WithFilesBaseURL(filesBaseURL string) Option {
+	return func(o *options) {
+		o.filesBaseURL = filesBaseURL
+	}
+}
+
+// WithTokenProvider sets the token provider used by every sub-client. The client ID and API
+// key passed to NewClient are then not required.
+func WithTokenProvider(provider common.TokenProvider) Option {
+	return func(o *options) {
+		o.tokenProvider = provider
+	}
+}
+
+// WithTokenStore shares tokens through store, e.g. an auth.FileTokenStore used by several processes
+func WithTokenStore(store auth.TokenStore) Option {
+	return func(o *options) {
+		o.tokenStore = store
+	}
+}
+
+// WithMiddleware appends middleware, applied in order to every request made by the client
+func WithMiddleware(middleware ...common.Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithAccountID makes every request act on behalf of a Connect sub-account
+func WithAccountID(accountID string) Option {
+	return func(o *options) {
+		o.accountID = accountID
+	}
+}
+
+// resolveHTTPClient returns the HTTP client to use after applying the timeout option
+func (o *options) resolveHTTPClient() *http.Client {
+	if o.httpClient == nil {
+		timeout := o.timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		return &http.Client{Timeout: timeout}
+	}
+	if o.timeout > 0 {
+		httpClient := *o.httpClient
+		httpClient.Timeout = o.timeout
+		return &httpClient
+	}
+	return o.httpClient
+}
@@ -0,0 +1,193 @@
+package uqpay
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+)
+
+// Pool caches one Client per (client ID, environment) for platforms serving several
+// UQPAY credentials. Clients are created lazily, share one http.Transport, and are evicted
+// after being idle for the pool's idle timeout.
+//
+//	pool := uqpay.NewPool(30*time.Minute, uqpay.WithTimeout(20*time.Second))
+//	client, err := pool.Get(merchant.ClientID, merchant.APIKey, configuration.Production())
+type Pool struct {
+	mu          sync.Mutex
+	opts        []Option
+	transport   *http.Transport
+	idleTimeout time.Duration
+	tenants     map[tenantKey]*tenant
+}
+
+// tenantKey identifies a tenant; the API key is not part of the key so that a rotated key
+// replaces the cached client
+type tenantKey struct {
+	clientID string
+	baseURL  string
+}
+
+type tenant struct {
+	client   *Client
+	apiKey   string
+	env      string
+	created  time.Time
+	lastUsed atomic.Int64 // UnixNano
+	requests atomic.Int64
+	errors   atomic.Int64
+}
+
+// TenantStats reports the usage of one pooled client
+type TenantStats struct {
+	ClientID    string
+	Environment string // environment name, or base URL for custom environments
+	Created     time.Time
+	LastUsed    time.Time
+	Requests    int64 // request attempts, including retries
+	Errors      int64 // attempts that failed with an API or transport error
+}
+
+// NewPool creates a client pool. Tenants unused for idleTimeout are evicted; 0 disables
+// eviction. Options apply to every client; all clients share one transport unless
+// WithHTTPClient is given.
+func NewPool(idleTimeout time.Duration, opts ...Option) *Pool {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 32
+	return &Pool{
+		opts:        opts,
+		transport:   transport,
+		idleTimeout: idleTimeout,
+		tenants:     make(map[tenantKey]*tenant),
+	}
+}
+
+// Get returns the cached client for the credentials, creating it if needed. If apiKey differs
+// from the cached client's key, a new client replaces it; calls already running on the old
+// client are unaffected.
+func (p *Pool) Get(clientID, apiKey string, env *configuration.Environment) (*Client, error) {
+	if env == nil {
+		return nil, ErrMissingEnvironment
+	}
+	key := tenantKey{clientID: clientID, baseURL: env.BaseURL}
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evictIdleLocked(now)
+
+	if t, ok := p.tenants[key]; ok && t.apiKey == apiKey {
+		t.lastUsed.Store(now.UnixNano())
+		return t.client, nil
+	}
+
+	t := &tenant{apiKey: apiKey, env: env.Name, created: now}
+	if t.env == "" {
+		t.env = env.BaseURL
+	}
+	t.lastUsed.Store(now.UnixNano())
+
+	opts := make([]Option, 0, len(p.opts)+2)
+	opts = append(opts, WithHTTPClient(&http.Client{Transport: p.transport, Timeout: DefaultTimeout}))
+	opts = append(opts, p.opts...)
+	opts = append(opts, WithMiddleware(t.track))
+
+	client, err := NewClient(clientID, apiKey, env, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t.client = client
+	p.tenants[key] = t
+	return client, nil
+}
+
+// Remove drops the cached client for a client ID and environment
+func (p *Pool) Remove(clientID string, env *configuration.Environment) {
+	if env == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tenants, tenantKey{clientID: clientID, baseURL: env.BaseURL})
+}
+
+// EvictIdle removes tenants unused for the idle timeout and returns how many were removed
+func (p *Pool) EvictIdle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.evictIdleLocked(time.Now())
+}
+
+func (p *Pool) evictIdleLocked(now time.Time) int {
+	if p.idleTimeout <= 0 {
+		return 0
+	}
+	evicted := 0
+	for key, t := range p.tenants {
+		if now.Sub(time.Unix(0, t.lastUsed.Load())) > p.idleTimeout {
+			delete(p.tenants, key)
+			evicted++
+		}
+	}
+	if evicted > 0 {
+		p.transport.CloseIdleConnections()
+	}
+	return evicted
+}
+
+// Len returns the number of cached clients
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tenants)
+}
+
+// Stats returns per-tenant usage, ordered by client ID
+func (p *Pool) Stats() []TenantStats {
+	p.mu.Lock()
+	stats := make([]TenantStats, 0, len(p.tenants))
+	for key, t := range p.tenants {
+		stats = append(stats, TenantStats{
+			ClientID:    key.clientID,
+			Environment: t.env,
+			Created:     t.created,
+			LastUsed:    time.Unix(0, t.lastUsed.Load()),
+			Requests:    t.requests.Load(),
+			Errors:      t.errors.Load(),
+		})
+	}
+	p.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ClientID != stats[j].ClientID {
+			return stats[i].ClientID < stats[j].ClientID
+		}
+		return stats[i].Environment < stats[j].Environment
+	})
+	return stats
+}
+
+// Close drops every cached client and closes idle connections
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tenants = make(map[tenantKey]*tenant)
+	p.transport.CloseIdleConnections()
+}
+
+// track is the middleware that records tenant usage
+func (t *tenant) track(next common.RoundTripFunc) common.RoundTripFunc {
+	return func(req *http.Request) (*common.Response, error) {
+		t.lastUsed.Store(time.Now().UnixNano())
+		t.requests.Add(1)
+		resp, err := next(req)
+		if err != nil {
+			t.errors.Add(1)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,361 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// Kinds of break found by Run
+const (
+	BreakGap              = "GAP"               // balance_before differs from the previous balance_after
+	BreakArithmetic       = "ARITHMETIC"        // balance_after - balance_before differs from the amount
+	BreakClosing          = "CLOSING"           // last balance_after differs from the current balance
+	BreakMissingReference = "MISSING_REFERENCE" // reference_id does not match a payout, transfer, conversion or deposit
+	BreakAmountMismatch   = "AMOUNT_MISMATCH"   // amount differs from the referenced resource
+)
+
+// Resources a transaction reference can be joined to
+const (
+	ResourcePayout     = "payout"
+	ResourceTransfer   = "transfer"
+	ResourceConversion = "conversion"
+	ResourceDeposit    = "deposit"
+)
+
+// referenceKinds lists, by transaction type, the resources tried in order for ReferenceID.
+// Other types, such as ADJUSTMENT or card transactions, are not joined.
+var referenceKinds = map[string][]string{
+	"PAYOUT":     {ResourcePayout},
+	"FEE":        {ResourcePayout, ResourceTransfer, ResourceConversion},
+	"REFUND":     {ResourcePayout, ResourceConversion},
+	"TRANSFER":   {ResourceTransfer},
+	"CONVERSION": {ResourceConversion},
+	"DEPOSIT":    {ResourceDeposit},
+	"PAYIN":      {ResourceDeposit},
+}
+
+// Options selects the ledger to reconcile
+type Options struct {
+	Currency string    // required
+	Start    time.Time // start of the period, zero for the first transaction
+	End      time.Time // end of the period, zero for now
+
+	SkipReferences bool // do not join references to payouts, transfers, conversions and deposits
+
+	// Balance selects the figure of BalancesClient.Get that the last balance_after is checked
+	// against, AvailableBalance when nil. The API documentation does not say whether
+	// balance_before and balance_after include frozen and prepaid funds; use TotalBalance if
+	// your ledger does.
+	Balance func(b *banking.Balance) money.Decimal
+}
+
+// AvailableBalance selects the available balance
+func AvailableBalance(b *banking.Balance) money.Decimal {
+	return b.AvailableBalance
+}
+
+// TotalBalance selects the available balance plus frozen and prepaid funds
+func TotalBalance(b *banking.Balance) money.Decimal {
+	return b.AvailableBalance.Add(b.FrozenBalance).Add(b.PrepaidBalance)
+}
+
+// Entry is a completed transaction in the period and the resource it was joined to
+type Entry struct {
+	Transaction banking.BalanceTransaction
+	Resource    string // ResourcePayout, ResourceTransfer, ResourceConversion or ResourceDeposit; empty when not joined
+	Status      string // status of the resource
+}
+
+// Break is a discrepancy that the ledger does not explain
+type Break struct {
+	Kind        string
+	Transaction *banking.BalanceTransaction // nil for BreakClosing
+	Expected    money.Decimal
+	Actual      money.Decimal
+	Message     string
+}
+
+// String describes the break
+func (b Break) String() string {
+	if b.Transaction == nil {
+		return fmt.Sprintf("%s: %s", b.Kind, b.Message)
+	}
+	return fmt.Sprintf("%s at %s (%s): %s", b.Kind, b.Transaction.TransactionID, b.Transaction.CreateTime, b.Message)
+}
+
+// Report is the result of reconciling one currency over a period
+type Report struct {
+	Currency       string
+	Start, End     time.Time
+	OpeningBalance money.Decimal // balance before the first transaction in the period
+	ClosingBalance money.Decimal // balance after the last transaction in the period
+	CurrentBalance money.Decimal // balance from BalancesClient.Get selected by Options.Balance
+	Credits        money.Decimal // total credited in the period
+	Debits         money.Decimal // total debited in the period, positive
+	Entries        []Entry       // completed transactions in the period, oldest first
+	Pending        int           // transactions in the period that are not completed and not reconciled
+	Breaks         []Break
+}
+
+// Balanced reports whether no breaks were found
+func (r *Report) Balanced() bool {
+	return len(r.Breaks) == 0
+}
+
+// Reconciler checks UQPAY balance transactions against balances and the resources they reference
+type Reconciler struct {
+	client *banking.Client
+}
+
+// New returns a Reconciler that reads through client
+func New(client *banking.Client) *Reconciler {
+	return &Reconciler{client: client}
+}
+
+// Run walks the balance transactions of opts.Currency from opts.Start to now, verifies every
+// balance_before follows the previous balance_after, checks the last balance_after against
+// the current balance and joins references in the period to the resources they belong to.
+// Transactions after opts.End are only used to prove the chain up to the current balance.
+func (r *Reconciler) Run(ctx context.Context, opts Options) (*Report, error) {
+	currency := strings.ToUpper(opts.Currency)
+	if currency == "" {
+		return nil, errors.New("uqpay: reconcile currency is required")
+	}
+	report := &Report{Currency: currency, Start: opts.Start, End: opts.End}
+
+	req := &banking.ListBalanceTransactionsRequest{PageSize: 100, Currency: currency, TransactionStatus: "ALL"}
+	if !opts.Start.IsZero() {
+		req.StartTime = opts.Start.UTC().Format(time.RFC3339)
+	}
+	txns, err := r.client.Balances.ListAllTransactions(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile %s: %w", currency, err)
+	}
+	balance, err := r.client.Balances.Get(ctx, currency)
+	switch {
+	case err == nil:
+		selectBalance := opts.Balance
+		if selectBalance == nil {
+			selectBalance = AvailableBalance
+		}
+		report.CurrentBalance = selectBalance(balance)
+	case !errors.Is(err, common.ErrNotFound):
+		return nil, fmt.Errorf("failed to reconcile %s: %w", currency, err)
+	}
+
+	completed := chronological(txns, report, opts.End)
+	r.checkChain(report, completed, opts.End)
+	if !opts.SkipReferences {
+		j := &joiner{client: r.client, cache: make(map[string]*resource)}
+		for i := range report.Entries {
+			if err := j.join(ctx, report, &report.Entries[i]); err != nil {
+				return nil, fmt.Errorf("failed to reconcile %s: %w", currency, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+// chronological returns the completed transactions oldest first and counts the pending ones
+// in the period. Ties keep the order of the API, which may list newest first.
+func chronological(txns []banking.BalanceTransaction, report *Report, end time.Time) []banking.BalanceTransaction {
+	if len(txns) > 1 && createTime(txns[0]).After(createTime(txns[len(txns)-1])) {
+		reversed := make([]banking.BalanceTransaction, len(txns))
+		for i, t := range txns {
+			reversed[len(txns)-1-i] = t
+		}
+		txns = reversed
+	}
+	var completed []banking.BalanceTransaction
+	for _, t := range txns {
+		if strings.EqualFold(t.TransactionStatus, "COMPLETED") {
+			completed = append(completed, t)
+		} else if inPeriod(t, end) {
+			report.Pending++
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool { return createTime(completed[i]).Before(createTime(completed[j])) })
+	return completed
+}
+
+// checkChain verifies the before/after chain, fills in the period totals and checks the
+// closing figure against the current balance
+func (r *Reconciler) checkChain(report *Report, txns []banking.BalanceTransaction, end time.Time) {
+	report.OpeningBalance, report.ClosingBalance = report.CurrentBalance, report.CurrentBalance
+	var periodStarted bool
+	for i := range txns {
+		t := &txns[i]
+		if i > 0 && !txns[i-1].BalanceAfter.Equal(t.BalanceBefore) {
+			report.Breaks = append(report.Breaks, Break{
+				Kind: BreakGap, Transaction: t, Expected: txns[i-1].BalanceAfter, Actual: t.BalanceBefore,
+				Message: fmt.Sprintf("balance before %s does not follow %s after %s", t.BalanceBefore, txns[i-1].BalanceAfter, txns[i-1].TransactionID),
+			})
+		}
+		delta := t.BalanceAfter.Sub(t.BalanceBefore)
+		if !delta.Abs().Equal(t.Amount.Abs()) {
+			report.Breaks = append(report.Breaks, Break{
+				Kind: BreakArithmetic, Transaction: t, Expected: t.Amount.Abs(), Actual: delta.Abs(),
+				Message: fmt.Sprintf("balance moved by %s for an amount of %s", delta, t.Amount),
+			})
+		}
+
+		if !inPeriod(*t, end) {
+			continue
+		}
+		if !periodStarted {
+			report.OpeningBalance, periodStarted = t.BalanceBefore, true
+		}
+		report.ClosingBalance = t.BalanceAfter
+		if delta.Sign() >= 0 {
+			report.Credits = report.Credits.Add(delta)
+		} else {
+			report.Debits = report.Debits.Sub(delta)
+		}
+		report.Entries = append(report.Entries, Entry{Transaction: *t})
+	}
+
+	switch {
+	case len(txns) == 0:
+		return
+	case !periodStarted:
+		// Nothing in the period: the balance throughout was the one before the first later transaction
+		report.OpeningBalance, report.ClosingBalance = txns[0].BalanceBefore, txns[0].BalanceBefore
+	}
+	if last := txns[len(txns)-1]; !last.BalanceAfter.Equal(report.CurrentBalance) {
+		report.Breaks = append(report.Breaks, Break{
+			Kind: BreakClosing, Expected: report.CurrentBalance, Actual: last.BalanceAfter,
+			Message: fmt.Sprintf("last balance after %s is %s but the current balance is %s", last.TransactionID, last.BalanceAfter, report.CurrentBalance),
+		})
+	}
+}
+
+// resource is a payout, transfer, conversion or deposit reduced to what is reconciled
+type resource struct {
+	kind     string
+	status   string
+	currency string
+	amounts  map[string][]money.Decimal // acceptable absolute amounts by transaction type
+}
+
+// joiner resolves references, caching lookups shared by several transactions
+type joiner struct {
+	client *banking.Client
+	cache  map[string]*resource // kind + "/" + ID, nil when not found
+}
+
+func (j *joiner) join(ctx context.Context, report *Report, entry *Entry) error {
+	t := &entry.Transaction
+	kinds, ok := referenceKinds[strings.ToUpper(t.TransactionType)]
+	if !ok {
+		return nil
+	}
+	if t.ReferenceID == "" {
+		report.Breaks = append(report.Breaks, Break{Kind: BreakMissingReference, Transaction: t, Actual: t.Amount, Message: t.TransactionType + " has no reference"})
+		return nil
+	}
+
+	for _, kind := range kinds {
+		res, err := j.lookup(ctx, kind, t.ReferenceID)
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			continue
+		}
+		entry.Resource, entry.Status = res.kind, res.status
+		expected := res.amounts[strings.ToUpper(t.TransactionType)]
+		if t.TransactionType == "CONVERSION" && strings.EqualFold(t.Currency, res.currency) {
+			// The sold currency is debited; the bought currency is credited
+			expected = res.amounts["CONVERSION_FROM"]
+		}
+		if len(expected) > 0 && !containsAmount(expected, t.Amount.Abs()) {
+			report.Breaks = append(report.Breaks, Break{
+				Kind: BreakAmountMismatch, Transaction: t, Expected: expected[0], Actual: t.Amount.Abs(),
+				Message: fmt.Sprintf("amount %s does not match %s %s of %s", t.Amount.Abs(), res.kind, t.ReferenceID, expected[0]),
+			})
+		}
+		return nil
+	}
+	report.Breaks = append(report.Breaks, Break{
+		Kind: BreakMissingReference, Transaction: t, Actual: t.Amount,
+		Message: fmt.Sprintf("%s reference %s not found as %s", t.TransactionType, t.ReferenceID, strings.Join(kinds, ", ")),
+	})
+	return nil
+}
+
+func (j *joiner) lookup(ctx context.Context, kind, id string) (*resource, error) {
+	key := kind + "/" + id
+	if res, ok := j.cache[key]; ok {
+		return res, nil
+	}
+	var res *resource
+	var err error
+	switch kind {
+	case ResourcePayout:
+		var p *banking.PayoutDetailResponse
+		if p, err = j.client.Payouts.Get(ctx, id); err == nil {
+			res = &resource{kind: kind, status: p.PayoutStatus, currency: p.Currency, amounts: map[string][]money.Decimal{
+				"PAYOUT": {p.Amount},
+				"FEE":    {p.Fee},
+				"REFUND": {p.Amount.Add(p.Fee), p.Amount, p.Fee},
+			}}
+		}
+	case ResourceTransfer:
+		var t *banking.Transfer
+		if t, err = j.client.Transfers.Get(ctx, id); err == nil {
+			res = &resource{kind: kind, status: t.TransferStatus, currency: t.Currency, amounts: map[string][]money.Decimal{
+				"TRANSFER": {t.Amount},
+			}}
+		}
+	case ResourceConversion:
+		var c *banking.Conversion
+		if c, err = j.client.Conversions.Get(ctx, id); err == nil {
+			res = &resource{kind: kind, status: c.ConversionStatus, currency: c.CurrencyFrom, amounts: map[string][]money.Decimal{
+				"CONVERSION":      {c.AmountTo},
+				"CONVERSION_FROM": {c.AmountFrom},
+				"REFUND":          {c.AmountFrom},
+			}}
+		}
+	case ResourceDeposit:
+		var d *banking.Deposit
+		if d, err = j.client.Deposits.Get(ctx, id); err == nil {
+			res = &resource{kind: kind, status: d.DepositStatus, currency: d.Currency, amounts: map[string][]money.Decimal{
+				"DEPOSIT": {d.Amount},
+				"PAYIN":   {d.Amount},
+			}}
+		}
+	}
+	if err != nil && !errors.Is(err, common.ErrNotFound) {
+		return nil, err
+	}
+	j.cache[key] = res
+	return res, nil
+}
+
+func containsAmount(amounts []money.Decimal, amount money.Decimal) bool {
+	for _, a := range amounts {
+		if a.Equal(amount) {
+			return true
+		}
+	}
+	return false
+}
+
+// createTime parses a transaction timestamp, zero when it cannot be parsed
+func createTime(t banking.BalanceTransaction) time.Time {
+	ts, _ := time.Parse(time.RFC3339, t.CreateTime)
+	return ts
+}
+
+// inPeriod reports whether t was created no later than end; zero end means now
+func inPeriod(t banking.BalanceTransaction, end time.Time) bool {
+	return end.IsZero() || !createTime(t).After(end)
+}
@@ -0,0 +1,162 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+type camtDocument struct {
+	XMLName xml.Name      `xml:"Document"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Stmt    camtStatement `xml:"BkToCstmrStmt"`
+}
+
+type camtStatement struct {
+	GrpHdr struct {
+		MsgID   string `xml:"MsgId"`
+		CreDtTm string `xml:"CreDtTm"`
+	} `xml:"GrpHdr"`
+	Stmt struct {
+		ID      string `xml:"Id"`
+		CreDtTm string `xml:"CreDtTm"`
+		FrToDt  struct {
+			FrDtTm string `xml:"FrDtTm"`
+			ToDtTm string `xml:"ToDtTm"`
+		} `xml:"FrToDt"`
+		Acct struct {
+			ID  string `xml:"Id>Othr>Id"`
+			Ccy string `xml:"Ccy"`
+		} `xml:"Acct"`
+		Bal       []camtBalance `xml:"Bal"`
+		TxsSummry struct {
+			TtlNtries    camtTotal `xml:"TtlNtries"`
+			TtlCdtNtries camtTotal `xml:"TtlCdtNtries"`
+			TtlDbtNtries camtTotal `xml:"TtlDbtNtries"`
+		} `xml:"TxsSummry"`
+		Ntry []camtEntry `xml:"Ntry"`
+	} `xml:"Stmt"`
+}
+
+type camtAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amt       camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Date      string     `xml:"Dt>Dt"`
+}
+
+type camtTotal struct {
+	NbOfNtries string `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum,omitempty"`
+}
+
+type camtEntry struct {
+	NtryRef     string     `xml:"NtryRef"`
+	Amt         camtAmount `xml:"Amt"`
+	CdtDbtInd   string     `xml:"CdtDbtInd"`
+	Sts         string     `xml:"Sts"`
+	BookgDtTm   string     `xml:"BookgDt>DtTm"`
+	ValDt       string     `xml:"ValDt>Dt"`
+	AcctSvcrRef string     `xml:"AcctSvcrRef,omitempty"`
+	BkTxCd      struct {
+		Domain    string `xml:"Domn>Cd"`
+		Family    string `xml:"Domn>Fmly>Cd"`
+		SubFamily string `xml:"Domn>Fmly>SubFmlyCd"`
+		Prtry     string `xml:"Prtry>Cd"`
+		Issr      string `xml:"Prtry>Issr"`
+	} `xml:"BkTxCd"`
+	TxDtls struct {
+		AcctSvcrRef string `xml:"Refs>AcctSvcrRef,omitempty"`
+		EndToEndID  string `xml:"Refs>EndToEndId"`
+		AddtlTxInf  string `xml:"AddtlTxInf,omitempty"`
+	} `xml:"NtryDtls>TxDtls"`
+	AddtlNtryInf string `xml:"AddtlNtryInf,omitempty"`
+}
+
+// WriteCAMT053 writes the statement as an ISO 20022 camt.053.001.02 bank-to-customer statement.
+// The UQPAY transaction type is carried as the proprietary bank transaction code.
+func (s *Statement) WriteCAMT053(w io.Writer) error {
+	var doc camtDocument
+	doc.Xmlns = camt053Namespace
+	id := s.AccountID + "-" + s.End.Format("20060102")
+	created := s.CreatedAt.Format(time.RFC3339)
+	doc.Stmt.GrpHdr.MsgID, doc.Stmt.GrpHdr.CreDtTm = id, created
+
+	stmt := &doc.Stmt.Stmt
+	stmt.ID, stmt.CreDtTm = id, created
+	stmt.FrToDt.FrDtTm, stmt.FrToDt.ToDtTm = s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339)
+	stmt.Acct.ID, stmt.Acct.Ccy = s.AccountID, s.Currency
+	stmt.Bal = []camtBalance{
+		s.camtBalance("OPBD", s.OpeningBalance, s.Start),
+		s.camtBalance("CLBD", s.ClosingBalance, s.End),
+	}
+
+	var credits, debits int
+	for _, l := range s.Lines {
+		codes := codesFor(l)
+		e := camtEntry{
+			NtryRef:      l.TransactionID,
+			Amt:          camtAmount{Ccy: s.Currency, Value: s.amount(l.Amount.Abs())},
+			CdtDbtInd:    camtIndicator(l.Amount),
+			Sts:          "BOOK",
+			BookgDtTm:    l.BookingTime.Format(time.RFC3339),
+			ValDt:        l.BookingTime.Format("2006-01-02"),
+			AcctSvcrRef:  l.TransactionID,
+			AddtlNtryInf: l.Description,
+		}
+		if l.Credit() {
+			credits++
+		} else {
+			debits++
+		}
+		e.BkTxCd.Domain, e.BkTxCd.Family, e.BkTxCd.SubFamily = codes.domain, codes.family, codes.sub
+		e.BkTxCd.Prtry, e.BkTxCd.Issr = l.UQPAYType, "UQPAY"
+		e.TxDtls.AcctSvcrRef = l.Reference
+		e.TxDtls.EndToEndID = l.Reference
+		if e.TxDtls.EndToEndID == "" {
+			e.TxDtls.EndToEndID = "NOTPROVIDED"
+		}
+		e.TxDtls.AddtlTxInf = l.Type
+		stmt.Ntry = append(stmt.Ntry, e)
+	}
+	stmt.TxsSummry.TtlNtries = camtTotal{NbOfNtries: strconv.Itoa(len(s.Lines))}
+	stmt.TxsSummry.TtlCdtNtries = camtTotal{NbOfNtries: strconv.Itoa(credits), Sum: s.amount(s.Credits)}
+	stmt.TxsSummry.TtlDbtNtries = camtTotal{NbOfNtries: strconv.Itoa(debits), Sum: s.amount(s.Debits)}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (s *Statement) camtBalance(code string, d money.Decimal, date time.Time) camtBalance {
+	return camtBalance{
+		Code:      code,
+		Amt:       camtAmount{Ccy: s.Currency, Value: s.amount(d.Abs())},
+		CdtDbtInd: camtIndicator(d),
+		Date:      date.Format("2006-01-02"),
+	}
+}
+
+func camtIndicator(d money.Decimal) string {
+	if d.Sign() < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
@@ -0,0 +1,100 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// mt940LineLength is the maximum length of a line in an MT940 field
+const mt940LineLength = 65
+
+// WriteMT940 writes the statement as a SWIFT MT940 message with CRLF line endings. References
+// longer than the 16 characters :61: allows are truncated there and given in full in :86:.
+func (s *Statement) WriteMT940(w io.Writer) error {
+	var b strings.Builder
+	field := func(tag, value string) {
+		b.WriteString(":" + tag + ":" + value + "\r\n")
+	}
+	field("20", swiftRef(s.CreatedAt.Format("060102150405")+s.Currency, "STATEMENT"))
+	field("25", swiftText(s.AccountID, 35))
+	field("28C", "1/1")
+	field("60F", s.mt940Balance(s.OpeningBalance, s.Start))
+	for _, l := range s.Lines {
+		mark := "C"
+		if !l.Credit() {
+			mark = "D"
+		}
+		field("61", fmt.Sprintf("%s%s%s%s%s%s//%s",
+			l.BookingTime.Format("060102"), l.BookingTime.Format("0102"), mark, s.mt940Amount(l.Amount),
+			codesFor(l).mt940, swiftRef(l.Reference, "NONREF"), swiftRef(l.TransactionID, "NONREF")))
+		field("86", mt940Narrative(l))
+	}
+	field("62F", s.mt940Balance(s.ClosingBalance, s.End))
+	b.WriteString("-\r\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mt940Balance formats a :60F: or :62F: balance as mark, date, currency and amount
+func (s *Statement) mt940Balance(d money.Decimal, date time.Time) string {
+	mark := "C"
+	if d.Sign() < 0 {
+		mark = "D"
+	}
+	return mark + date.Format("060102") + s.Currency + s.mt940Amount(d)
+}
+
+// mt940Amount formats the absolute value of d with a decimal comma
+func (s *Statement) mt940Amount(d money.Decimal) string {
+	return strings.Replace(s.amount(d.Abs()), ".", ",", 1)
+}
+
+// mt940Narrative builds the :86: information, wrapped to at most 6 lines
+func mt940Narrative(l Line) string {
+	text := swiftText(fmt.Sprintf("/TYPE/%s/REF/%s/TXN/%s %s", l.Type, l.Reference, l.TransactionID, l.Description), 6*mt940LineLength)
+	var lines []string
+	for len(lines) < 5 && len(text) > mt940LineLength {
+		// A continuation line starting with ':' or '-' would read as a new field or the end of the message
+		cut := mt940LineLength
+		for cut > 1 && strings.ContainsRune(":-", rune(text[cut])) {
+			cut--
+		}
+		lines = append(lines, text[:cut])
+		text = text[cut:]
+	}
+	if len(text) > mt940LineLength {
+		text = text[:mt940LineLength]
+	}
+	return strings.Join(append(lines, text), "\r\n")
+}
+
+// swiftRef returns ref truncated to the 16 characters of an MT940 reference, or fallback when empty
+func swiftRef(ref, fallback string) string {
+	ref = swiftText(strings.ReplaceAll(ref, "/", ""), 16)
+	if ref == "" {
+		return fallback
+	}
+	return ref
+}
+
+// swiftText keeps the characters of the SWIFT X character set, replacing others with a space,
+// and truncates to max characters
+func swiftText(s string, max int) string {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("/-?:().,'+ ", r):
+			out = append(out, byte(r))
+		default:
+			out = append(out, ' ')
+		}
+		if len(out) == max {
+			break
+		}
+	}
+	return strings.TrimSpace(string(out))
+}
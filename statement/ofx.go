@@ -0,0 +1,103 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const (
+	ofxHeader     = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+	ofxTimeLayout = "20060102150405"
+	ofxNameLength = 32
+)
+
+type ofxDocument struct {
+	XMLName xml.Name `xml:"OFX"`
+	Signon  struct {
+		Status   ofxStatus `xml:"STATUS"`
+		DtServer string    `xml:"DTSERVER"`
+		Language string    `xml:"LANGUAGE"`
+	} `xml:"SIGNONMSGSRSV1>SONRS"`
+	Statement struct {
+		TrnUID string       `xml:"TRNUID"`
+		Status ofxStatus    `xml:"STATUS"`
+		Stmt   ofxStatement `xml:"STMTRS"`
+	} `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     string `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxStatement struct {
+	CurDef       string `xml:"CURDEF"`
+	BankAcctFrom struct {
+		BankID   string `xml:"BANKID"`
+		AcctID   string `xml:"ACCTID"`
+		AcctType string `xml:"ACCTTYPE"`
+	} `xml:"BANKACCTFROM"`
+	TranList struct {
+		DtStart string           `xml:"DTSTART"`
+		DtEnd   string           `xml:"DTEND"`
+		Trn     []ofxTransaction `xml:"STMTTRN"`
+	} `xml:"BANKTRANLIST"`
+	LedgerBal struct {
+		BalAmt string `xml:"BALAMT"`
+		DtAsOf string `xml:"DTASOF"`
+	} `xml:"LEDGERBAL"`
+}
+
+type ofxTransaction struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DtPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FitID    string `xml:"FITID"`
+	RefNum   string `xml:"REFNUM,omitempty"`
+	Name     string `xml:"NAME"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+// WriteOFX writes the statement as an OFX 2.2 bank statement response. OFX carries only the
+// closing balance; the opening balance is implied by the transactions.
+func (s *Statement) WriteOFX(w io.Writer) error {
+	var doc ofxDocument
+	ok := ofxStatus{Code: "0", Severity: "INFO"}
+	doc.Signon.Status, doc.Signon.DtServer, doc.Signon.Language = ok, s.CreatedAt.Format(ofxTimeLayout), "ENG"
+	doc.Statement.TrnUID, doc.Statement.Status = "0", ok
+
+	stmt := &doc.Statement.Stmt
+	stmt.CurDef = s.Currency
+	stmt.BankAcctFrom.BankID, stmt.BankAcctFrom.AcctID, stmt.BankAcctFrom.AcctType = "UQPAY", s.AccountID, "CHECKING"
+	stmt.TranList.DtStart, stmt.TranList.DtEnd = s.Start.Format(ofxTimeLayout), s.End.Format(ofxTimeLayout)
+	for _, l := range s.Lines {
+		name := l.Type
+		if l.Reference != "" {
+			name += " " + l.Reference
+		}
+		if len(name) > ofxNameLength {
+			name = name[:ofxNameLength]
+		}
+		stmt.TranList.Trn = append(stmt.TranList.Trn, ofxTransaction{
+			TrnType:  codesFor(l).ofx,
+			DtPosted: l.BookingTime.Format(ofxTimeLayout),
+			TrnAmt:   s.amount(l.Amount),
+			FitID:    l.TransactionID,
+			RefNum:   l.Reference,
+			Name:     name,
+			Memo:     l.Description,
+		})
+	}
+	stmt.LedgerBal.BalAmt, stmt.LedgerBal.DtAsOf = s.amount(s.ClosingBalance), s.End.Format(ofxTimeLayout)
+
+	if _, err := io.WriteString(w, xml.Header+ofxHeader); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
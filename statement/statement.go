@@ -0,0 +1,283 @@
+package statement
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/reconcile"
+)
+
+// Export formats accepted by Statement.Write
+const (
+	FormatCSV     = "CSV"
+	FormatMT940   = "MT940"
+	FormatCAMT053 = "CAMT053"
+	FormatOFX     = "OFX"
+)
+
+// Statement line types that UQPAY transaction types are mapped to
+const (
+	TypePayin      = "PAYIN"
+	TypePayout     = "PAYOUT"
+	TypeTransfer   = "TRANSFER"
+	TypeConversion = "CONVERSION"
+	TypeFee        = "FEE"
+	TypeRefund     = "REFUND"
+	TypeCard       = "CARD"
+	TypeAdjustment = "ADJUSTMENT"
+	TypeOther      = "OTHER"
+)
+
+// ErrUnknownFormat is returned by Statement.Write for a format it cannot produce
+var ErrUnknownFormat = errors.New("uqpay: unknown statement format")
+
+// lineTypes maps UQPAY balance transaction types to statement line types
+var lineTypes = map[string]string{
+	"PAYIN":         TypePayin,
+	"DEPOSIT":       TypePayin,
+	"PAYOUT":        TypePayout,
+	"TRANSFER":      TypeTransfer,
+	"CONVERSION":    TypeConversion,
+	"FEE":           TypeFee,
+	"REFUND":        TypeRefund,
+	"CARD_RECHARGE": TypeCard,
+	"CARD_WITHDRAW": TypeCard,
+	"ADJUSTMENT":    TypeAdjustment,
+}
+
+// Options selects the statement to generate
+type Options struct {
+	Currency  string    // required by Generate; all currencies with a balance for GenerateAll
+	Start     time.Time // start of the period, zero for the first transaction or End when there is none
+	End       time.Time // end of the period, zero for now
+	AccountID string    // account identifier written to the statement, "UQPAY-<CURRENCY>" when empty
+
+	Now func() time.Time // clock used for creation times and a zero End, time.Now when nil
+}
+
+// Line is a booked transaction on a statement
+type Line struct {
+	TransactionID string
+	Reference     string // UQPAY ID of the payout, transfer, conversion or deposit
+	Type          string // TypePayin, TypePayout, TypeConversion, TypeFee, ...
+	UQPAYType     string // transaction type as returned by the API
+	Description   string
+	BookingTime   time.Time
+	Amount        money.Decimal // signed, negative for debits
+	Balance       money.Decimal // balance after the transaction
+}
+
+// Credit reports whether the line increases the balance
+func (l Line) Credit() bool {
+	return l.Amount.Sign() >= 0
+}
+
+// Statement is the booked activity of one currency balance over a period
+type Statement struct {
+	AccountID      string
+	Currency       string
+	Start, End     time.Time
+	CreatedAt      time.Time
+	OpeningBalance money.Decimal
+	ClosingBalance money.Decimal
+	Credits        money.Decimal     // total credited, positive
+	Debits         money.Decimal     // total debited, positive
+	Lines          []Line            // oldest first
+	Breaks         []reconcile.Break // balance chain breaks; figures may not add up when not empty
+}
+
+// Generator builds statements from UQPAY balance transactions
+type Generator struct {
+	client     *banking.Client
+	reconciler *reconcile.Reconciler
+}
+
+// New returns a Generator that reads through client
+func New(client *banking.Client) *Generator {
+	return &Generator{client: client, reconciler: reconcile.New(client)}
+}
+
+// Generate builds the statement of opts.Currency for the period. Only completed transactions
+// are included. Opening and closing balances are taken from the balance chain, so a period
+// without transactions still carries the balance it held.
+func (g *Generator) Generate(ctx context.Context, opts Options) (*Statement, error) {
+	if opts.Currency == "" {
+		return nil, errors.New("uqpay: statement currency is required")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	now := opts.Now().UTC()
+	if opts.End.IsZero() {
+		opts.End = now
+	}
+	report, err := g.reconciler.Run(ctx, reconcile.Options{
+		Currency:       opts.Currency,
+		Start:          opts.Start,
+		End:            opts.End,
+		SkipReferences: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate statement: %w", err)
+	}
+
+	s := &Statement{
+		AccountID:      opts.AccountID,
+		Currency:       report.Currency,
+		Start:          opts.Start.UTC(),
+		End:            opts.End.UTC(),
+		CreatedAt:      now,
+		OpeningBalance: report.OpeningBalance,
+		ClosingBalance: report.ClosingBalance,
+		Credits:        report.Credits,
+		Debits:         report.Debits,
+		Breaks:         report.Breaks,
+	}
+	if s.AccountID == "" {
+		s.AccountID = "UQPAY-" + s.Currency
+	}
+	for _, e := range report.Entries {
+		t := e.Transaction
+		booked, _ := time.Parse(time.RFC3339, t.CreateTime)
+		lineType, ok := lineTypes[strings.ToUpper(t.TransactionType)]
+		if !ok {
+			lineType = TypeOther
+		}
+		s.Lines = append(s.Lines, Line{
+			TransactionID: t.TransactionID,
+			Reference:     t.ReferenceID,
+			Type:          lineType,
+			UQPAYType:     t.TransactionType,
+			Description:   t.Description,
+			BookingTime:   booked.UTC(),
+			Amount:        t.BalanceAfter.Sub(t.BalanceBefore),
+			Balance:       t.BalanceAfter,
+		})
+	}
+	if s.Start.IsZero() {
+		// Without transactions in the period the opening balance is the one held at End
+		s.Start = s.End
+		if len(s.Lines) > 0 {
+			s.Start = s.Lines[0].BookingTime
+		}
+	}
+	return s, nil
+}
+
+// GenerateAll builds one statement per currency held, using opts for everything but Currency
+func (g *Generator) GenerateAll(ctx context.Context, opts Options) ([]*Statement, error) {
+	balances, err := g.client.Balances.ListAll(ctx, &banking.ListBalancesRequest{PageSize: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate statements: %w", err)
+	}
+	statements := make([]*Statement, 0, len(balances))
+	for _, b := range balances {
+		currencyOpts := opts
+		currencyOpts.Currency = b.Currency
+		if opts.AccountID != "" {
+			currencyOpts.AccountID = opts.AccountID + "-" + b.Currency
+		}
+		s, err := g.Generate(ctx, currencyOpts)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+	return statements, nil
+}
+
+// Write writes the statement in format, one of FormatCSV, FormatMT940, FormatCAMT053 or FormatOFX
+func (s *Statement) Write(w io.Writer, format string) error {
+	switch strings.ToUpper(strings.ReplaceAll(format, ".", "")) {
+	case FormatCSV:
+		return s.WriteCSV(w)
+	case FormatMT940:
+		return s.WriteMT940(w)
+	case FormatCAMT053:
+		return s.WriteCAMT053(w)
+	case FormatOFX:
+		return s.WriteOFX(w)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+var csvColumns = []string{"date", "type", "uqpay_type", "transaction_id", "reference", "description", "currency", "debit", "credit", "balance"}
+
+// WriteCSV writes the statement as CSV with a header row, an OPENING row, one row per line
+// and a CLOSING row. Debits and credits are positive and in separate columns.
+func (s *Statement) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	opening := []string{s.Start.Format("2006-01-02"), "OPENING", "", "", "", "Opening balance", s.Currency, "", "", s.amount(s.OpeningBalance)}
+	if err := cw.Write(opening); err != nil {
+		return err
+	}
+	for _, l := range s.Lines {
+		var debit, credit string
+		if l.Credit() {
+			credit = s.amount(l.Amount)
+		} else {
+			debit = s.amount(l.Amount.Abs())
+		}
+		record := []string{
+			l.BookingTime.Format(time.RFC3339), l.Type, l.UQPAYType, l.TransactionID, l.Reference, l.Description,
+			s.Currency, debit, credit, s.amount(l.Balance),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	closing := []string{s.End.Format("2006-01-02"), "CLOSING", "", "", "", "Closing balance", s.Currency, s.amount(s.Debits), s.amount(s.Credits), s.amount(s.ClosingBalance)}
+	if err := cw.Write(closing); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// amount formats d with the currency's minor units
+func (s *Statement) amount(d money.Decimal) string {
+	return d.StringFixed(money.MinorUnits(s.Currency))
+}
+
+// bankCodes are the codes each format uses for a statement line type
+type bankCodes struct {
+	mt940    string // MT940 :61: transaction type identification code
+	domain   string // camt.053 bank transaction code domain
+	family   string // camt.053 family for credits
+	debitFam string // camt.053 family for debits, family when empty
+	sub      string // camt.053 sub-family
+	ofx      string // OFX TRNTYPE
+}
+
+var lineCodes = map[string]bankCodes{
+	TypePayin:      {mt940: "NTRF", domain: "PMNT", family: "RCDT", sub: "OTHR", ofx: "DEP"},
+	TypePayout:     {mt940: "NTRF", domain: "PMNT", family: "ICDT", sub: "OTHR", ofx: "PAYMENT"},
+	TypeTransfer:   {mt940: "NTRF", domain: "PMNT", family: "RCDT", debitFam: "ICDT", sub: "OTHR", ofx: "XFER"},
+	TypeConversion: {mt940: "NFEX", domain: "FORX", family: "SPOT", sub: "OTHR", ofx: "OTHER"},
+	TypeFee:        {mt940: "NCHG", domain: "ACMT", family: "MDOP", sub: "CHRG", ofx: "FEE"},
+	TypeRefund:     {mt940: "NRTI", domain: "PMNT", family: "ICDT", sub: "RRTN", ofx: "CREDIT"},
+	TypeCard:       {mt940: "NMSC", domain: "PMNT", family: "CCRD", sub: "OTHR", ofx: "XFER"},
+	TypeAdjustment: {mt940: "NMSC", domain: "ACMT", family: "MCOP", debitFam: "MDOP", sub: "ADJT", ofx: "OTHER"},
+	TypeOther:      {mt940: "NMSC", domain: "ACMT", family: "MCOP", debitFam: "MDOP", sub: "OTHR", ofx: "OTHER"},
+}
+
+func codesFor(l Line) bankCodes {
+	codes, ok := lineCodes[l.Type]
+	if !ok {
+		codes = lineCodes[TypeOther]
+	}
+	if !l.Credit() && codes.debitFam != "" {
+		codes.family = codes.debitFam
+	}
+	return codes
+}
@@ -1,15 +1,32 @@
 package supporting
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"path/filepath"
+	"strings"
 
 	"github.com/jackillll/uqpay-sdk-go/common"
 )
 
+// MaxUploadSize is the largest file accepted by Upload (20MB)
+const MaxUploadSize = 20 << 20
+
+// ErrUnsupportedFileType is returned by Upload for files that are not jpeg, png, jpg, doc, docx or pdf
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// uploadContentTypes maps the supported file extensions to their content types
+var uploadContentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"jpg":  "image/jpeg",
+	"png":  "image/png",
+	"pdf":  "application/pdf",
+	"doc":  "application/msword",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
 // FilesClient handles file operations
 type FilesClient struct {
 	client *common.APIClient
@@ -17,8 +34,8 @@ type FilesClient struct {
 
 // UploadFileParams represents file upload parameters
 type UploadFileParams struct {
-	File     io.Reader
-	FileName string
+	File     io.Reader // an io.Seeker such as *os.File lets failed uploads be retried
+	FileName string    // must end in .jpeg, .jpg, .png, .doc, .docx or .pdf
 	Notes    string
 }
 
@@ -57,49 +74,48 @@ type DownloadLinksResponse struct {
 // Maximum file size: 20MB
 // Supported types: jpeg, png, jpg, doc, docx, pdf
 func (c *FilesClient) Upload(ctx context.Context, params *UploadFileParams) (*UploadFileResponse, error) {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-
-	// Create form file
-	part, err := writer.CreateFormFile("file", params.FileName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
+	ctx = common.WithOperation(ctx, "supporting.files.upload")
+	if params == nil || params.File == nil {
+		return nil, fmt.Errorf("failed to upload file: file is required")
 	}
-
-	// Copy file content
-	if _, err := io.Copy(part, params.File); err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
+	contentType, err := uploadContentType(params.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// Add notes if provided
+	var fields map[string]string
 	if params.Notes != "" {
-		if err := writer.WriteField("notes", params.Notes); err != nil {
-			return nil, fmt.Errorf("failed to write notes field: %w", err)
-		}
+		fields = map[string]string{"notes": params.Notes}
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	// TODO: Implement multipart form-data POST in APIClient
-	// For now, this is a placeholder implementation
 	var resp UploadFileResponse
-	path := "/v1/files/upload"
-	if params.Notes != "" {
-		path += fmt.Sprintf("?notes=%s", params.Notes)
+	file := &common.MultipartFile{
+		FieldName:   "file",
+		FileName:    params.FileName,
+		ContentType: contentType,
+		Reader:      params.File,
+		MaxSize:     MaxUploadSize,
 	}
+	if err := c.client.PostMultipart(ctx, "/v1/files/upload", fields, file, &resp); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	return &resp, nil
+}
 
-	// Note: This requires special handling in APIClient for multipart/form-data
-	// The actual implementation needs to be added to common.APIClient
-	_ = path // Placeholder to avoid unused variable error
-
-	return &resp, fmt.Errorf("file upload not yet implemented - requires multipart/form-data support")
+// uploadContentType returns the content type for a supported file name
+func uploadContentType(fileName string) (string, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	contentType, ok := uploadContentTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFileType, fileName)
+	}
+	return contentType, nil
 }
 
 // GetDownloadLinks retrieves download links for specified file IDs
 // POST /v1/files/download_links
 func (c *FilesClient) GetDownloadLinks(ctx context.Context, req *DownloadLinksRequest) (*DownloadLinksResponse, error) {
+	ctx = common.WithOperation(ctx, "supporting.files.get_download_links")
 	var resp DownloadLinksResponse
 	if err := c.client.Post(ctx, "/v1/files/download_links", req, &resp); err != nil {
 		return nil, fmt.Errorf("failed to get download links: %w", err)
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+)
+
+func TestWithAccount(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]string{}
+	server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path] = r.Header.Get("x-on-behalf-of")
+		mu.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/files"):
+			writeJSON(w, http.StatusOK, supporting.DownloadLinksResponse{})
+		default:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": []interface{}{}})
+		}
+	})
+	header := func(path string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return seen[path]
+	}
+
+	env := &configuration.Environment{BaseURL: server.URL, FilesBaseURL: server.URL}
+	client, err := uqpay.NewClient("id", "key", env, uqpay.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("ScopedClient", func(t *testing.T) {
+		merchant := client.WithAccount("acct-1")
+		if _, err := merchant.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1}); err != nil {
+			t.Fatalf("List balances failed: %v", err)
+		}
+		if got := header("/v1/balances"); got != "acct-1" {
+			t.Errorf("Expected x-on-behalf-of acct-1, got %q", got)
+		}
+
+		if _, err := merchant.Supporting.Files.GetDownloadLinks(ctx, &supporting.DownloadLinksRequest{FileIDs: []string{"f-1"}}); err != nil {
+			t.Fatalf("GetDownloadLinks failed: %v", err)
+		}
+		if got := header("/v1/files/download_links"); got != "acct-1" {
+			t.Errorf("Expected Files request on behalf of acct-1, got %q", got)
+		}
+	})
+
+	t.Run("ParentUnchanged", func(t *testing.T) {
+		client.WithAccount("acct-2")
+		if _, err := client.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1}); err != nil {
+			t.Fatalf("List balances failed: %v", err)
+		}
+		if got := header("/v1/balances"); got != "" {
+			t.Errorf("Expected no x-on-behalf-of for the master client, got %q", got)
+		}
+	})
+
+	t.Run("ContextOverride", func(t *testing.T) {
+		merchant := client.WithAccount("acct-1")
+
+		scopedCtx := common.WithAccountID(ctx, "acct-3")
+		merchant.Banking.Balances.List(scopedCtx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1})
+		if got := header("/v1/balances"); got != "acct-3" {
+			t.Errorf("Expected context account acct-3, got %q", got)
+		}
+
+		masterCtx := common.WithAccountID(ctx, "")
+		merchant.Banking.Balances.List(masterCtx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1})
+		if got := header("/v1/balances"); got != "" {
+			t.Errorf("Expected empty context account to act as master, got %q", got)
+		}
+	})
+
+	t.Run("RescopeToMaster", func(t *testing.T) {
+		master := client.WithAccount("acct-1").WithAccount("")
+		master.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1})
+		if got := header("/v1/balances"); got != "" {
+			t.Errorf("Expected no x-on-behalf-of, got %q", got)
+		}
+	})
+	t.Run("MiddlewareEditsOwnHeaderOnly", func(t *testing.T) {
+		var tampered bool
+		tamper := func(next common.RoundTripFunc) common.RoundTripFunc {
+			return func(req *http.Request) (*common.Response, error) {
+				if values := req.Header[http.CanonicalHeaderKey(common.OnBehalfOfHeader)]; len(values) > 0 && !tampered {
+					values[0], tampered = "tampered", true
+				}
+				return next(req)
+			}
+		}
+		tampering, err := uqpay.NewClient("id", "key", env, uqpay.WithHTTPClient(server.Client()), uqpay.WithMiddleware(tamper))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		merchant := tampering.WithAccount("acct-1")
+		merchant.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1})
+		if got := header("/v1/balances"); got != "tampered" {
+			t.Fatalf("Expected the middleware to edit its request, got %q", got)
+		}
+		merchant.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1})
+		if got := header("/v1/balances"); got != "acct-1" {
+			t.Errorf("Expected the next request on behalf of acct-1, got %q", got)
+		}
+	})
+}
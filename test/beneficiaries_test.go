@@ -143,10 +143,10 @@ func TestBeneficiaries(t *testing.T) {
 			if len(method.OptionalFields) > 0 {
 				t.Logf("   Optional Fields: %v", method.OptionalFields)
 			}
-			if method.MinAmount != "" {
+			if method.MinAmount != nil {
 				t.Logf("   Min Amount: %s", method.MinAmount)
 			}
-			if method.MaxAmount != "" {
+			if method.MaxAmount != nil {
 				t.Logf("   Max Amount: %s", method.MaxAmount)
 			}
 		}
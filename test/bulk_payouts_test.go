@@ -0,0 +1,198 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func TestParseBulkPayouts(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		input := "\ufeffBeneficiary_ID,currency,amount,purpose,reference,beneficiary_name,account_number,bank_name,country\n" +
+			"ben-1,USD,100.50,salary,MAY-001,,,,\n" +
+			"\n" +
+			",USD,20,vendor_payment,MAY-002,Acme Ltd,987654,Acme Bank,US\n"
+		rows, err := banking.ParseBulkPayoutsCSV(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ParseBulkPayoutsCSV failed: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 rows, got %d", len(rows))
+		}
+		if rows[0].Line != 2 || rows[0].BeneficiaryID != "ben-1" || !rows[0].Amount.Equal(money.MustParse("100.50")) || rows[0].PayoutPurpose != "salary" {
+			t.Errorf("Unexpected first row: %+v", rows[0])
+		}
+		inline := rows[1].Beneficiary
+		if rows[1].Line != 4 || inline == nil || inline.BeneficiaryName != "Acme Ltd" || inline.BankDetails.AccountNumber != "987654" {
+			t.Fatalf("Unexpected inline row: %+v", rows[1])
+		}
+		if inline.ContactDetails == nil || inline.ContactDetails.Country != "US" {
+			t.Errorf("Expected inline contact country US, got %+v", inline.ContactDetails)
+		}
+	})
+
+	t.Run("CSVErrors", func(t *testing.T) {
+		if _, err := banking.ParseBulkPayoutsCSV(strings.NewReader("currency,amount,colour\n")); err == nil || !strings.Contains(err.Error(), "colour") {
+			t.Errorf("Expected unknown column error, got %v", err)
+		}
+		if _, err := banking.ParseBulkPayoutsCSV(strings.NewReader("currency,amount\nUSD,ten\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected invalid amount error on line 2, got %v", err)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		input := `[{"beneficiary_id":"ben-1","currency":"USD","amount":"12.34","payout_purpose":"salary","payment_method":"LOCAL"}]`
+		rows, err := banking.ParseBulkPayoutsJSON(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ParseBulkPayoutsJSON failed: %v", err)
+		}
+		if len(rows) != 1 || rows[0].Line != 1 || rows[0].PaymentMethod != "LOCAL" || !rows[0].Amount.Equal(money.MustParse("12.34")) {
+			t.Errorf("Unexpected rows: %+v", rows)
+		}
+	})
+}
+
+func TestBulkPayoutRunner(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "500")
+	beneficiaryID := createFakeBeneficiary(t, client)
+
+	row := func(line int, amount, reference string) banking.BulkPayoutRow {
+		return banking.BulkPayoutRow{Line: line, CreatePayoutRequest: banking.CreatePayoutRequest{
+			BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse(amount), PayoutPurpose: "salary", Reference: reference,
+		}}
+	}
+	rows := []banking.BulkPayoutRow{
+		row(2, "100", "R1"),
+		row(3, "0.50", "R2"),   // below the LOCAL minimum
+		row(4, "10.001", "R3"), // too many decimal places
+		{Line: 5, CreatePayoutRequest: banking.CreatePayoutRequest{BeneficiaryID: "ben-missing", Currency: "USD", Amount: money.MustParse("5"), PayoutPurpose: "salary"}},
+		row(6, "350", "R5"),
+		row(7, "100", "R6"), // overdraws the balance
+		row(8, "100", "R1"), // duplicate of line 2, must get its own key
+	}
+	statuses := func(report *banking.BulkPayoutReport) []string {
+		var out []string
+		for _, r := range report.Results {
+			out = append(out, r.Status)
+		}
+		return out
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		runner := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{BatchID: "may", DryRun: true})
+		report, err := runner.Run(ctx, rows)
+		if !errors.Is(err, banking.ErrBulkValidation) {
+			t.Fatalf("Expected ErrBulkValidation for a dry run with invalid rows, got %v", err)
+		}
+		want := []string{"VALID", "INVALID", "INVALID", "INVALID", "VALID", "INVALID", "INVALID"}
+		if got := statuses(report); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+		for i, substr := range map[int]string{1: "minimum", 2: "decimal places", 3: "not found", 5: "insufficient USD balance"} {
+			if err := report.Results[i].Err; err == nil || !strings.Contains(err.Error(), substr) {
+				t.Errorf("Expected line %d error containing %q, got %v", report.Results[i].Row.Line, substr, err)
+			}
+		}
+		if report.Results[0].IdempotencyKey == report.Results[6].IdempotencyKey {
+			t.Errorf("Expected duplicate rows to get distinct idempotency keys")
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("500")) {
+			t.Errorf("Expected dry run to leave balance at 500, got %s", got)
+		}
+	})
+
+	t.Run("InlineBeneficiaryWithoutCountry", func(t *testing.T) {
+		runner := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{BatchID: "inline", DryRun: true})
+		report, err := runner.Run(ctx, []banking.BulkPayoutRow{{Line: 2, CreatePayoutRequest: banking.CreatePayoutRequest{
+			Currency: "USD", Amount: money.MustParse("5000000"), PayoutPurpose: "salary",
+			Beneficiary: &banking.PayoutBeneficiary{BeneficiaryName: "Acme Ltd", BankDetails: &banking.PayoutBankDetails{AccountNumber: "987654"}},
+		}}})
+		if !errors.Is(err, banking.ErrBulkValidation) {
+			t.Fatalf("Expected ErrBulkValidation, got %v", err)
+		}
+		result := report.Results[0]
+		if result.Status != banking.BulkRowInvalid || result.Err == nil || !strings.Contains(result.Err.Error(), "beneficiary country required") {
+			t.Errorf("Expected an invalid row without a country, got %s: %v", result.Status, result.Err)
+		}
+	})
+
+	t.Run("DryRunSkipInvalid", func(t *testing.T) {
+		runner := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{BatchID: "may", DryRun: true, SkipInvalid: true})
+		report, err := runner.Run(ctx, rows)
+		if err != nil {
+			t.Fatalf("Expected no error when invalid rows are skipped, got %v", err)
+		}
+		if report.Count(banking.BulkRowValid) != 2 || report.Count(banking.BulkRowInvalid) != 5 {
+			t.Errorf("Expected 2 valid and 5 invalid rows, got %v", statuses(report))
+		}
+	})
+
+	t.Run("AbortOnInvalid", func(t *testing.T) {
+		runner := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{BatchID: "may"})
+		report, err := runner.Run(ctx, rows)
+		if !errors.Is(err, banking.ErrBulkValidation) {
+			t.Fatalf("Expected ErrBulkValidation, got %v", err)
+		}
+		if report.Count(banking.BulkRowSkipped) != 2 || len(report.PayoutIDs()) != 0 {
+			t.Errorf("Expected 2 skipped rows and no payouts, got %v", statuses(report))
+		}
+	})
+
+	t.Run("SubmitAndRerun", func(t *testing.T) {
+		runner := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{BatchID: "may", Concurrency: 2, SkipInvalid: true})
+		report, err := runner.Run(ctx, rows)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		ids := report.PayoutIDs()
+		if len(ids) != 2 || report.Count(banking.BulkRowSubmitted) != 2 {
+			t.Fatalf("Expected 2 payouts, got %v", statuses(report))
+		}
+		if total := report.Totals(banking.BulkRowSubmitted)["USD"]; !total.Equal(money.MustParse("450")) {
+			t.Errorf("Expected 450 USD submitted, got %s", total)
+		}
+
+		// Rerunning the batch with rows reordered replays the same payouts
+		rerun := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{BatchID: "may", SkipBalanceCheck: true})
+		again, err := rerun.Run(ctx, []banking.BulkPayoutRow{rows[4], rows[0]})
+		if err != nil {
+			t.Fatalf("Rerun failed: %v", err)
+		}
+		if again.Results[0].PayoutID != ids[1] || again.Results[1].PayoutID != ids[0] {
+			t.Errorf("Expected rerun to return payouts %v, got %v", ids, again.PayoutIDs())
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("50")) {
+			t.Errorf("Expected balance 50 after one debit per payout, got %s", got)
+		}
+
+		var out bytes.Buffer
+		if err := report.WriteCSV(&out); err != nil {
+			t.Fatalf("WriteCSV failed: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != len(rows)+1 || !strings.HasPrefix(lines[0], "line,status,") || !strings.Contains(lines[1], ids[0]) {
+			t.Errorf("Unexpected CSV report:\n%s", out.String())
+		}
+		out.Reset()
+		if err := report.WriteJSON(&out); err != nil {
+			t.Fatalf("WriteJSON failed: %v", err)
+		}
+		if !strings.Contains(out.String(), `"batch_id": "may"`) || !strings.Contains(out.String(), ids[1]) {
+			t.Errorf("Unexpected JSON report:\n%s", out.String())
+		}
+	})
+
+	t.Run("BatchIDRequired", func(t *testing.T) {
+		if _, err := banking.NewBulkPayoutRunner(client.Banking, banking.BulkPayoutOptions{}).Run(ctx, rows); err == nil {
+			t.Error("Expected error without BatchID")
+		}
+	})
+}
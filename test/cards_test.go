@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 func TestCards(t *testing.T) {
@@ -41,7 +42,7 @@ func TestCards(t *testing.T) {
 
 		cardID := "test-card-id"
 		req := &issuing.CardOrderRequest{
-			Amount: 100.00,
+			Amount: money.Number(money.MustParse("100.00")),
 		}
 
 		order, err := client.Issuing.Cards.Recharge(ctx, cardID, req)
@@ -49,7 +50,7 @@ func TestCards(t *testing.T) {
 			t.Fatalf("Failed to recharge card: %v", err)
 		}
 
-		t.Logf("✅ Recharge order created: ID=%s, Status=%s, Amount=%.2f",
+		t.Logf("✅ Recharge order created: ID=%s, Status=%s, Amount=%s",
 			order.CardOrderID, order.OrderStatus, order.Amount)
 	})
 
@@ -58,7 +59,7 @@ func TestCards(t *testing.T) {
 
 		cardID := "test-card-id"
 		req := &issuing.CardOrderRequest{
-			Amount: 50.00,
+			Amount: money.Number(money.MustParse("50.00")),
 		}
 
 		order, err := client.Issuing.Cards.Withdraw(ctx, cardID, req)
@@ -66,7 +67,7 @@ func TestCards(t *testing.T) {
 			t.Fatalf("Failed to withdraw from card: %v", err)
 		}
 
-		t.Logf("✅ Withdraw order created: ID=%s, Status=%s, Amount=%.2f",
+		t.Logf("✅ Withdraw order created: ID=%s, Status=%s, Amount=%s",
 			order.CardOrderID, order.OrderStatus, order.Amount)
 	})
 }
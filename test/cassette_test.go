@@ -0,0 +1,286 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+	"github.com/jackillll/uqpay-sdk-go/connect"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+// newCassetteClient returns a client replaying testdata/cassettes/<name>.json, which must
+// have been recorded against the sandbox. The test is skipped when the cassette has not been
+// recorded yet. With UQPAY_RECORD=1 it records against the sandbox and fails without
+// UQPAY_CLIENT_ID and UQPAY_API_KEY.
+func newCassetteClient(t *testing.T, name string) (*uqpay.Client, *uqpaytest.Recorder) {
+	t.Helper()
+	env := configuration.Sandbox()
+	clientID, apiKey := "replay-client", "replay-key"
+	if os.Getenv(uqpaytest.RecordEnv) == "1" {
+		clientID, apiKey = os.Getenv("UQPAY_CLIENT_ID"), os.Getenv("UQPAY_API_KEY")
+		if clientID == "" || apiKey == "" {
+			t.Fatalf("%s=1 records against the sandbox and needs UQPAY_CLIENT_ID and UQPAY_API_KEY", uqpaytest.RecordEnv)
+		}
+	} else if _, err := os.Stat(filepath.Join("testdata", "cassettes", name+".json")); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("cassette %s not recorded; run with %s=1 and sandbox credentials", name, uqpaytest.RecordEnv)
+	}
+
+	rec := uqpaytest.NewCassette(t, name)
+	if rec.Recording() {
+		rec.SetSource(uqpaytest.SourceSandbox)
+	} else if rec.Source() != uqpaytest.SourceSandbox {
+		t.Fatalf("cassette %s was recorded against %q; only sandbox cassettes are replayed", name, rec.Source())
+	}
+
+	client, err := uqpay.NewClient(clientID, apiKey, env,
+		uqpay.WithHTTPClient(rec.HTTPClient()),
+		uqpay.WithRetryPolicy(common.NoRetry()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client, rec
+}
+
+func TestCassetteIssuing(t *testing.T) {
+	client, rec := newCassetteClient(t, "issuing")
+	ctx := context.Background()
+
+	products, err := client.Issuing.Products.List(ctx, &issuing.ListProductsRequest{PageSize: 10, PageNumber: 1})
+	if err != nil {
+		t.Fatalf("List products failed: %v", err)
+	}
+	if len(products.Data) == 0 {
+		t.Fatal("Expected at least one card product")
+	}
+
+	holder, err := client.Issuing.Cardholders.Create(ctx, &issuing.CreateCardholderRequest{
+		Email: "cassette@example.com", FirstName: "Cassette", LastName: "Test", CountryCode: "SG", PhoneNumber: "6581234567",
+	})
+	if err != nil {
+		t.Fatalf("Create cardholder failed: %v", err)
+	}
+
+	card, err := client.Issuing.Cards.Create(ctx, &issuing.CreateCardRequest{
+		CardCurrency: "USD", CardholderID: holder.CardholderID, CardProductID: products.Data[0].ProductID,
+	})
+	if err != nil {
+		t.Fatalf("Create card failed: %v", err)
+	}
+
+	t.Run("SecureDetailsAreScrubbed", func(t *testing.T) {
+		secure, err := client.Issuing.Cards.GetSecure(ctx, card.CardID)
+		if err != nil {
+			t.Fatalf("GetSecure failed: %v", err)
+		}
+		if rec.Recording() {
+			t.Skip("live responses are only scrubbed in the cassette")
+		}
+		if !strings.Contains(secure.CardNumber, "XXXXXX") {
+			t.Errorf("Expected masked card number, got %s", secure.CardNumber)
+		}
+		if secure.CVV != "[REDACTED]" {
+			t.Errorf("Expected redacted CVV, got %s", secure.CVV)
+		}
+	})
+
+	t.Run("Recharge", func(t *testing.T) {
+		// The generated idempotency key differs from the recorded one and is ignored when matching
+		order, err := client.Issuing.Cards.Recharge(ctx, card.CardID, &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("25"))})
+		if err != nil {
+			t.Fatalf("Recharge failed: %v", err)
+		}
+		if order.CardID != card.CardID {
+			t.Errorf("Expected order for %s, got %s", card.CardID, order.CardID)
+		}
+	})
+}
+
+func TestCassetteBanking(t *testing.T) {
+	client, _ := newCassetteClient(t, "banking")
+	ctx := context.Background()
+
+	if _, err := client.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1}); err != nil {
+		t.Fatalf("List balances failed: %v", err)
+	}
+
+	beneficiary, err := client.Banking.Beneficiaries.Create(ctx, &banking.BeneficiaryCreationRequest{
+		EntityType:    "COMPANY",
+		CompanyName:   "Cassette Supplies Ltd",
+		Currency:      "USD",
+		Country:       "US",
+		PaymentMethod: "LOCAL",
+		BankDetails:   &banking.BankDetails{AccountNumber: "000123456789", BankName: "Test Bank", RoutingNumber: "021000021"},
+		Address:       &banking.Address{FirstLine: "1 Main St", City: "New York", Country: "US"},
+	})
+	if err != nil {
+		t.Fatalf("Create beneficiary failed: %v", err)
+	}
+
+	payout, err := client.Banking.Payouts.Create(ctx, &banking.CreatePayoutRequest{
+		BeneficiaryID: beneficiary.BeneficiaryID, Currency: "USD", Amount: money.MustParse("12.34"), PayoutPurpose: "vendor_payment",
+	})
+	if err != nil {
+		t.Fatalf("Create payout failed: %v", err)
+	}
+	detail, err := client.Banking.Payouts.Get(ctx, payout.PayoutID)
+	if err != nil {
+		t.Fatalf("Get payout failed: %v", err)
+	}
+	if !detail.Amount.Equal(money.MustParse("12.34")) {
+		t.Errorf("Expected amount 12.34, got %s", detail.Amount)
+	}
+
+	if _, err := client.Banking.ExchangeRates.List(ctx, &banking.ListRatesRequest{CurrencyPairs: []string{"USD/EUR"}}); err != nil {
+		t.Fatalf("List rates failed: %v", err)
+	}
+	quote, err := client.Banking.Conversions.CreateQuote(ctx, &banking.CreateQuoteRequest{
+		CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100"),
+	})
+	if err != nil {
+		t.Fatalf("CreateQuote failed: %v", err)
+	}
+	if quote.QuoteID == "" {
+		t.Error("Expected quote ID")
+	}
+}
+
+func TestCassetteConnect(t *testing.T) {
+	client, _ := newCassetteClient(t, "connect")
+	ctx := context.Background()
+
+	account, err := client.Connect.Accounts.CreateSubAccount(ctx, &connect.CreateAccountRequest{
+		EntityType: connect.EntityTypeIndividual,
+		Individual: &connect.IndividualDetails{
+			FirstName:   "Cassette",
+			LastName:    "Merchant",
+			DateOfBirth: "1990-01-01",
+			Address:     connect.Address{Line1: "1 Orchard Rd", City: "Singapore", PostalCode: "238801", Country: "SG"},
+			ContactInfo: connect.ContactDetails{Email: "merchant@example.com", PhoneNumber: "6581234567"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubAccount failed: %v", err)
+	}
+
+	got, err := client.Connect.Accounts.Get(ctx, account.AccountID)
+	if err != nil {
+		t.Fatalf("Get account failed: %v", err)
+	}
+	if got.AccountID != account.AccountID {
+		t.Errorf("Expected account %s, got %s", account.AccountID, got.AccountID)
+	}
+
+	scoped := client.WithAccount(account.AccountID)
+	if _, err := scoped.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1}); err != nil {
+		t.Fatalf("List sub-account balances failed: %v", err)
+	}
+}
+
+func TestCassetteSupporting(t *testing.T) {
+	client, _ := newCassetteClient(t, "supporting")
+	ctx := context.Background()
+
+	uploaded, err := client.Supporting.Files.Upload(ctx, &supporting.UploadFileParams{
+		File: strings.NewReader("%PDF-1.4 cassette"), FileName: "statement.pdf", Notes: "cassette",
+	})
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	links, err := client.Supporting.Files.GetDownloadLinks(ctx, &supporting.DownloadLinksRequest{FileIDs: []string{uploaded.FileID}})
+	if err != nil {
+		t.Fatalf("GetDownloadLinks failed: %v", err)
+	}
+	if len(links.Files) != 1 {
+		t.Errorf("Expected 1 download link, got %d", len(links.Files))
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	server := uqpaytest.NewServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	newClient := func(rec *uqpaytest.Recorder) *uqpay.Client {
+		client, err := uqpay.NewClient(uqpaytest.ClientID, uqpaytest.APIKey, server.Environment(),
+			uqpay.WithHTTPClient(rec.HTTPClient()), uqpay.WithRetryPolicy(common.NoRetry()))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		return client
+	}
+	createCard := func(client *uqpay.Client) (*issuing.SecureCardInfo, error) {
+		holder, err := client.Issuing.Cardholders.Create(ctx, &issuing.CreateCardholderRequest{
+			Email: "rec@example.com", FirstName: "Rec", LastName: "Order", CountryCode: "SG",
+		})
+		if err != nil {
+			return nil, err
+		}
+		card, err := client.Issuing.Cards.Create(ctx, &issuing.CreateCardRequest{
+			CardCurrency: "USD", CardholderID: holder.CardholderID, CardProductID: uqpaytest.ProductID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return client.Issuing.Cards.GetSecure(ctx, card.CardID)
+	}
+
+	rec, err := uqpaytest.NewRecorder(path, uqpaytest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	live, err := createCard(newClient(rec))
+	if err != nil {
+		t.Fatalf("Recording failed: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	t.Run("Scrubbed", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read cassette: %v", err)
+		}
+		for _, secret := range []string{live.CardNumber, `"` + live.CVV + `"`, uqpaytest.APIKey, "x-auth-token", "/v1/connect/token"} {
+			if bytes.Contains(data, []byte(secret)) {
+				t.Errorf("Cassette contains %q", secret)
+			}
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		server.Close()
+		rec, err := uqpaytest.NewRecorder(path, uqpaytest.ModeReplay)
+		if err != nil {
+			t.Fatalf("NewRecorder failed: %v", err)
+		}
+		client := newClient(rec)
+		replayed, err := createCard(client)
+		if err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		if replayed.CardNumber[len(replayed.CardNumber)-4:] != live.CardNumber[len(live.CardNumber)-4:] {
+			t.Errorf("Expected replayed card ending %s, got %s", live.CardNumber[len(live.CardNumber)-4:], replayed.CardNumber)
+		}
+
+		_, err = client.Issuing.Cards.Get(ctx, "card-never-recorded")
+		if !errors.Is(err, uqpaytest.ErrInteractionNotFound) {
+			t.Errorf("Expected ErrInteractionNotFound, got %v", err)
+		}
+	})
+}
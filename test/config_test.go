@@ -0,0 +1,232 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	clearEnv := func(t *testing.T) {
+		for _, name := range []string{
+			configuration.EnvClientID, configuration.EnvAPIKey, configuration.EnvEnvironment,
+			configuration.EnvBaseURL, configuration.EnvFilesBaseURL, configuration.EnvTimeout,
+			configuration.EnvRetryMaxAttempts, configuration.EnvRetryBaseDelay,
+			configuration.EnvRetryMaxDelay, configuration.EnvAccountID,
+		} {
+			t.Setenv(name, "")
+		}
+	}
+
+	t.Run("AllSettings", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(configuration.EnvClientID, "client-1")
+		t.Setenv(configuration.EnvAPIKey, "secret-key")
+		t.Setenv(configuration.EnvEnvironment, "production")
+		t.Setenv(configuration.EnvTimeout, "30s")
+		t.Setenv(configuration.EnvRetryMaxAttempts, "5")
+		t.Setenv(configuration.EnvRetryBaseDelay, "200ms")
+		t.Setenv(configuration.EnvAccountID, "acct-1")
+
+		config, err := configuration.FromEnv()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.ClientID != "client-1" || config.APIKey != "secret-key" {
+			t.Errorf("Unexpected credentials: %s", config)
+		}
+		if config.Environment.BaseURL != configuration.Production().BaseURL {
+			t.Errorf("Expected production base URL, got %s", config.Environment.BaseURL)
+		}
+		if config.Timeout != 30*time.Second {
+			t.Errorf("Expected 30s timeout, got %v", config.Timeout)
+		}
+		if config.Retry == nil || config.Retry.MaxAttempts != 5 || config.Retry.BaseDelay != 200*time.Millisecond {
+			t.Errorf("Unexpected retry settings: %+v", config.Retry)
+		}
+		if config.AccountID != "acct-1" {
+			t.Errorf("Expected account acct-1, got %s", config.AccountID)
+		}
+	})
+
+	t.Run("DefaultsToSandbox", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(configuration.EnvClientID, "client-1")
+		t.Setenv(configuration.EnvAPIKey, "secret-key")
+
+		config, err := configuration.FromEnv()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.Environment.Name != configuration.EnvironmentSandbox || config.Retry != nil || config.Timeout != 0 {
+			t.Errorf("Expected sandbox defaults, got %s", config)
+		}
+	})
+
+	t.Run("MissingFields", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(configuration.EnvEnvironment, "custom")
+
+		_, err := configuration.FromEnv()
+		if !errors.Is(err, configuration.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+		for _, name := range []string{"UQPAY_CLIENT_ID", "UQPAY_API_KEY", "UQPAY_BASE_URL", "UQPAY_FILES_BASE_URL"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("Expected error to name %s, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("InvalidValues", func(t *testing.T) {
+		for name, value := range map[string]string{
+			configuration.EnvEnvironment:      "staging",
+			configuration.EnvTimeout:          "thirty",
+			configuration.EnvRetryMaxAttempts: "many",
+		} {
+			clearEnv(t)
+			t.Setenv(configuration.EnvClientID, "client-1")
+			t.Setenv(configuration.EnvAPIKey, "secret-key")
+			t.Setenv(name, value)
+			if _, err := configuration.FromEnv(); !errors.Is(err, configuration.ErrInvalidConfig) || !strings.Contains(err.Error(), name) {
+				t.Errorf("Expected ErrInvalidConfig naming %s, got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestConfigFromFile(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		path := writeConfigFile(t, "uqpay.yaml", `# UQPAY settings
+client_id: "12345" # don't commit "real" IDs
+api_key: "secret # not a comment"
+environment: custom
+base_url: https://api.example.com/api   # gateway
+files_base_url: 'https://files.example.com/api' # it's the Files API
+timeout: 20s
+
+retry:
+  max_attempts: 4
+  base_delay: 1s
+  max_delay: 5s
+account_id: acct-9
+`)
+		config, err := configuration.FromFile(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.ClientID != "12345" || config.APIKey != "secret # not a comment" {
+			t.Errorf("Unexpected credentials: %q %q", config.ClientID, config.APIKey)
+		}
+		if config.Environment.BaseURL != "https://api.example.com/api" || config.Environment.FilesBaseURL != "https://files.example.com/api" {
+			t.Errorf("Unexpected environment: %+v", config.Environment)
+		}
+		if config.Timeout != 20*time.Second || config.AccountID != "acct-9" {
+			t.Errorf("Unexpected settings: %s", config)
+		}
+		if config.Retry == nil || config.Retry.MaxAttempts != 4 || config.Retry.MaxDelay != 5*time.Second {
+			t.Errorf("Unexpected retry settings: %+v", config.Retry)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		path := writeConfigFile(t, "uqpay.json", `{
+  "client_id": "client-1",
+  "api_key": "secret-key",
+  "environment": "sandbox",
+  "retry": {"max_attempts": 2}
+}`)
+		config, err := configuration.FromFile(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.Environment.BaseURL != configuration.Sandbox().BaseURL || config.Retry.MaxAttempts != 2 {
+			t.Errorf("Unexpected configuration: %s", config)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := map[string]string{
+			"missing.yaml":     "environment: sandbox\n",
+			"bad-indent.yaml":  "client_id: a\n  api_key: b\n",
+			"sequence.yml":     "client_id:\n  - a\n",
+			"unsupported.toml": "client_id = 'a'\n",
+			"bad.json":         "{",
+		}
+		for name, content := range cases {
+			if _, err := configuration.FromFile(writeConfigFile(t, name, content)); !errors.Is(err, configuration.ErrInvalidConfig) {
+				t.Errorf("%s: expected ErrInvalidConfig, got %v", name, err)
+			}
+		}
+		if _, err := configuration.FromFile(filepath.Join(t.TempDir(), "absent.yaml")); err == nil {
+			t.Error("Expected an error for a missing file")
+		}
+	})
+}
+
+func TestConfigRedaction(t *testing.T) {
+	config := &configuration.Configuration{
+		ClientID:    "client-1",
+		APIKey:      "super-secret-key",
+		Environment: configuration.Sandbox(),
+	}
+	embedded := struct {
+		Name string
+		configuration.Configuration
+	}{"tenant", *config}
+	for _, s := range []string{
+		config.String(), fmt.Sprintf("%v", config), fmt.Sprintf("%+v", config), fmt.Sprintf("%#v", config),
+		fmt.Sprintf("%+v", *config), fmt.Sprintf("%#v", *config), fmt.Sprintf("%+v", embedded),
+	} {
+		if strings.Contains(s, "super-secret-key") {
+			t.Errorf("Expected API key to be redacted, got %s", s)
+		}
+		if !strings.Contains(s, "client-1") {
+			t.Errorf("Expected client ID in %s", s)
+		}
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	var onBehalfOf string
+	server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		onBehalfOf = r.Header.Get("x-on-behalf-of")
+		writeJSON(w, http.StatusOK, map[string]string{"card_id": "card-1"})
+	})
+
+	config := &configuration.Configuration{
+		ClientID:    "client-1",
+		APIKey:      "secret-key",
+		Environment: &configuration.Environment{BaseURL: server.URL, FilesBaseURL: server.URL},
+		HTTPClient:  server.Client(),
+		AccountID:   "acct-1",
+	}
+	client, err := uqpay.NewClientFromConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.Issuing.Cards.Get(context.Background(), "card-1"); err != nil {
+		t.Fatalf("Get card failed: %v", err)
+	}
+	if onBehalfOf != "acct-1" {
+		t.Errorf("Expected x-on-behalf-of acct-1, got %q", onBehalfOf)
+	}
+}
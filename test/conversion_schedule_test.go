@@ -0,0 +1,256 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+// testClock is a settable clock shared by the fake server and the code under test
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func TestSettlementDates(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	monday := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	server.Now = func() time.Time { return monday }
+
+	dates, err := client.Banking.Conversions.SettlementDates(ctx, "USD", "EUR")
+	if err != nil {
+		t.Fatalf("SettlementDates failed: %v", err)
+	}
+	if len(dates) != 5 || dates[0].Date != "2024-06-03" {
+		t.Fatalf("Unexpected dates: %+v", dates)
+	}
+	if got := dates[0].UntilFirstCutoff(monday); got != 2*time.Hour {
+		t.Errorf("Expected 2h to the first cutoff, got %s", got)
+	}
+	if got := dates[0].UntilSecondCutoff(monday); got != 8*time.Hour {
+		t.Errorf("Expected 8h to the second cutoff, got %s", got)
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		now      time.Time
+		want     string
+	}{
+		{"Earliest", banking.SettlementEarliest, monday, "2024-06-03"},
+		{"Optimized", banking.SettlementOptimized, monday, "2024-06-04"},
+		{"EarliestAfterCutoff", banking.SettlementEarliest, monday.Add(9 * time.Hour), "2024-06-04"},
+		{"OptimizedFallsBack", banking.SettlementOptimized, monday.Add(33 * time.Hour), "2024-06-05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := banking.PickSettlementDate(dates, tt.strategy, tt.now)
+			if err != nil {
+				t.Fatalf("PickSettlementDate failed: %v", err)
+			}
+			if got.Date != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got.Date)
+			}
+		})
+	}
+
+	if _, err := banking.PickSettlementDate(dates, banking.SettlementEarliest, monday.AddDate(0, 0, 7)); !errors.Is(err, banking.ErrNoSettlementDate) {
+		t.Errorf("Expected ErrNoSettlementDate, got %v", err)
+	}
+}
+
+func TestSettlementDatesSkipsInvalidCutoffs(t *testing.T) {
+	apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []banking.ConversionDate{
+			{Date: "2024-06-04", FirstCutoff: "2024-06-04T10:00:00Z", SecondCutoff: "2024-06-04T16:00:00Z"},
+			{Date: "2024-06-03", FirstCutoff: "", SecondCutoff: "2024-06-03T16:00:00Z"},
+			{Date: "2024-06-05", FirstCutoff: "2024-06-05T10:00:00Z", SecondCutoff: "16:00"},
+		})
+	})
+	dates, err := banking.NewClient(apiClient).Conversions.SettlementDates(context.Background(), "USD", "EUR")
+	if err != nil {
+		t.Fatalf("SettlementDates failed: %v", err)
+	}
+	if len(dates) != 1 || dates[0].Date != "2024-06-04" {
+		t.Errorf("Expected only 2024-06-04, got %+v", dates)
+	}
+}
+
+func TestConversionScheduler(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "1000")
+	monday := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	clock := &testClock{now: monday}
+	server.Now = clock.Now
+
+	var warnings []error
+	var results []banking.ScheduledConversion
+	scheduler := banking.NewConversionScheduler(client.Banking.Conversions, banking.ConversionSchedulerOptions{
+		Lead:      30 * time.Minute,
+		OnWarning: func(_ banking.ScheduledConversion, err error) { warnings = append(warnings, err) },
+		OnResult:  func(c banking.ScheduledConversion) { results = append(results, c) },
+		Now:       clock.Now,
+	})
+	request := banking.CreateConversionRequest{CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100")}
+
+	t.Run("SubmitsBeforeCutoff", func(t *testing.T) {
+		clock.Set(monday)
+		scheduled, err := scheduler.Schedule(ctx, request)
+		if err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		if scheduled.SettlementDate.Date != "2024-06-03" || !scheduled.ExecuteAt.Equal(monday.Add(90*time.Minute)) {
+			t.Errorf("Expected execution at 09:30 for 2024-06-03, got %s for %s", scheduled.ExecuteAt, scheduled.SettlementDate.Date)
+		}
+		if got := scheduler.RunDue(ctx); len(got) != 0 {
+			t.Errorf("Expected nothing due at 08:00, got %+v", got)
+		}
+
+		clock.Set(monday.Add(95 * time.Minute))
+		got := scheduler.RunDue(ctx)
+		if len(got) != 1 || got[0].Status != banking.ScheduledStatusSubmitted || got[0].Conversion == nil {
+			t.Fatalf("Expected one submitted conversion, got %+v", got)
+		}
+		conversion, err := client.Banking.Conversions.Get(ctx, got[0].Conversion.ConversionID)
+		if err != nil {
+			t.Fatalf("Get conversion failed: %v", err)
+		}
+		if conversion.SettlementDate != "2024-06-03" {
+			t.Errorf("Expected settlement on 2024-06-03, got %s", conversion.SettlementDate)
+		}
+		if len(warnings) != 0 || len(scheduler.Pending()) != 0 {
+			t.Errorf("Expected no warnings or pending conversions, got %v, %+v", warnings, scheduler.Pending())
+		}
+	})
+
+	t.Run("WarnsAfterFirstCutoff", func(t *testing.T) {
+		warnings = nil
+		clock.Set(monday.Add(4 * time.Hour)) // 12:00, between the cutoffs
+		req := request
+		req.SettlementDate = "2024-06-03"
+		if _, err := scheduler.Schedule(ctx, req); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		got := scheduler.RunDue(ctx)
+		if len(got) != 1 || got[0].Status != banking.ScheduledStatusSubmitted {
+			t.Fatalf("Expected immediate submission, got %+v", got)
+		}
+		if len(warnings) != 1 || !errors.Is(warnings[0], banking.ErrFirstCutoffMissed) {
+			t.Errorf("Expected one first cutoff warning, got %v", warnings)
+		}
+	})
+
+	t.Run("RetriesTransientError", func(t *testing.T) {
+		results = nil
+		clock.Set(monday)
+		if _, err := scheduler.Schedule(ctx, request); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		server.Inject(uqpaytest.Fault{Method: http.MethodPost, Path: "/v1/conversion", Status: http.StatusServiceUnavailable, Times: 1})
+		clock.Set(monday.Add(95 * time.Minute))
+		got := scheduler.RunDue(ctx)
+		if len(got) != 1 || got[0].Status != banking.ScheduledStatusPending || got[0].Err == nil {
+			t.Fatalf("Expected the conversion to stay pending after a 503, got %+v", got)
+		}
+		if len(results) != 0 || len(scheduler.Pending()) != 1 {
+			t.Fatalf("Expected no result and one pending conversion, got %+v and %+v", results, scheduler.Pending())
+		}
+		if got := scheduler.RunDue(ctx); len(got) != 0 {
+			t.Errorf("Expected no retry before Interval, got %+v", got)
+		}
+
+		clock.Set(monday.Add(96 * time.Minute))
+		got = scheduler.RunDue(ctx)
+		if len(got) != 1 || got[0].Status != banking.ScheduledStatusSubmitted || got[0].Err != nil {
+			t.Fatalf("Expected the retry to submit the conversion, got %+v", got)
+		}
+		if len(results) != 1 || len(scheduler.Pending()) != 0 {
+			t.Errorf("Expected one result and nothing pending, got %+v and %+v", results, scheduler.Pending())
+		}
+	})
+
+	t.Run("FailsOnRejection", func(t *testing.T) {
+		clock.Set(monday)
+		if _, err := scheduler.Schedule(ctx, request); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		server.Inject(uqpaytest.Fault{Method: http.MethodPost, Path: "/v1/conversion", Status: http.StatusBadRequest, Code: "invalid_request", Times: 1})
+		clock.Set(monday.Add(95 * time.Minute))
+		got := scheduler.RunDue(ctx)
+		if len(got) != 1 || got[0].Status != banking.ScheduledStatusFailed || len(scheduler.Pending()) != 0 {
+			t.Errorf("Expected a failed conversion that is not retried, got %+v", got)
+		}
+	})
+
+	t.Run("MissesFinalCutoff", func(t *testing.T) {
+		results = nil
+		clock.Set(monday)
+		before := server.Balance("USD")
+		if _, err := scheduler.Schedule(ctx, request); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		clock.Set(monday.Add(9 * time.Hour)) // 17:00, after both cutoffs
+		got := scheduler.RunDue(ctx)
+		if len(got) != 1 || got[0].Status != banking.ScheduledStatusMissed || !errors.Is(got[0].Err, banking.ErrCutoffMissed) {
+			t.Fatalf("Expected a missed conversion, got %+v", got)
+		}
+		if len(results) != 1 || results[0].Status != banking.ScheduledStatusMissed {
+			t.Errorf("Expected OnResult for the missed conversion, got %+v", results)
+		}
+		if got := server.Balance("USD"); !got.Equal(before) {
+			t.Errorf("Expected no conversion, USD balance went from %s to %s", before, got)
+		}
+	})
+
+	t.Run("RejectsClosedOrUnknownDates", func(t *testing.T) {
+		clock.Set(monday.Add(9 * time.Hour))
+		req := request
+		req.SettlementDate = "2024-06-03"
+		if _, err := scheduler.Schedule(ctx, req); !errors.Is(err, banking.ErrCutoffMissed) {
+			t.Errorf("Expected ErrCutoffMissed, got %v", err)
+		}
+		req.SettlementDate = "2024-06-08"
+		if _, err := scheduler.Schedule(ctx, req); !errors.Is(err, banking.ErrNoSettlementDate) {
+			t.Errorf("Expected ErrNoSettlementDate, got %v", err)
+		}
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		clock.Set(monday)
+		runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		runner := banking.NewConversionScheduler(client.Banking.Conversions, banking.ConversionSchedulerOptions{
+			Interval: time.Millisecond,
+			OnResult: func(banking.ScheduledConversion) { cancel() },
+			Now:      clock.Now,
+		})
+		if _, err := runner.Schedule(ctx, request); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		go clock.Set(monday.Add(2 * time.Hour))
+		if err := runner.Run(runCtx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected Run to stop after the conversion was submitted, got %v", err)
+		}
+	})
+}
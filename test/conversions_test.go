@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
 )
 
 func TestConversionCreateQuote(t *testing.T) {
@@ -21,7 +22,7 @@ func TestConversionCreateQuote(t *testing.T) {
 	req := &banking.CreateQuoteRequest{
 		CurrencyFrom: "USD",
 		CurrencyTo:   "EUR",
-		AmountFrom:   "100.00",
+		AmountFrom:   money.MustParse("100.00"),
 	}
 
 	t.Logf("Creating quote: %s -> %s, Amount: %s", req.CurrencyFrom, req.CurrencyTo, req.AmountFrom)
@@ -40,13 +41,13 @@ func TestConversionCreateQuote(t *testing.T) {
 	if quote.CurrencyTo != req.CurrencyTo {
 		t.Errorf("Expected currency_to %s, got %s", req.CurrencyTo, quote.CurrencyTo)
 	}
-	if quote.AmountFrom != req.AmountFrom {
+	if !quote.AmountFrom.Equal(req.AmountFrom) {
 		t.Errorf("Expected amount_from %s, got %s", req.AmountFrom, quote.AmountFrom)
 	}
-	if quote.Rate == "" {
+	if quote.Rate.IsZero() {
 		t.Error("Expected rate to be set")
 	}
-	if quote.AmountTo == "" {
+	if quote.AmountTo.IsZero() {
 		t.Error("Expected amount_to to be set")
 	}
 	if quote.ExpiresAt == "" {
@@ -89,7 +90,7 @@ func TestConversionCreateQuoteWithSettlementDate(t *testing.T) {
 	req := &banking.CreateQuoteRequest{
 		CurrencyFrom:   "USD",
 		CurrencyTo:     "EUR",
-		AmountFrom:     "100.00",
+		AmountFrom:     money.MustParse("100.00"),
 		SettlementDate: settlementDate,
 	}
 
@@ -122,7 +123,7 @@ func TestConversionCreate(t *testing.T) {
 	req := &banking.CreateConversionRequest{
 		CurrencyFrom: "USD",
 		CurrencyTo:   "EUR",
-		AmountFrom:   "100.00",
+		AmountFrom:   money.MustParse("100.00"),
 	}
 
 	t.Logf("Creating conversion: %s -> %s, Amount: %s", req.CurrencyFrom, req.CurrencyTo, req.AmountFrom)
@@ -161,16 +162,16 @@ func TestConversionCreate(t *testing.T) {
 		if conversion.CurrencyTo != req.CurrencyTo {
 			t.Errorf("Expected currency_to %s, got %s", req.CurrencyTo, conversion.CurrencyTo)
 		}
-		if conversion.AmountFrom != req.AmountFrom {
+		if !conversion.AmountFrom.Equal(req.AmountFrom) {
 			t.Errorf("Expected amount_from %s, got %s", req.AmountFrom, conversion.AmountFrom)
 		}
 		if conversion.ConversionStatus == "" {
 			t.Error("Expected conversion_status to be set")
 		}
-		if conversion.Rate == "" {
+		if conversion.Rate.IsZero() {
 			t.Error("Expected rate to be set")
 		}
-		if conversion.AmountTo == "" {
+		if conversion.AmountTo.IsZero() {
 			t.Error("Expected amount_to to be set")
 		}
 		if conversion.CreateTime == "" {
@@ -206,7 +207,7 @@ func TestConversionCreateWithQuote(t *testing.T) {
 	quoteReq := &banking.CreateQuoteRequest{
 		CurrencyFrom: "USD",
 		CurrencyTo:   "EUR",
-		AmountFrom:   "100.00",
+		AmountFrom:   money.MustParse("100.00"),
 	}
 
 	t.Logf("Creating quote for conversion...")
@@ -222,7 +223,7 @@ func TestConversionCreateWithQuote(t *testing.T) {
 	convReq := &banking.CreateConversionRequest{
 		CurrencyFrom: "USD",
 		CurrencyTo:   "EUR",
-		AmountFrom:   "100.00",
+		AmountFrom:   money.MustParse("100.00"),
 		QuoteID:      quote.QuoteID,
 	}
 
@@ -458,13 +459,13 @@ func TestConversionFullFlow(t *testing.T) {
 
 	// Step 2: Create a quote
 	var quoteID string
-	var quoteRate string
+	var quoteRate money.Decimal
 
 	t.Run("CreateQuote", func(t *testing.T) {
 		req := &banking.CreateQuoteRequest{
 			CurrencyFrom: "USD",
 			CurrencyTo:   "EUR",
-			AmountFrom:   "100.00",
+			AmountFrom:   money.MustParse("100.00"),
 		}
 
 		quote, err := client.Banking.Conversions.CreateQuote(ctx, req)
@@ -488,7 +489,7 @@ func TestConversionFullFlow(t *testing.T) {
 		req := &banking.CreateConversionRequest{
 			CurrencyFrom: "USD",
 			CurrencyTo:   "EUR",
-			AmountFrom:   "100.00",
+			AmountFrom:   money.MustParse("100.00"),
 			QuoteID:      quoteID,
 		}
 
@@ -518,7 +519,7 @@ func TestConversionFullFlow(t *testing.T) {
 		t.Logf("  From: %s %s", conversion.AmountFrom, conversion.CurrencyFrom)
 		t.Logf("  To: %s %s", conversion.AmountTo, conversion.CurrencyTo)
 
-		if conversion.Rate != quoteRate {
+		if !conversion.Rate.Equal(quoteRate) {
 			t.Logf("  Note: Rate changed from quote (%s) to final (%s)", quoteRate, conversion.Rate)
 		}
 	})
@@ -575,7 +576,7 @@ func TestConversionErrorHandling(t *testing.T) {
 		req := &banking.CreateConversionRequest{
 			CurrencyFrom: "INVALID",
 			CurrencyTo:   "EUR",
-			AmountFrom:   "100.00",
+			AmountFrom:   money.MustParse("100.00"),
 		}
 
 		_, err := client.Banking.Conversions.Create(ctx, req)
@@ -590,7 +591,7 @@ func TestConversionErrorHandling(t *testing.T) {
 		req := &banking.CreateQuoteRequest{
 			CurrencyFrom: "USD",
 			CurrencyTo:   "EUR",
-			AmountFrom:   "invalid",
+			AmountFrom:   money.MustParse("-100.00"),
 		}
 
 		_, err := client.Banking.Conversions.CreateQuote(ctx, req)
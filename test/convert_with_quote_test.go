@@ -0,0 +1,194 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func TestConvertWithQuote(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "1000")
+
+	// Both clocks share an offset so tests can simulate a slow approver
+	var offset int64
+	now := func() time.Time { return time.Now().Add(time.Duration(atomic.LoadInt64(&offset))) }
+	server.Now = now
+	quoteReq := &banking.CreateQuoteRequest{CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100")}
+
+	t.Run("Completes", func(t *testing.T) {
+		var approved []*banking.CreateQuoteResponse
+		result, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{
+			MaxSlippage: money.MustParse("0.01"),
+			Approve: func(ctx context.Context, quote *banking.CreateQuoteResponse) error {
+				approved = append(approved, quote)
+				return nil
+			},
+			Wait: fastWait,
+			Now:  now,
+		})
+		if err != nil {
+			t.Fatalf("ConvertWithQuote failed: %v", err)
+		}
+		if result.Quotes != 1 || len(approved) != 1 {
+			t.Errorf("Expected one quote approved, got %d quotes and %d approvals", result.Quotes, len(approved))
+		}
+		if result.Conversion.ConversionStatus != banking.ConversionStatusCompleted || !result.Conversion.AmountTo.Equal(money.MustParse("92")) {
+			t.Errorf("Unexpected conversion: %+v", result.Conversion)
+		}
+		if result.ReferenceRate == nil || !result.ReferenceRate.Equal(money.MustParse("0.92")) {
+			t.Errorf("Expected reference rate 0.92, got %v", result.ReferenceRate)
+		}
+		if got := server.Balance("EUR"); !got.Equal(money.MustParse("92")) {
+			t.Errorf("Expected EUR balance 92, got %s", got)
+		}
+	})
+
+	t.Run("RequotesNearExpiry", func(t *testing.T) {
+		defer atomic.StoreInt64(&offset, 0)
+		approvals := 0
+		result, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{
+			Approve: func(ctx context.Context, quote *banking.CreateQuoteResponse) error {
+				approvals++
+				if approvals == 1 {
+					atomic.AddInt64(&offset, int64(25*time.Second)) // leaves 5s, under MinQuoteValidity
+				}
+				return nil
+			},
+			Wait: fastWait,
+			Now:  now,
+		})
+		if err != nil {
+			t.Fatalf("ConvertWithQuote failed: %v", err)
+		}
+		if result.Quotes != 2 || approvals != 2 {
+			t.Errorf("Expected a re-quote, got %d quotes and %d approvals", result.Quotes, approvals)
+		}
+	})
+
+	t.Run("RequotesWhenRejectedAsExpired", func(t *testing.T) {
+		defer atomic.StoreInt64(&offset, 0)
+		approvals := 0
+		result, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{
+			Approve: func(ctx context.Context, quote *banking.CreateQuoteResponse) error {
+				approvals++
+				if approvals == 1 {
+					atomic.AddInt64(&offset, int64(uqpaytest.QuoteLifetime+time.Second))
+				}
+				return nil
+			},
+			Wait: fastWait,
+			Now:  time.Now, // the local clock still thinks the first quote is valid
+		})
+		if err != nil {
+			t.Fatalf("ConvertWithQuote failed: %v", err)
+		}
+		if result.Quotes != 2 || result.Conversion.ConversionStatus != banking.ConversionStatusCompleted {
+			t.Errorf("Expected completion after a re-quote, got %d quotes, %+v", result.Quotes, result.Conversion)
+		}
+	})
+
+	t.Run("GivesUpAfterMaxQuotes", func(t *testing.T) {
+		result, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{
+			MinQuoteValidity: time.Minute, // longer than any quote lives
+			MaxQuotes:        2,
+			Now:              now,
+		})
+		if !errors.Is(err, banking.ErrQuoteExpired) {
+			t.Fatalf("Expected ErrQuoteExpired, got %v", err)
+		}
+		if result.Quotes != 2 || result.Conversion != nil {
+			t.Errorf("Expected 2 quotes and no conversion, got %+v", result)
+		}
+	})
+
+	t.Run("Slippage", func(t *testing.T) {
+		server.SetQuoteMarkup("0.02")
+		defer server.SetQuoteMarkup("0")
+
+		before := server.Balance("USD")
+		_, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{MaxSlippage: money.MustParse("0.01"), Now: now})
+		if !errors.Is(err, banking.ErrSlippageExceeded) {
+			t.Fatalf("Expected ErrSlippageExceeded, got %v", err)
+		}
+		if got := server.Balance("USD"); !got.Equal(before) {
+			t.Errorf("Expected no conversion, USD balance went from %s to %s", before, got)
+		}
+
+		result, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{MaxSlippage: money.MustParse("0.03"), Wait: fastWait, Now: now})
+		if err != nil {
+			t.Fatalf("ConvertWithQuote within slippage failed: %v", err)
+		}
+		if !result.Quote.Rate.Equal(money.MustParse("0.9016")) {
+			t.Errorf("Expected marked-up rate 0.9016, got %s", result.Quote.Rate)
+		}
+	})
+
+	t.Run("ApprovalRejected", func(t *testing.T) {
+		rejected := errors.New("treasury rejected the rate")
+		_, err := client.Banking.Conversions.ConvertWithQuote(ctx, quoteReq, &banking.ConvertOptions{
+			Approve: func(ctx context.Context, quote *banking.CreateQuoteResponse) error { return rejected },
+			Now:     now,
+		})
+		if !errors.Is(err, rejected) {
+			t.Errorf("Expected approval error, got %v", err)
+		}
+	})
+}
+
+func TestWaitForConversion(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "100")
+	server.SetAutoAdvance(false)
+
+	created, err := client.Banking.Conversions.Create(ctx, &banking.CreateConversionRequest{
+		CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("40"),
+	})
+	if err != nil {
+		t.Fatalf("Create conversion failed: %v", err)
+	}
+	if err := server.FailConversion(created.ConversionID); err != nil {
+		t.Fatalf("FailConversion failed: %v", err)
+	}
+
+	_, err = client.Banking.Conversions.WaitForConversion(ctx, created.ConversionID, fastWait)
+	var conversionErr *banking.ConversionError
+	if !errors.As(err, &conversionErr) || !errors.Is(err, banking.ErrConversionFailed) {
+		t.Fatalf("Expected ConversionError wrapping ErrConversionFailed, got %v", err)
+	}
+	if conversionErr.Conversion.ConversionStatus != banking.ConversionStatusFailed {
+		t.Errorf("Expected FAILED, got %s", conversionErr.Conversion.ConversionStatus)
+	}
+	if got := server.Balance("USD"); !got.Equal(money.MustParse("100")) {
+		t.Errorf("Expected refunded balance 100, got %s", got)
+	}
+}
+
+func TestWaitForConversionIntermediateStatus(t *testing.T) {
+	var polls int32
+	apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		status := "PROCESSING"
+		if atomic.AddInt32(&polls, 1) >= 3 {
+			status = banking.ConversionStatusCompleted
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"conversion_id": "conv-1", "conversion_status": status})
+	})
+	conversions := banking.NewClient(apiClient).Conversions
+
+	conversion, err := conversions.WaitForConversion(context.Background(), "conv-1", fastWait)
+	if err != nil {
+		t.Fatalf("Expected PROCESSING to be polled until COMPLETED, got %v", err)
+	}
+	if conversion.ConversionStatus != banking.ConversionStatusCompleted || atomic.LoadInt32(&polls) != 3 {
+		t.Errorf("Expected COMPLETED after 3 polls, got %s after %d", conversion.ConversionStatus, polls)
+	}
+}
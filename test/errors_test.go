@@ -0,0 +1,92 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+func TestAPIErrors(t *testing.T) {
+	apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req-123")
+		switch r.URL.Path {
+		case "/v1/payouts":
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"code":    "validation_error",
+				"message": "invalid request",
+				"details": []map[string]string{
+					{"field": "amount", "message": "must be positive"},
+				},
+				"errors": []string{"currency is not supported"},
+			})
+		case "/v1/transfer":
+			writeJSON(w, http.StatusBadRequest, map[string]string{"code": "insufficient_balance", "message": "not enough funds"})
+		case "/v1/conversion":
+			writeJSON(w, http.StatusConflict, map[string]string{"code": "duplicate_request", "message": "already processed"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<html>not found</html>"))
+		}
+	})
+	client := banking.NewClient(apiClient)
+	ctx := context.Background()
+
+	t.Run("FieldErrors", func(t *testing.T) {
+		_, err := client.Payouts.Create(ctx, &banking.CreatePayoutRequest{Currency: "USD", Amount: money.MustParse("-1"), IdempotencyKey: "key-1"})
+		var apiErr *common.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected *common.APIError, got %T: %v", err, err)
+		}
+		if apiErr.RequestID != "req-123" {
+			t.Errorf("Expected request ID req-123, got %q", apiErr.RequestID)
+		}
+		if apiErr.Method != http.MethodPost || apiErr.Path != "/v1/payouts" {
+			t.Errorf("Expected POST /v1/payouts, got %s %s", apiErr.Method, apiErr.Path)
+		}
+		if apiErr.IdempotencyKey != "key-1" {
+			t.Errorf("Expected idempotency key key-1, got %q", apiErr.IdempotencyKey)
+		}
+		fields := apiErr.FieldErrors()
+		if len(fields) != 2 || fields[0].Field != "amount" || fields[1].Message != "currency is not supported" {
+			t.Errorf("Unexpected field errors: %+v", fields)
+		}
+		if !errors.Is(err, common.ErrValidation) {
+			t.Error("Expected errors.Is(err, ErrValidation)")
+		}
+		if !strings.Contains(err.Error(), "req-123") {
+			t.Errorf("Expected error message to include request ID, got %q", err.Error())
+		}
+	})
+
+	t.Run("Sentinels", func(t *testing.T) {
+		_, err := client.Transfers.Create(ctx, &banking.CreateTransferRequest{Currency: "USD", Amount: money.MustParse("1")})
+		if !errors.Is(err, common.ErrInsufficientBalance) || errors.Is(err, common.ErrValidation) {
+			t.Errorf("Expected ErrInsufficientBalance only, got %v", err)
+		}
+
+		_, err = client.Conversions.Create(ctx, &banking.CreateConversionRequest{})
+		if !errors.Is(err, common.ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("UndecodableBody", func(t *testing.T) {
+		_, err := client.Deposits.Get(ctx, "missing")
+		var apiErr *common.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected *common.APIError, got %T: %v", err, err)
+		}
+		if string(apiErr.RawBody) != "<html>not found</html>" {
+			t.Errorf("Expected raw body to be kept, got %q", apiErr.RawBody)
+		}
+		if !errors.Is(err, common.ErrNotFound) {
+			t.Error("Expected errors.Is(err, ErrNotFound)")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	var received string
+	apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("x-idempotency-key")
+		if r.URL.Path == "/v1/transfer" {
+			writeJSON(w, http.StatusConflict, map[string]string{"code": "conflict", "message": "duplicate"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"payout_id": "p-1", "card_order_id": "o-1"})
+	})
+	bankingClient := banking.NewClient(apiClient)
+	issuingClient := issuing.NewClient(apiClient)
+	ctx := context.Background()
+
+	t.Run("RequestField", func(t *testing.T) {
+		resp, err := bankingClient.Payouts.Create(ctx, &banking.CreatePayoutRequest{
+			BeneficiaryID:  "b-1",
+			Currency:       "USD",
+			Amount:         money.MustParse("10.00"),
+			PayoutPurpose:  "salary",
+			IdempotencyKey: "payout-key-1",
+		})
+		if err != nil {
+			t.Fatalf("Create payout failed: %v", err)
+		}
+		if received != "payout-key-1" {
+			t.Errorf("Expected header payout-key-1, got %q", received)
+		}
+		if resp.IdempotencyKey != "payout-key-1" {
+			t.Errorf("Expected response key payout-key-1, got %q", resp.IdempotencyKey)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		keyCtx := common.WithIdempotencyKey(ctx, "recharge-key-1")
+		order, err := issuingClient.Cards.Recharge(keyCtx, "card-1", &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("5"))})
+		if err != nil {
+			t.Fatalf("Recharge failed: %v", err)
+		}
+		if received != "recharge-key-1" || order.IdempotencyKey != "recharge-key-1" {
+			t.Errorf("Expected recharge-key-1, got header %q and response %q", received, order.IdempotencyKey)
+		}
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		order, err := issuingClient.Cards.Withdraw(ctx, "card-1", &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("5"))})
+		if err != nil {
+			t.Fatalf("Withdraw failed: %v", err)
+		}
+		if order.IdempotencyKey == "" || order.IdempotencyKey != received {
+			t.Errorf("Expected generated key %q to be surfaced, got %q", received, order.IdempotencyKey)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := bankingClient.Transfers.Create(ctx, &banking.CreateTransferRequest{
+			Currency:       "USD",
+			Amount:         money.MustParse("1.00"),
+			IdempotencyKey: "transfer-key-1",
+		})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if key := common.IdempotencyKeyFromError(err); key != "transfer-key-1" {
+			t.Errorf("Expected error to carry transfer-key-1, got %q", key)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+)
+
+func TestIterator(t *testing.T) {
+	const totalItems = 25
+	var requestedSizes []int
+	apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		pageNumber, _ := strconv.Atoi(r.URL.Query().Get("page_number"))
+		requestedSizes = append(requestedSizes, pageSize)
+
+		totalPages := (totalItems + pageSize - 1) / pageSize
+		var data []map[string]string
+		for i := (pageNumber - 1) * pageSize; i < pageNumber*pageSize && i < totalItems; i++ {
+			data = append(data, map[string]string{"payout_id": fmt.Sprintf("p-%d", i), "card_id": fmt.Sprintf("c-%d", i)})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"total_pages": totalPages,
+			"total_items": totalItems,
+			"data":        data,
+		})
+	})
+	payouts := banking.NewClient(apiClient).Payouts
+	cards := issuing.NewClient(apiClient).Cards
+	ctx := context.Background()
+
+	t.Run("ListAll", func(t *testing.T) {
+		all, err := payouts.ListAll(ctx, &banking.ListPayoutsRequest{PageSize: 10, Currency: "USD"})
+		if err != nil {
+			t.Fatalf("ListAll failed: %v", err)
+		}
+		if len(all) != totalItems {
+			t.Fatalf("Expected %d payouts, got %d", totalItems, len(all))
+		}
+		if all[0].PayoutID != "p-0" || all[totalItems-1].PayoutID != "p-24" {
+			t.Errorf("Unexpected order: first %s, last %s", all[0].PayoutID, all[totalItems-1].PayoutID)
+		}
+	})
+
+	t.Run("PageSizeBounds", func(t *testing.T) {
+		requestedSizes = nil
+		if _, err := cards.Iter(&issuing.ListCardsRequest{PageSize: 3}).Collect(ctx, 1); err != nil {
+			t.Fatalf("Collect failed: %v", err)
+		}
+		if len(requestedSizes) != 1 || requestedSizes[0] != 10 {
+			t.Errorf("Expected a single request with page size 10, got %v", requestedSizes)
+		}
+
+		requestedSizes = nil
+		if _, err := cards.ListAll(ctx, nil); err != nil {
+			t.Fatalf("ListAll failed: %v", err)
+		}
+		if len(requestedSizes) != 1 || requestedSizes[0] != 100 {
+			t.Errorf("Expected a single request with page size 100, got %v", requestedSizes)
+		}
+	})
+
+	t.Run("ForEach", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := payouts.Iter(&banking.ListPayoutsRequest{PageSize: 10}).ForEach(ctx, func(p banking.Payout) error {
+			count++
+			if count == 12 {
+				return stop
+			}
+			return nil
+		})
+		if !errors.Is(err, stop) || count != 12 {
+			t.Errorf("Expected to stop after 12 items, got %d (err=%v)", count, err)
+		}
+	})
+
+	t.Run("ContextCancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		it := payouts.Iter(&banking.ListPayoutsRequest{PageSize: 10})
+		if !it.Next(cancelled) {
+			t.Fatalf("Expected first item, got error %v", it.Err())
+		}
+		cancel()
+		if it.Next(cancelled) {
+			t.Error("Expected iteration to stop after cancel")
+		}
+		if !errors.Is(it.Err(), context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", it.Err())
+		}
+	})
+}
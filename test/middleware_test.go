@@ -0,0 +1,93 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+)
+
+func TestMiddleware(t *testing.T) {
+	server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-trace-id") != "trace-1" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"code": "missing_trace", "message": "no trace header"})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/issuing/cards/card-1":
+			writeJSON(w, http.StatusOK, map[string]string{"card_id": "card-1"})
+		case "/v1/files/download_links":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"files": []interface{}{}})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"code": "not_found", "message": "not found"})
+		}
+	})
+
+	var order []string
+	var operations []string
+	var results []interface{}
+	var apiErrors []*common.APIError
+
+	inject := func(next common.RoundTripFunc) common.RoundTripFunc {
+		return func(req *http.Request) (*common.Response, error) {
+			order = append(order, "inject")
+			req.Header.Set("x-trace-id", "trace-1")
+			return next(req)
+		}
+	}
+	record := func(next common.RoundTripFunc) common.RoundTripFunc {
+		return func(req *http.Request) (*common.Response, error) {
+			order = append(order, "record")
+			resp, err := next(req)
+			operations = append(operations, common.OperationFromContext(req.Context()))
+			if resp != nil {
+				results = append(results, resp.Result)
+			}
+			var apiErr *common.APIError
+			if errors.As(err, &apiErr) {
+				apiErrors = append(apiErrors, apiErr)
+			}
+			return resp, err
+		}
+	}
+
+	env := &configuration.Environment{BaseURL: server.URL, FilesBaseURL: server.URL}
+	client, err := uqpay.NewClient("mock-client-id", "mock-api-key", env, uqpay.WithMiddleware(inject, record))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	card, err := client.Issuing.Cards.Get(ctx, "card-1")
+	if err != nil {
+		t.Fatalf("Get card failed: %v", err)
+	}
+	if _, err := client.Supporting.Files.GetDownloadLinks(ctx, &supporting.DownloadLinksRequest{FileIDs: []string{"f-1"}}); err != nil {
+		t.Fatalf("GetDownloadLinks failed: %v", err)
+	}
+	if _, err := client.Issuing.Cards.Get(ctx, "missing"); err == nil {
+		t.Fatal("Expected an error for a missing card")
+	}
+
+	if len(order) != 6 || order[0] != "inject" || order[1] != "record" {
+		t.Errorf("Expected middleware to run in order for every call, got %v", order)
+	}
+	expected := []string{"issuing.cards.get", "supporting.files.get_download_links", "issuing.cards.get"}
+	for i, op := range expected {
+		if i >= len(operations) || operations[i] != op {
+			t.Fatalf("Expected operations %v, got %v", expected, operations)
+		}
+	}
+	if got, ok := results[0].(*issuing.RetrieveCardResponse); !ok || got != card {
+		t.Errorf("Expected decoded card as result, got %T", results[0])
+	}
+	if len(apiErrors) != 1 || apiErrors[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected one 404 APIError, got %v", apiErrors)
+	}
+}
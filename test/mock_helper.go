@@ -0,0 +1,55 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/auth"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+)
+
+// NewMockServer starts an httptest server that issues auth tokens and passes
+// every other request to handler
+func NewMockServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(auth.TokenResponse{
+			AuthToken: "mock-token",
+			ExpiredAt: time.Now().Add(time.Hour).Unix(),
+		})
+	})
+	mux.HandleFunc("/", handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// NewMockAPIClient creates an API client pointed at a mock server
+func NewMockAPIClient(t *testing.T, handler http.HandlerFunc) *common.APIClient {
+	t.Helper()
+
+	server := NewMockServer(t, handler)
+	config := &configuration.Configuration{
+		ClientID:    "mock-client-id",
+		APIKey:      "mock-api-key",
+		Environment: &configuration.Environment{BaseURL: server.URL, FilesBaseURL: server.URL},
+		HTTPClient:  server.Client(),
+	}
+	tokenProvider := auth.NewTokenProvider(server.URL, config.ClientID, config.APIKey, config.HTTPClient)
+	return common.NewAPIClient(config, tokenProvider)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
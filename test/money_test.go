@@ -0,0 +1,106 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("Arithmetic", func(t *testing.T) {
+		a := money.MustParse("0.1")
+		b := money.MustParse("0.2")
+		if got := a.Add(b).String(); got != "0.3" {
+			t.Errorf("Expected 0.1 + 0.2 = 0.3, got %s", got)
+		}
+		if got := money.MustParse("100.50").Sub(money.MustParse("0.505")).String(); got != "99.995" {
+			t.Errorf("Expected 99.995, got %s", got)
+		}
+		if got := money.MustParse("1.5").Mul(money.MustParse("-2.25")).String(); got != "-3.375" {
+			t.Errorf("Expected -3.375, got %s", got)
+		}
+		q, err := money.MustParse("10").Div(money.MustParse("3"), 4)
+		if err != nil || q.String() != "3.3333" {
+			t.Errorf("Expected 3.3333, got %s (err=%v)", q, err)
+		}
+		if _, err := money.NewFromInt(1).Div(money.Zero, 2); err != money.ErrDivisionByZero {
+			t.Errorf("Expected ErrDivisionByZero, got %v", err)
+		}
+		if !money.MustParse("1.0").Equal(money.MustParse("1.00")) {
+			t.Error("Expected 1.0 to equal 1.00")
+		}
+		if got := money.MustParse("-2.345").Round(2).String(); got != "-2.35" {
+			t.Errorf("Expected -2.35, got %s", got)
+		}
+		if got := money.MustParse("1.5e2").String(); got != "150" {
+			t.Errorf("Expected 150, got %s", got)
+		}
+		if _, err := money.Parse("12.3.4"); err == nil {
+			t.Error("Expected parse error for 12.3.4")
+		}
+		for _, s := range []string{"1e200000000", "1e-200000000", "1e1025"} {
+			if _, err := money.Parse(s); err == nil {
+				t.Errorf("Expected out of range error for %s", s)
+			}
+		}
+		var d money.Decimal
+		if err := json.Unmarshal([]byte(`"1e200000000"`), &d); err == nil {
+			t.Error("Expected JSON decode error for a huge exponent")
+		}
+	})
+
+	t.Run("CurrencyPrecision", func(t *testing.T) {
+		cases := []struct {
+			currency string
+			amount   string
+			expected string
+			minor    int64
+		}{
+			{"JPY", "1234.5", "1235 JPY", 1235},
+			{"USD", "10.005", "10.01 USD", 1001},
+			{"KWD", "1.2345", "1.235 KWD", 1235},
+		}
+		for _, c := range cases {
+			m, err := money.ParseMoney(c.amount, c.currency)
+			if err != nil {
+				t.Fatalf("ParseMoney failed: %v", err)
+			}
+			if m.String() != c.expected {
+				t.Errorf("Expected %s, got %s", c.expected, m)
+			}
+			minor, err := m.MinorUnits()
+			if err != nil || minor != c.minor {
+				t.Errorf("Expected %d minor units for %s, got %d (err=%v)", c.minor, c.currency, minor, err)
+			}
+		}
+		if got := money.FromMinorUnits(123456789, "USD").Format(); got != "1,234,567.89" {
+			t.Errorf("Expected 1,234,567.89, got %s", got)
+		}
+		if _, err := money.New(money.NewFromInt(1), "USD").Add(money.New(money.NewFromInt(1), "EUR")); err == nil {
+			t.Error("Expected currency mismatch error")
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var balance banking.Balance
+		if err := json.Unmarshal([]byte(`{"available_balance":"1000.25","frozen_balance":12.5,"margin_balance":null,"prepaid_balance":""}`), &balance); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if balance.AvailableBalance.String() != "1000.25" || balance.FrozenBalance.String() != "12.5" || !balance.MarginBalance.IsZero() {
+			t.Errorf("Unexpected balance: %+v", balance)
+		}
+
+		data, _ := json.Marshal(&banking.CreateTransferRequest{Amount: money.MustParse("10.00")})
+		if !strings.Contains(string(data), `"amount":"10.00"`) {
+			t.Errorf("Expected amount as string, got %s", data)
+		}
+		data, _ = json.Marshal(&issuing.CardOrderRequest{Amount: money.Number(money.MustParse("10.50"))})
+		if string(data) != `{"amount":10.50}` {
+			t.Errorf("Expected amount as number, got %s", data)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+)
+
+type staticTokenProvider string
+
+func (p staticTokenProvider) GetToken(ctx context.Context) (string, error) {
+	return string(p), nil
+}
+
+func TestClientOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Validation", func(t *testing.T) {
+		if _, err := uqpay.NewClient("", "key", configuration.Sandbox()); !errors.Is(err, uqpay.ErrMissingCredentials) {
+			t.Errorf("Expected ErrMissingCredentials, got %v", err)
+		}
+		if _, err := uqpay.NewClient("id", "", configuration.Sandbox()); !errors.Is(err, uqpay.ErrMissingCredentials) {
+			t.Errorf("Expected ErrMissingCredentials, got %v", err)
+		}
+		if _, err := uqpay.NewClient("id", "key", nil); !errors.Is(err, uqpay.ErrMissingEnvironment) {
+			t.Errorf("Expected ErrMissingEnvironment, got %v", err)
+		}
+		if _, err := uqpay.NewClient("", "", configuration.Sandbox(), uqpay.WithTokenProvider(staticTokenProvider("t"))); err != nil {
+			t.Errorf("Expected no error with a token provider, got %v", err)
+		}
+	})
+
+	t.Run("AppliedToEverySubClient", func(t *testing.T) {
+		var userAgents []string
+		var tokens []string
+		server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+			userAgents = append(userAgents, r.Header.Get("User-Agent"))
+			tokens = append(tokens, r.Header.Get("x-auth-token"))
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/files"):
+				writeJSON(w, http.StatusOK, supporting.DownloadLinksResponse{})
+			default:
+				writeJSON(w, http.StatusOK, map[string]string{"card_id": "card-1"})
+			}
+		})
+
+		var logs bytes.Buffer
+		client, err := uqpay.NewClient("", "", configuration.Sandbox(),
+			uqpay.WithBaseURL(server.URL),
+			uqpay.WithFilesBaseURL(server.URL+"/files"),
+			uqpay.WithHTTPClient(server.Client()),
+			uqpay.WithUserAgent("my-app/2.0"),
+			uqpay.WithTokenProvider(staticTokenProvider("custom-token")),
+			uqpay.WithLogger(log.New(&logs, "", 0)),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		if _, err := client.Issuing.Cards.Get(ctx, "card-1"); err != nil {
+			t.Fatalf("Get card failed: %v", err)
+		}
+		if _, err := client.Supporting.Files.GetDownloadLinks(ctx, &supporting.DownloadLinksRequest{FileIDs: []string{"f-1"}}); err != nil {
+			t.Fatalf("GetDownloadLinks failed: %v", err)
+		}
+
+		for i := range userAgents {
+			if userAgents[i] != "my-app/2.0" || tokens[i] != "custom-token" {
+				t.Errorf("Request %d: expected custom user agent and token, got %q and %q", i, userAgents[i], tokens[i])
+			}
+		}
+		if !strings.Contains(logs.String(), "issuing.cards.get GET /v1/issuing/cards/card-1 -> 200") {
+			t.Errorf("Expected request to be logged, got %q", logs.String())
+		}
+		if !strings.Contains(logs.String(), "supporting.files.get_download_links POST /files/") {
+			t.Errorf("Expected Files request to be logged, got %q", logs.String())
+		}
+		if strings.Contains(logs.String(), "custom-token") {
+			t.Error("Expected the token never to be logged")
+		}
+	})
+
+	t.Run("DefaultUserAgent", func(t *testing.T) {
+		var userAgent string
+		server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+			userAgent = r.Header.Get("User-Agent")
+			writeJSON(w, http.StatusOK, map[string]string{})
+		})
+		env := &configuration.Environment{BaseURL: server.URL, FilesBaseURL: server.URL}
+		client, err := uqpay.NewClient("id", "key", env, uqpay.WithHTTPClient(server.Client()))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		client.Issuing.Cards.Get(ctx, "card-1")
+		if userAgent != uqpay.DefaultUserAgent {
+			t.Errorf("Expected %q, got %q", uqpay.DefaultUserAgent, userAgent)
+		}
+	})
+
+	t.Run("RetryPolicyAndTimeout", func(t *testing.T) {
+		// The token is served without delay by NewMockServer; only card attempts are slow and counted
+		var calls int32
+		server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/issuing/cards/card-1" {
+				writeJSON(w, http.StatusOK, map[string]string{})
+				return
+			}
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(100 * time.Millisecond)
+			writeJSON(w, http.StatusOK, map[string]string{})
+		})
+		env := &configuration.Environment{BaseURL: server.URL, FilesBaseURL: server.URL}
+		client, err := uqpay.NewClient("id", "key", env,
+			uqpay.WithHTTPClient(server.Client()),
+			uqpay.WithTimeout(20*time.Millisecond),
+			uqpay.WithRetryPolicy(common.NoRetry()),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		if _, err := client.Issuing.Cards.Get(ctx, "card-1"); err == nil {
+			t.Error("Expected a timeout error")
+		}
+		if server.Client().Timeout != 0 {
+			t.Error("Expected WithTimeout not to modify the caller's HTTP client")
+		}
+		// Close waits for the timed out handler to return before the counter is read
+		server.Close()
+		if got := atomic.LoadInt32(&calls); got != 1 {
+			t.Errorf("Expected exactly one card attempt, got %d", got)
+		}
+	})
+}
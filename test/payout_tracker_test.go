@@ -0,0 +1,201 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func createFakePayout(t *testing.T, client *uqpay.Client, beneficiaryID, amount string) string {
+	t.Helper()
+	resp, err := client.Banking.Payouts.Create(context.Background(), &banking.CreatePayoutRequest{
+		BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse(amount), PayoutPurpose: "salary",
+	})
+	if err != nil {
+		t.Fatalf("Create payout failed: %v", err)
+	}
+	return resp.PayoutID
+}
+
+func TestWaitForPayout(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "1000")
+	beneficiaryID := createFakeBeneficiary(t, client)
+
+	t.Run("Completed", func(t *testing.T) {
+		id := createFakePayout(t, client, beneficiaryID, "10")
+		payout, err := client.Banking.Payouts.WaitForPayout(ctx, id, fastWait)
+		if err != nil {
+			t.Fatalf("WaitForPayout failed: %v", err)
+		}
+		if payout.PayoutStatus != banking.PayoutStatusCompleted {
+			t.Errorf("Expected COMPLETED, got %s", payout.PayoutStatus)
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+
+		id := createFakePayout(t, client, beneficiaryID, "10")
+		if err := server.FailPayout(id, "invalid account"); err != nil {
+			t.Fatalf("FailPayout failed: %v", err)
+		}
+		_, err := client.Banking.Payouts.WaitForPayout(ctx, id, fastWait)
+		var payoutErr *banking.PayoutError
+		if !errors.As(err, &payoutErr) || !errors.Is(err, banking.ErrPayoutFailed) {
+			t.Fatalf("Expected PayoutError wrapping ErrPayoutFailed, got %v", err)
+		}
+		if payoutErr.Payout.FailureReason != "invalid account" {
+			t.Errorf("Expected failure reason, got %q", payoutErr.Payout.FailureReason)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+
+		id := createFakePayout(t, client, beneficiaryID, "10")
+		_, err := client.Banking.Payouts.WaitForPayout(ctx, id, &common.WaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond})
+		if !errors.Is(err, common.ErrWaitTimeout) {
+			t.Errorf("Expected ErrWaitTimeout, got %v", err)
+		}
+	})
+}
+
+func TestPayoutTracker(t *testing.T) {
+	var inFlight, maxInFlight int32
+	limiter := func(next common.RoundTripFunc) common.RoundTripFunc {
+		return func(req *http.Request) (*common.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			return next(req)
+		}
+	}
+	client, server := uqpaytest.NewClient(t, uqpay.WithMiddleware(limiter))
+	ctx := context.Background()
+	server.SetBalance("USD", "1000")
+	beneficiaryID := createFakeBeneficiary(t, client)
+
+	var mu sync.Mutex
+	var changes []banking.PayoutStatusChange
+	opts := banking.PayoutTrackerOptions{
+		Concurrency: 2,
+		Interval:    time.Millisecond,
+		OnChange: func(change banking.PayoutStatusChange) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, change)
+		},
+	}
+	changesFor := func(id string) []banking.PayoutStatusChange {
+		mu.Lock()
+		defer mu.Unlock()
+		var out []banking.PayoutStatusChange
+		for _, c := range changes {
+			if c.PayoutID == id {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	t.Run("TracksUntilTerminal", func(t *testing.T) {
+		var ids []string
+		for i := 0; i < 5; i++ {
+			ids = append(ids, createFakePayout(t, client, beneficiaryID, "5"))
+		}
+		server.SetAutoAdvance(false)
+		failed := createFakePayout(t, client, beneficiaryID, "5")
+		if err := server.FailPayout(failed, "beneficiary bank rejected"); err != nil {
+			t.Fatalf("FailPayout failed: %v", err)
+		}
+		server.SetAutoAdvance(true)
+		atomic.StoreInt32(&maxInFlight, 0)
+
+		tracker := banking.NewPayoutTracker(client.Banking.Payouts, opts)
+		tracker.Track(append(ids, failed)...)
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := tracker.Wait(waitCtx); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+
+		got := changesFor(ids[0])
+		if len(got) != 2 || got[0].Previous != "" || got[0].Payout.PayoutStatus != banking.PayoutStatusProcessing ||
+			got[1].Previous != banking.PayoutStatusProcessing || got[1].Payout.PayoutStatus != banking.PayoutStatusCompleted {
+			t.Errorf("Unexpected changes for %s: %+v", ids[0], got)
+		}
+		got = changesFor(failed)
+		if len(got) != 1 || got[0].Payout.PayoutStatus != banking.PayoutStatusFailed || got[0].Payout.FailureReason != "beneficiary bank rejected" {
+			t.Errorf("Unexpected changes for failed payout: %+v", got)
+		}
+		if max := atomic.LoadInt32(&maxInFlight); max > 2 {
+			t.Errorf("Expected at most 2 concurrent requests, got %d", max)
+		}
+		if len(tracker.Pending()) != 0 {
+			t.Errorf("Expected no pending payouts, got %v", tracker.Pending())
+		}
+	})
+
+	t.Run("ResumeFromSnapshot", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+		id := createFakePayout(t, client, beneficiaryID, "5")
+
+		first := banking.NewPayoutTracker(client.Banking.Payouts, opts)
+		first.Track(id)
+		first.PollOnce(ctx)
+		snapshot := first.Snapshot()
+		if snapshot[id] != banking.PayoutStatusPending {
+			t.Fatalf("Expected snapshot PENDING, got %v", snapshot)
+		}
+
+		if err := server.CompletePayout(id); err != nil {
+			t.Fatalf("CompletePayout failed: %v", err)
+		}
+		resumed := banking.NewPayoutTracker(client.Banking.Payouts, opts)
+		resumed.Resume(snapshot)
+		if err := resumed.Wait(ctx); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+
+		got := changesFor(id)
+		if len(got) != 2 || got[1].Previous != banking.PayoutStatusPending || got[1].Payout.PayoutStatus != banking.PayoutStatusCompleted {
+			t.Errorf("Expected PENDING -> COMPLETED after resume, got %+v", got)
+		}
+	})
+
+	t.Run("UnknownPayout", func(t *testing.T) {
+		var reported string
+		tracker := banking.NewPayoutTracker(client.Banking.Payouts, banking.PayoutTrackerOptions{
+			Interval: time.Millisecond,
+			OnError:  func(id string, err error) { reported = id },
+		})
+		tracker.Track("payout-missing")
+		if err := tracker.Wait(ctx); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		if reported != "payout-missing" {
+			t.Errorf("Expected OnError for payout-missing, got %q", reported)
+		}
+	})
+}
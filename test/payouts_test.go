@@ -126,7 +126,7 @@ func TestPayouts(t *testing.T) {
 			req := &banking.CreatePayoutRequest{
 				BeneficiaryID: beneficiaryID,
 				Currency:      "USD",
-				Amount:        "10.00",
+				Amount:        money.MustParse("10.00"),
 				PayoutPurpose: "test_payment",
 				Description:   "Test payout via SDK",
 				Reference:     "TEST-REF-001",
@@ -152,7 +152,7 @@ func TestPayouts(t *testing.T) {
 		/*
 			req := &banking.CreatePayoutRequest{
 				Currency:      "USD",
-				Amount:        "10.00",
+				Amount:        money.MustParse("10.00"),
 				PayoutPurpose: "vendor_payment",
 				Description:   "Test payout with inline beneficiary",
 				Beneficiary: &banking.PayoutBeneficiary{
@@ -239,7 +239,7 @@ func TestPayouts(t *testing.T) {
 
 		if resp.TransactionDetails != nil {
 			t.Logf("   Transaction ID: %s", resp.TransactionDetails.TransactionID)
-			if resp.TransactionDetails.ExchangeRate != "" {
+			if resp.TransactionDetails.ExchangeRate != nil {
 				t.Logf("   Exchange Rate: %s", resp.TransactionDetails.ExchangeRate)
 			}
 		}
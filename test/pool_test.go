@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+)
+
+func TestPool(t *testing.T) {
+	server := NewMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/issuing/cards/missing" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "card not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"card_id": "card-1"})
+	})
+	env := &configuration.Environment{Name: "mock", BaseURL: server.URL, FilesBaseURL: server.URL}
+	ctx := context.Background()
+
+	t.Run("CachesPerCredentials", func(t *testing.T) {
+		pool := uqpay.NewPool(0)
+		a1, err := pool.Get("merchant-a", "key-a", env)
+		if err != nil {
+			t.Fatalf("Failed to get client: %v", err)
+		}
+		a2, _ := pool.Get("merchant-a", "key-a", env)
+		b, _ := pool.Get("merchant-b", "key-b", env)
+
+		if a1 != a2 {
+			t.Error("Expected the same client for the same credentials")
+		}
+		if a1 == b {
+			t.Error("Expected different clients for different tenants")
+		}
+		if pool.Len() != 2 {
+			t.Errorf("Expected 2 tenants, got %d", pool.Len())
+		}
+	})
+
+	t.Run("RotatesCredentials", func(t *testing.T) {
+		pool := uqpay.NewPool(0)
+		old, _ := pool.Get("merchant-a", "key-1", env)
+		rotated, _ := pool.Get("merchant-a", "key-2", env)
+
+		if old == rotated {
+			t.Error("Expected a new client after key rotation")
+		}
+		if pool.Len() != 1 {
+			t.Errorf("Expected the rotated client to replace the old one, got %d tenants", pool.Len())
+		}
+		if _, err := old.Issuing.Cards.Get(ctx, "card-1"); err != nil {
+			t.Errorf("Expected the old client to keep working, got %v", err)
+		}
+	})
+
+	t.Run("EvictsIdleTenants", func(t *testing.T) {
+		pool := uqpay.NewPool(20 * time.Millisecond)
+		pool.Get("merchant-a", "key-a", env)
+		time.Sleep(40 * time.Millisecond)
+		pool.Get("merchant-b", "key-b", env)
+
+		if pool.Len() != 1 {
+			t.Errorf("Expected the idle tenant to be evicted, got %d tenants", pool.Len())
+		}
+		time.Sleep(40 * time.Millisecond)
+		if n := pool.EvictIdle(); n != 1 || pool.Len() != 0 {
+			t.Errorf("Expected EvictIdle to remove 1 tenant, removed %d leaving %d", n, pool.Len())
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		pool := uqpay.NewPool(0)
+		client, _ := pool.Get("merchant-a", "key-a", env)
+		client.Issuing.Cards.Get(ctx, "card-1")
+		client.Issuing.Cards.Get(ctx, "missing")
+		client.WithAccount("acct-1").Issuing.Cards.Get(ctx, "card-1")
+
+		stats := pool.Stats()
+		if len(stats) != 1 {
+			t.Fatalf("Expected stats for 1 tenant, got %d", len(stats))
+		}
+		s := stats[0]
+		if s.ClientID != "merchant-a" || s.Environment != "mock" {
+			t.Errorf("Unexpected tenant: %+v", s)
+		}
+		if s.Requests != 3 || s.Errors != 1 {
+			t.Errorf("Expected 3 requests and 1 error, got %d and %d", s.Requests, s.Errors)
+		}
+		if s.LastUsed.Before(s.Created) {
+			t.Errorf("Expected LastUsed after Created, got %v < %v", s.LastUsed, s.Created)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		pool := uqpay.NewPool(0)
+		if _, err := pool.Get("", "key", env); err == nil {
+			t.Error("Expected an error for empty credentials")
+		}
+		if _, err := pool.Get("id", "key", nil); err == nil {
+			t.Error("Expected an error for a nil environment")
+		}
+		if pool.Len() != 0 {
+			t.Errorf("Expected no tenants after failed Gets, got %d", pool.Len())
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+)
+
+func TestQueryEncoding(t *testing.T) {
+	t.Run("QueryValues", func(t *testing.T) {
+		empty := ""
+		type filter struct {
+			PageSize   int       `url:"page_size"`
+			PageNumber int       `url:"page_number"`
+			StartTime  time.Time `url:"start_time"`
+			EndTime    time.Time `url:"end_time"`
+			Status     *string   `url:"status"`
+			Currencies []string  `url:"currency"`
+			Pairs      []string  `url:"pairs,comma"`
+			Skipped    string    `url:"-"`
+			Untagged   string
+		}
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("SGT", 8*3600))
+
+		values, err := common.QueryValues(&filter{
+			PageSize:   10,
+			StartTime:  start,
+			Status:     &empty,
+			Currencies: []string{"USD", "EUR"},
+			Pairs:      []string{"USD/EUR", "GBP/USD"},
+			Skipped:    "x",
+			Untagged:   "y",
+		})
+		if err != nil {
+			t.Fatalf("QueryValues failed: %v", err)
+		}
+
+		expected := "currency=USD&currency=EUR&page_size=10&pairs=USD%2FEUR%2CGBP%2FUSD&start_time=2024-01-01T00%3A00%3A00%2B08%3A00&status="
+		if got := values.Encode(); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("SpecialCharacters", func(t *testing.T) {
+		var received map[string][]string
+		apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			received = r.URL.Query()
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": []interface{}{}})
+		})
+		ctx := context.Background()
+
+		cardNumber := "4111 & 1111#+"
+		if _, err := issuing.NewClient(apiClient).Cards.List(ctx, &issuing.ListCardsRequest{
+			PageSize:   10,
+			PageNumber: 1,
+			CardNumber: &cardNumber,
+		}); err != nil {
+			t.Fatalf("List cards failed: %v", err)
+		}
+		if got := received["card_number"]; len(got) != 1 || got[0] != cardNumber {
+			t.Errorf("Expected card_number %q, got %v", cardNumber, got)
+		}
+		if _, ok := received["card_status"]; ok {
+			t.Error("Expected unset card_status to be omitted")
+		}
+
+		startTime := "2024-01-01T00:00:00+08:00"
+		if _, err := banking.NewClient(apiClient).Payouts.List(ctx, &banking.ListPayoutsRequest{
+			PageSize:   10,
+			PageNumber: 1,
+			StartTime:  startTime,
+		}); err != nil {
+			t.Fatalf("List payouts failed: %v", err)
+		}
+		if got := received["start_time"]; len(got) != 1 || got[0] != startTime {
+			t.Errorf("Expected start_time %q, got %v", startTime, got)
+		}
+	})
+}
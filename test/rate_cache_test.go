@@ -0,0 +1,158 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func TestRateCache(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+
+	t.Run("DirectInverseAndCross", func(t *testing.T) {
+		cache := banking.NewRateCache(client.Banking.ExchangeRates, banking.RateCacheOptions{})
+		if !cache.Stale() {
+			t.Error("Expected an empty cache to be stale")
+		}
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+
+		tests := []struct {
+			base, quote, sell, source, via string
+		}{
+			{"USD", "EUR", "0.92", banking.RateSourceDirect, ""},
+			{"eur", "usd", "1.08695652", banking.RateSourceInverse, ""},
+			{"SGD", "EUR", "0.68148148", banking.RateSourceCross, "USD"},
+			{"GBP", "EUR", "1.16279070", banking.RateSourceInverse, ""},
+		}
+		for _, tt := range tests {
+			rate, err := cache.Rate(tt.base, tt.quote)
+			if err != nil {
+				t.Errorf("Rate(%s, %s) failed: %v", tt.base, tt.quote, err)
+				continue
+			}
+			if !rate.Sell.Equal(money.MustParse(tt.sell)) || rate.Source != tt.source || rate.Via != tt.via {
+				t.Errorf("Rate(%s, %s): expected %s %s via %q, got %s %s via %q", tt.base, tt.quote, tt.sell, tt.source, tt.via, rate.Sell, rate.Source, rate.Via)
+			}
+			if rate.Stale {
+				t.Errorf("Rate(%s, %s) is unexpectedly stale", tt.base, tt.quote)
+			}
+		}
+
+		if _, err := cache.Rate("USD", "JPY"); !errors.Is(err, banking.ErrRateUnavailable) {
+			t.Errorf("Expected ErrRateUnavailable, got %v", err)
+		}
+		if len(cache.Rates()) != 4 {
+			t.Errorf("Expected 4 published rates, got %d", len(cache.Rates()))
+		}
+	})
+
+	t.Run("ConfiguredPairs", func(t *testing.T) {
+		cache := banking.NewRateCache(client.Banking.ExchangeRates, banking.RateCacheOptions{Pairs: []string{"SGD/EUR"}})
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		unavailable := cache.Unavailable()
+		if len(unavailable) != 1 || unavailable[0] != "SGD/EUR" {
+			t.Errorf("Expected SGD/EUR unavailable, got %v", unavailable)
+		}
+		rate, err := cache.Rate("SGD", "EUR")
+		if err != nil || rate.Source != banking.RateSourceCross {
+			t.Errorf("Expected cross rate for SGD/EUR, got %+v, %v", rate, err)
+		}
+	})
+
+	t.Run("Staleness", func(t *testing.T) {
+		now := time.Now()
+		cache := banking.NewRateCache(client.Banking.ExchangeRates, banking.RateCacheOptions{
+			MaxAge: time.Minute,
+			Now:    func() time.Time { return now },
+		})
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		if cache.Stale() || cache.LastUpdated().IsZero() {
+			t.Errorf("Expected fresh rates, last updated %v", cache.LastUpdated())
+		}
+		now = now.Add(2 * time.Minute)
+		rate, err := cache.Rate("USD", "EUR")
+		if err != nil {
+			t.Fatalf("Rate failed: %v", err)
+		}
+		if !cache.Stale() || !rate.Stale {
+			t.Error("Expected rates to be stale after MaxAge")
+		}
+	})
+
+	t.Run("Subscribe", func(t *testing.T) {
+		cache := banking.NewRateCache(client.Banking.ExchangeRates, banking.RateCacheOptions{})
+		var changes []banking.RateChange
+		unsubscribe := cache.Subscribe(func(change banking.RateChange) { changes = append(changes, change) })
+
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		if len(changes) != 4 || changes[0].Previous != nil {
+			t.Fatalf("Expected 4 new pairs, got %+v", changes)
+		}
+
+		changes = nil
+		server.SetRate("USD", "EUR", "0.95")
+		defer server.SetRate("USD", "EUR", "0.92")
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		if len(changes) != 1 || changes[0].Pair != "USD/EUR" ||
+			!changes[0].Previous.Sell.Equal(money.MustParse("0.92")) || !changes[0].Current.Sell.Equal(money.MustParse("0.95")) {
+			t.Errorf("Expected USD/EUR 0.92 -> 0.95, got %+v", changes)
+		}
+
+		changes = nil
+		unsubscribe()
+		server.SetRate("USD", "EUR", "0.96")
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("Expected no notifications after unsubscribe, got %+v", changes)
+		}
+	})
+
+	t.Run("RunReportsErrors", func(t *testing.T) {
+		server.Inject(uqpaytest.Fault{Path: "/v1/exchange/rates", Times: 1})
+		runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		var mu sync.Mutex
+		var errs []error
+		cache := banking.NewRateCache(client.Banking.ExchangeRates, banking.RateCacheOptions{
+			Interval: time.Millisecond,
+			OnError: func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			},
+		})
+		cache.Subscribe(func(banking.RateChange) { cancel() })
+
+		if err := cache.Run(runCtx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(errs) != 1 {
+			t.Errorf("Expected 1 refresh error, got %v", errs)
+		}
+		if _, err := cache.Rate("USD", "GBP"); err != nil {
+			t.Errorf("Expected rates after recovery, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/reconcile"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func TestReconcile(t *testing.T) {
+	// Rewrites the reference of transactions pointing at orphanRef, simulating a ledger entry
+	// whose resource cannot be found
+	var orphanRef string
+	orphan := func(next common.RoundTripFunc) common.RoundTripFunc {
+		return func(req *http.Request) (*common.Response, error) {
+			resp, err := next(req)
+			if err != nil || orphanRef == "" {
+				return resp, err
+			}
+			if list, ok := resp.Result.(*banking.ListBalanceTransactionsResponse); ok {
+				for i := range list.Data {
+					if list.Data[i].ReferenceID == orphanRef {
+						list.Data[i].ReferenceID = "payout_missing"
+					}
+				}
+			}
+			return resp, err
+		}
+	}
+	client, server := uqpaytest.NewClient(t, uqpay.WithMiddleware(orphan))
+	ctx := context.Background()
+	day := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	clock := &testClock{now: day}
+	server.Now = clock.Now
+	tick := func() { clock.Set(clock.Now().Add(time.Minute)) }
+	reconciler := reconcile.New(client.Banking)
+
+	server.SetBalance("USD", "1000")
+	server.SetPayoutFee("2")
+	beneficiaryID := createFakeBeneficiary(t, client)
+	tick()
+	server.AddDeposit("USD", "500", "ACME Ltd")
+	tick()
+	completed := createFakePayout(t, client, beneficiaryID, "100")
+	if err := server.CompletePayout(completed); err != nil {
+		t.Fatalf("CompletePayout failed: %v", err)
+	}
+	tick()
+	failed := createFakePayout(t, client, beneficiaryID, "50")
+	tick()
+	if err := server.FailPayout(failed, "rejected"); err != nil {
+		t.Fatalf("FailPayout failed: %v", err)
+	}
+	tick()
+	conversion, err := client.Banking.Conversions.Create(ctx, &banking.CreateConversionRequest{
+		CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100"),
+	})
+	if err != nil {
+		t.Fatalf("Create conversion failed: %v", err)
+	}
+	if _, err := client.Banking.Conversions.Get(ctx, conversion.ConversionID); err != nil {
+		t.Fatalf("Get conversion failed: %v", err)
+	}
+	tick()
+
+	t.Run("Balanced", func(t *testing.T) {
+		report, err := reconciler.Run(ctx, reconcile.Options{Currency: "usd"})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !report.Balanced() {
+			t.Fatalf("Expected a balanced ledger, got %v", report.Breaks)
+		}
+		checks := []struct {
+			name      string
+			got, want money.Decimal
+		}{
+			{"opening", report.OpeningBalance, money.MustParse("1000")},
+			{"closing", report.ClosingBalance, money.MustParse("1298")},
+			{"current", report.CurrentBalance, money.MustParse("1298")},
+			{"credits", report.Credits, money.MustParse("552")},
+			{"debits", report.Debits, money.MustParse("254")},
+		}
+		for _, c := range checks {
+			if !c.got.Equal(c.want) {
+				t.Errorf("Expected %s balance %s, got %s", c.name, c.want, c.got)
+			}
+		}
+		if len(report.Entries) != 7 {
+			t.Fatalf("Expected 7 entries, got %d", len(report.Entries))
+		}
+		for _, e := range report.Entries {
+			if e.Resource == "" {
+				t.Errorf("Expected %s %s to be joined", e.Transaction.TransactionType, e.Transaction.TransactionID)
+			}
+		}
+		if last := report.Entries[6]; last.Resource != reconcile.ResourceConversion || last.Status != "COMPLETED" {
+			t.Errorf("Expected the last entry to be a completed conversion, got %s %s", last.Resource, last.Status)
+		}
+
+		eur, err := reconciler.Run(ctx, reconcile.Options{Currency: "EUR"})
+		if err != nil {
+			t.Fatalf("Run EUR failed: %v", err)
+		}
+		if !eur.Balanced() || len(eur.Entries) != 1 || !eur.Credits.Equal(money.MustParse("92")) {
+			t.Errorf("Expected a balanced EUR ledger with one 92 credit, got %+v", eur)
+		}
+	})
+
+	t.Run("Period", func(t *testing.T) {
+		report, err := reconciler.Run(ctx, reconcile.Options{
+			Currency: "USD",
+			Start:    day.Add(2 * time.Minute),
+			End:      day.Add(2*time.Minute + 30*time.Second),
+		})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !report.Balanced() {
+			t.Errorf("Expected a balanced period, got %v", report.Breaks)
+		}
+		// The completed payout and its fee
+		if len(report.Entries) != 2 || !report.OpeningBalance.Equal(money.MustParse("1500")) || !report.ClosingBalance.Equal(money.MustParse("1398")) {
+			t.Errorf("Expected 1500 -> 1398 over 2 entries, got %s -> %s over %d", report.OpeningBalance, report.ClosingBalance, len(report.Entries))
+		}
+	})
+
+	t.Run("MissingReference", func(t *testing.T) {
+		orphanRef = completed
+		defer func() { orphanRef = "" }()
+		report, err := reconciler.Run(ctx, reconcile.Options{Currency: "USD"})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(report.Breaks) != 2 {
+			t.Fatalf("Expected 2 breaks, got %v", report.Breaks)
+		}
+		for _, b := range report.Breaks {
+			if b.Kind != reconcile.BreakMissingReference || b.Transaction.ReferenceID != "payout_missing" {
+				t.Errorf("Expected a missing payout reference, got %s", b)
+			}
+		}
+	})
+
+	t.Run("GapAndClosing", func(t *testing.T) {
+		server.SetBalance("GBP", "100")
+		server.AddDeposit("GBP", "10", "ACME Ltd")
+		server.SetBalance("GBP", "200")
+		server.AddDeposit("GBP", "10", "ACME Ltd")
+		server.SetBalance("GBP", "500")
+
+		report, err := reconciler.Run(ctx, reconcile.Options{Currency: "GBP"})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(report.Breaks) != 2 {
+			t.Fatalf("Expected 2 breaks, got %v", report.Breaks)
+		}
+		gap, closing := report.Breaks[0], report.Breaks[1]
+		if gap.Kind != reconcile.BreakGap || !gap.Expected.Equal(money.MustParse("110")) || !gap.Actual.Equal(money.MustParse("200")) {
+			t.Errorf("Expected a gap from 110 to 200, got %s", gap)
+		}
+		if closing.Kind != reconcile.BreakClosing || !closing.Expected.Equal(money.MustParse("500")) || !closing.Actual.Equal(money.MustParse("210")) {
+			t.Errorf("Expected a closing break of 210 against 500, got %s", closing)
+		}
+	})
+
+	t.Run("RequiresCurrency", func(t *testing.T) {
+		if _, err := reconciler.Run(ctx, reconcile.Options{}); err == nil {
+			t.Error("Expected an error without a currency")
+		}
+	})
+}
+
+func TestReconcileBalanceComponent(t *testing.T) {
+	// 150 of the 450 held is frozen; the transactions track the total
+	apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/balances/transactions":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"total_pages": 1, "total_items": 1, "data": []banking.BalanceTransaction{{
+				TransactionID: "txn-1", Currency: "SGD", Amount: money.MustParse("50"), TransactionType: "ADJUSTMENT",
+				TransactionStatus: "COMPLETED", BalanceBefore: money.MustParse("400"), BalanceAfter: money.MustParse("450"),
+				CreateTime: "2024-06-03T09:00:00Z",
+			}}})
+		case "/v1/balances/SGD":
+			writeJSON(w, http.StatusOK, banking.Balance{Currency: "SGD", AvailableBalance: money.MustParse("300"), FrozenBalance: money.MustParse("150")})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"code": "not_found"})
+		}
+	})
+	reconciler := reconcile.New(banking.NewClient(apiClient))
+	ctx := context.Background()
+
+	report, err := reconciler.Run(ctx, reconcile.Options{Currency: "SGD"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Breaks) != 1 || report.Breaks[0].Kind != reconcile.BreakClosing || !report.CurrentBalance.Equal(money.MustParse("300")) {
+		t.Errorf("Expected a closing break against the available 300, got %v", report.Breaks)
+	}
+
+	report, err = reconciler.Run(ctx, reconcile.Options{Currency: "SGD", Balance: reconcile.TotalBalance})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !report.Balanced() || !report.CurrentBalance.Equal(money.MustParse("450")) {
+		t.Errorf("Expected the total of 450 to match, got %s and %v", report.CurrentBalance, report.Breaks)
+	}
+}
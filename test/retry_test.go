@@ -0,0 +1,134 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+)
+
+func TestRetry(t *testing.T) {
+	fastPolicy := &common.RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             10 * time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+	}
+
+	t.Run("RetriesWithSameIdempotencyKey", func(t *testing.T) {
+		var mu sync.Mutex
+		var keys []string
+		client := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			keys = append(keys, r.Header.Get("x-idempotency-key"))
+			attempt := len(keys)
+			mu.Unlock()
+
+			if attempt < 3 {
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{"code": "unavailable"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"payout_id": "p-1"})
+		})
+		client.RetryPolicy = fastPolicy
+
+		var resp struct {
+			PayoutID string `json:"payout_id"`
+		}
+		if err := client.Post(context.Background(), "/v1/payouts", map[string]string{"amount": "1"}, &resp); err != nil {
+			t.Fatalf("Post failed: %v", err)
+		}
+		if resp.PayoutID != "p-1" {
+			t.Errorf("Expected payout_id p-1, got %q", resp.PayoutID)
+		}
+		if len(keys) != 3 {
+			t.Fatalf("Expected 3 attempts, got %d", len(keys))
+		}
+		for _, key := range keys {
+			if key == "" || key != keys[0] {
+				t.Errorf("Expected the same idempotency key on every attempt, got %v", keys)
+				break
+			}
+		}
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		attempts := 0
+		client := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Retry-After", "0")
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"code": "rate_limited", "message": "slow down"})
+		})
+		client.RetryPolicy = fastPolicy
+
+		err := client.Get(context.Background(), "/v1/balances", nil)
+		apiErr, ok := err.(*common.APIError)
+		if !ok {
+			t.Fatalf("Expected *common.APIError, got %T: %v", err, err)
+		}
+		if apiErr.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429, got %d", apiErr.StatusCode)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("DoesNotRetryClientErrors", func(t *testing.T) {
+		attempts := 0
+		client := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			writeJSON(w, http.StatusBadRequest, map[string]string{"code": "invalid", "message": "bad"})
+		})
+		client.RetryPolicy = fastPolicy
+
+		if err := client.Get(context.Background(), "/v1/balances", nil); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("PerCallPolicy", func(t *testing.T) {
+		attempts := 0
+		client := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"code": "unavailable"})
+		})
+		client.RetryPolicy = fastPolicy
+
+		ctx := common.WithRetryPolicy(context.Background(), common.NoRetry())
+		if err := client.Get(ctx, "/v1/balances", nil); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt with NoRetry, got %d", attempts)
+		}
+	})
+
+	t.Run("StopsOnContextCancel", func(t *testing.T) {
+		client := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"code": "unavailable"})
+		})
+		client.RetryPolicy = &common.RetryPolicy{
+			MaxAttempts:          5,
+			BaseDelay:            time.Second,
+			RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if err := client.Get(ctx, "/v1/balances", nil); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected retry wait to stop on cancel, took %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/statement"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func TestStatement(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	day := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	clock := &testClock{now: day}
+	server.Now = clock.Now
+	tick := func() { clock.Set(clock.Now().Add(time.Minute)) }
+
+	server.SetBalance("USD", "1000")
+	server.SetPayoutFee("2")
+	beneficiaryID := createFakeBeneficiary(t, client)
+	tick()
+	deposit := server.AddDeposit("USD", "500", "ACME Ltd")
+	tick()
+	payoutID := createFakePayout(t, client, beneficiaryID, "100")
+	tick()
+	conversion, err := client.Banking.Conversions.Create(ctx, &banking.CreateConversionRequest{
+		CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100"),
+	})
+	if err != nil {
+		t.Fatalf("Create conversion failed: %v", err)
+	}
+	if _, err := client.Banking.Conversions.Get(ctx, conversion.ConversionID); err != nil {
+		t.Fatalf("Get conversion failed: %v", err)
+	}
+
+	generator := statement.New(client.Banking)
+	end := time.Date(2024, 6, 3, 23, 59, 59, 0, time.UTC)
+	stmt, err := generator.Generate(ctx, statement.Options{
+		Currency:  "USD",
+		Start:     time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+		End:       end,
+		AccountID: "ACME-USD",
+		Now:       func() time.Time { return end },
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	t.Run("Figures", func(t *testing.T) {
+		if !stmt.OpeningBalance.Equal(money.MustParse("1000")) || !stmt.ClosingBalance.Equal(money.MustParse("1298")) {
+			t.Errorf("Expected 1000 -> 1298, got %s -> %s", stmt.OpeningBalance, stmt.ClosingBalance)
+		}
+		wantTypes := []string{statement.TypePayin, statement.TypePayout, statement.TypeFee, statement.TypeConversion}
+		if len(stmt.Lines) != len(wantTypes) {
+			t.Fatalf("Expected %d lines, got %+v", len(wantTypes), stmt.Lines)
+		}
+		for i, want := range wantTypes {
+			if stmt.Lines[i].Type != want {
+				t.Errorf("Line %d: expected %s, got %s", i, want, stmt.Lines[i].Type)
+			}
+		}
+		if stmt.Lines[0].Reference != deposit.DepositID || stmt.Lines[1].Reference != payoutID || !stmt.Lines[1].Amount.Equal(money.MustParse("-100")) {
+			t.Errorf("Unexpected references or amounts: %+v", stmt.Lines[:2])
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := stmt.Write(&buf, statement.FormatCSV); err != nil {
+			t.Fatalf("WriteCSV failed: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Invalid CSV: %v", err)
+		}
+		if len(records) != 7 {
+			t.Fatalf("Expected header, opening, 4 lines and closing, got %d records", len(records))
+		}
+		if records[1][1] != "OPENING" || records[1][9] != "1000.00" || records[6][1] != "CLOSING" || records[6][9] != "1298.00" {
+			t.Errorf("Unexpected opening or closing rows: %v, %v", records[1], records[6])
+		}
+		if payout := records[3]; payout[1] != statement.TypePayout || payout[7] != "100.00" || payout[8] != "" {
+			t.Errorf("Expected a 100.00 payout debit, got %v", payout)
+		}
+	})
+
+	t.Run("MT940", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := stmt.Write(&buf, "mt940"); err != nil {
+			t.Fatalf("WriteMT940 failed: %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			":25:ACME-USD\r\n",
+			":60F:C240603USD1000,00\r\n",
+			":61:2406030603C500,00NTRF",
+			":61:2406030603D2,00NCHG",
+			":61:2406030603D100,00NFEX",
+			":62F:C240603USD1298,00\r\n-\r\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %q in MT940:\n%s", want, out)
+			}
+		}
+		for _, line := range strings.Split(out, "\r\n") {
+			if len(line) > 65+len(":86:") {
+				t.Errorf("Line longer than 65 characters: %q", line)
+			}
+		}
+	})
+
+	t.Run("CAMT053", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := stmt.Write(&buf, "camt.053"); err != nil {
+			t.Fatalf("WriteCAMT053 failed: %v", err)
+		}
+		var doc struct {
+			Stmt struct {
+				Bal []struct {
+					Code string `xml:"Tp>CdOrPrtry>Cd"`
+					Amt  string `xml:"Amt"`
+				} `xml:"Bal"`
+				Ntry []struct {
+					Amt       string `xml:"Amt"`
+					CdtDbtInd string `xml:"CdtDbtInd"`
+					Family    string `xml:"BkTxCd>Domn>Fmly>Cd"`
+					EndToEnd  string `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+				} `xml:"Ntry"`
+			} `xml:"BkToCstmrStmt>Stmt"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("Invalid XML: %v", err)
+		}
+		if len(doc.Stmt.Bal) != 2 || doc.Stmt.Bal[0].Code != "OPBD" || doc.Stmt.Bal[0].Amt != "1000.00" || doc.Stmt.Bal[1].Amt != "1298.00" {
+			t.Errorf("Unexpected balances: %+v", doc.Stmt.Bal)
+		}
+		if len(doc.Stmt.Ntry) != 4 {
+			t.Fatalf("Expected 4 entries, got %d", len(doc.Stmt.Ntry))
+		}
+		if e := doc.Stmt.Ntry[1]; e.CdtDbtInd != "DBIT" || e.Family != "ICDT" || e.EndToEnd != payoutID {
+			t.Errorf("Unexpected payout entry: %+v", e)
+		}
+	})
+
+	t.Run("OFX", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := stmt.Write(&buf, statement.FormatOFX); err != nil {
+			t.Fatalf("WriteOFX failed: %v", err)
+		}
+		var doc struct {
+			Trn []struct {
+				TrnType string `xml:"TRNTYPE"`
+				TrnAmt  string `xml:"TRNAMT"`
+			} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+			BalAmt string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>BALAMT"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("Invalid OFX: %v", err)
+		}
+		if len(doc.Trn) != 4 || doc.Trn[0].TrnType != "DEP" || doc.Trn[2].TrnType != "FEE" || doc.Trn[2].TrnAmt != "-2.00" {
+			t.Errorf("Unexpected transactions: %+v", doc.Trn)
+		}
+		if doc.BalAmt != "1298.00" {
+			t.Errorf("Expected ledger balance 1298.00, got %s", doc.BalAmt)
+		}
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		if err := stmt.Write(&bytes.Buffer{}, "QIF"); !errors.Is(err, statement.ErrUnknownFormat) {
+			t.Errorf("Expected ErrUnknownFormat, got %v", err)
+		}
+	})
+
+	t.Run("EmptyPeriod", func(t *testing.T) {
+		next := time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC)
+		empty, err := generator.Generate(ctx, statement.Options{Currency: "USD", Start: next, End: next.Add(24 * time.Hour)})
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		if len(empty.Lines) != 0 || !empty.OpeningBalance.Equal(money.MustParse("1298")) || !empty.ClosingBalance.Equal(money.MustParse("1298")) {
+			t.Errorf("Expected no lines at 1298, got %d lines, %s -> %s", len(empty.Lines), empty.OpeningBalance, empty.ClosingBalance)
+		}
+	})
+
+	t.Run("EmptyPeriodWithoutStart", func(t *testing.T) {
+		before := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)
+		empty, err := generator.Generate(ctx, statement.Options{Currency: "USD", End: before})
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		if len(empty.Lines) != 0 || !empty.Start.Equal(before) || !empty.OpeningBalance.Equal(money.MustParse("1000")) {
+			t.Fatalf("Expected no lines at 1000 starting at End, got %d lines at %s starting %s", len(empty.Lines), empty.OpeningBalance, empty.Start)
+		}
+
+		var mt940, camt, csvOut bytes.Buffer
+		empty.WriteMT940(&mt940)
+		empty.WriteCAMT053(&camt)
+		empty.WriteCSV(&csvOut)
+		if want := ":60F:C240602USD1000,00\r\n"; !strings.Contains(mt940.String(), want) {
+			t.Errorf("Expected %q in MT940:\n%s", want, mt940.String())
+		}
+		if want := "<Dt>2024-06-02</Dt>"; !strings.Contains(camt.String(), want) {
+			t.Errorf("Expected opening balance dated %q in camt.053:\n%s", want, camt.String())
+		}
+		records, err := csv.NewReader(&csvOut).ReadAll()
+		if err != nil || len(records) < 2 || records[1][0] != "2024-06-02" {
+			t.Errorf("Expected the opening row dated 2024-06-02, got %v (%v)", records, err)
+		}
+	})
+
+	t.Run("GenerateAll", func(t *testing.T) {
+		all, err := generator.GenerateAll(ctx, statement.Options{End: end})
+		if err != nil {
+			t.Fatalf("GenerateAll failed: %v", err)
+		}
+		if len(all) != 2 || all[0].Currency != "EUR" || all[1].Currency != "USD" {
+			t.Fatalf("Expected EUR and USD statements, got %d", len(all))
+		}
+		if eur := all[0]; len(eur.Lines) != 1 || eur.Lines[0].Type != statement.TypeConversion || !eur.ClosingBalance.Equal(money.MustParse("92")) {
+			t.Errorf("Unexpected EUR statement: %+v", eur)
+		}
+	})
+}
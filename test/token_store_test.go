@@ -0,0 +1,167 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/auth"
+)
+
+func TestTokenStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SharedMemoryStore", func(t *testing.T) {
+		server, fetches := newTokenServer(t, time.Hour, 0, nil)
+		store := auth.NewMemoryTokenStore()
+		main := auth.NewTokenProviderWithStore(server.URL, "id", "key", server.Client(), store)
+		files := auth.NewTokenProviderWithStore(server.URL, "id", "key", server.Client(), store)
+
+		first, _ := main.GetToken(ctx)
+		second, _ := files.GetToken(ctx)
+		if first != "token-1" || second != "token-1" {
+			t.Errorf("Expected both providers to use token-1, got %q and %q", first, second)
+		}
+		if *fetches != 1 {
+			t.Errorf("Expected 1 token fetch, got %d", *fetches)
+		}
+	})
+
+	t.Run("EnvironmentsKeptApart", func(t *testing.T) {
+		sandbox, sandboxFetches := newTokenServer(t, time.Hour, 0, nil)
+		production, productionFetches := newTokenServer(t, time.Hour, 0, nil)
+		store := auth.NewMemoryTokenStore()
+		auth.NewTokenProviderWithStore(sandbox.URL, "id", "key", sandbox.Client(), store).GetToken(ctx)
+		auth.NewTokenProviderWithStore(production.URL, "id", "key", production.Client(), store).GetToken(ctx)
+		if *sandboxFetches != 1 || *productionFetches != 1 {
+			t.Errorf("Expected one fetch per environment, got %d sandbox and %d production", *sandboxFetches, *productionFetches)
+		}
+
+		// A Files provider sharing the main provider's key reuses its token
+		files, filesFetches := newTokenServer(t, time.Hour, 0, nil)
+		key := auth.TokenKey("id", sandbox.URL)
+		token, _ := auth.NewTokenProviderWithStoreKey(files.URL, "id", "key", files.Client(), store, key).GetToken(ctx)
+		if token != "token-1" || *filesFetches != 0 {
+			t.Errorf("Expected the stored sandbox token without a fetch, got %q after %d fetches", token, *filesFetches)
+		}
+	})
+
+	t.Run("FileStoreAcrossProcesses", func(t *testing.T) {
+		server, fetches := newTokenServer(t, time.Hour, 50*time.Millisecond, nil)
+		dir := t.TempDir()
+
+		// Each provider has its own store instance, as separate processes would
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			store, err := auth.NewFileTokenStore(dir)
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			provider := auth.NewTokenProviderWithStore(server.URL, "id", "key", server.Client(), store)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := provider.GetToken(ctx)
+				if err != nil || token != "token-1" {
+					t.Errorf("Expected token-1, got %q (%v)", token, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if *fetches != 1 {
+			t.Errorf("Expected 1 token fetch, got %d", *fetches)
+		}
+	})
+
+	t.Run("InvalidatedTokenNotReused", func(t *testing.T) {
+		server, fetches := newTokenServer(t, time.Hour, 0, nil)
+		store := auth.NewMemoryTokenStore()
+		provider := auth.NewTokenProviderWithStore(server.URL, "id", "key", server.Client(), store)
+
+		first, _ := provider.GetToken(ctx)
+		provider.InvalidateToken(first)
+		second, _ := provider.GetToken(ctx)
+		if second == first {
+			t.Errorf("Expected a new token after invalidation, got %q again", second)
+		}
+		if *fetches != 2 {
+			t.Errorf("Expected 2 token fetches, got %d", *fetches)
+		}
+	})
+
+	t.Run("FileStoreRoundTrip", func(t *testing.T) {
+		store, err := auth.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens"))
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+
+		if token, err := store.Get(ctx, "missing"); token != nil || err != nil {
+			t.Errorf("Expected nil token for a missing key, got %v (%v)", token, err)
+		}
+
+		expires := time.Now().Add(time.Hour).Truncate(time.Second)
+		if err := store.Set(ctx, auth.TokenKey("id", "https://api-sandbox.uqpaytech.com"), &auth.Token{Value: "abc", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Failed to set token: %v", err)
+		}
+		token, err := store.Get(ctx, auth.TokenKey("id", "https://api-sandbox.uqpaytech.com"))
+		if err != nil || token == nil {
+			t.Fatalf("Expected stored token, got %v (%v)", token, err)
+		}
+		if token.Value != "abc" || !token.ExpiresAt.Equal(expires) {
+			t.Errorf("Expected abc expiring %v, got %s expiring %v", expires, token.Value, token.ExpiresAt)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(store.Dir, "token-*.json"))
+		if len(files) != 1 {
+			t.Fatalf("Expected 1 token file, got %d", len(files))
+		}
+		if info, _ := os.Stat(files[0]); info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+		}
+	})
+
+	t.Run("FileStoreLock", func(t *testing.T) {
+		store, _ := auth.NewFileTokenStore(t.TempDir())
+		store.PollInterval = 5 * time.Millisecond
+
+		unlock, err := store.Lock(ctx, "key")
+		if err != nil {
+			t.Fatalf("Failed to lock: %v", err)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+		defer cancel()
+		if _, err := store.Lock(waitCtx, "key"); err == nil {
+			t.Error("Expected second Lock to wait until the context is done")
+		}
+
+		unlock()
+		unlock2, err := store.Lock(ctx, "key")
+		if err != nil {
+			t.Fatalf("Expected lock after unlock, got %v", err)
+		}
+		unlock2()
+	})
+
+	t.Run("FileStoreStaleLock", func(t *testing.T) {
+		store, _ := auth.NewFileTokenStore(t.TempDir())
+		store.StaleLockAge = 10 * time.Millisecond
+
+		if _, err := store.Lock(ctx, "key"); err != nil {
+			t.Fatalf("Failed to lock: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		unlock, err := store.Lock(waitCtx, "key")
+		if err != nil {
+			t.Fatalf("Expected stale lock to be replaced, got %v", err)
+		}
+		unlock()
+	})
+}
@@ -0,0 +1,167 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/auth"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+)
+
+// newTokenServer starts a server whose token endpoint returns "token-1", "token-2", ...
+// valid for ttl, after waiting for delay
+func newTokenServer(t *testing.T, ttl, delay time.Duration, handler http.HandlerFunc) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var fetches int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		time.Sleep(delay)
+		writeJSON(w, http.StatusOK, auth.TokenResponse{
+			AuthToken: fmt.Sprintf("token-%d", n),
+			ExpiredAt: time.Now().Add(ttl).Unix(),
+		})
+	})
+	if handler != nil {
+		mux.HandleFunc("/", handler)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func TestTokenProvider(t *testing.T) {
+	t.Run("SingleFlight", func(t *testing.T) {
+		server, fetches := newTokenServer(t, time.Hour, 50*time.Millisecond, nil)
+		provider := auth.NewTokenProvider(server.URL, "id", "key", server.Client())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := provider.GetToken(context.Background())
+				if err != nil || token != "token-1" {
+					t.Errorf("Expected token-1, got %q (%v)", token, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if *fetches != 1 {
+			t.Errorf("Expected 1 token fetch, got %d", *fetches)
+		}
+	})
+
+	t.Run("HonoursContext", func(t *testing.T) {
+		release := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		})
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+		t.Cleanup(func() { close(release) })
+		provider := auth.NewTokenProvider(server.URL, "id", "key", server.Client())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := provider.GetToken(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected GetToken to return promptly, took %v", elapsed)
+		}
+	})
+
+	t.Run("BackgroundRefresh", func(t *testing.T) {
+		// Tokens expire within the 5 minute refresh buffer, so every use triggers a refresh
+		server, _ := newTokenServer(t, 2*time.Minute, 200*time.Millisecond, nil)
+		provider := auth.NewTokenProvider(server.URL, "id", "key", server.Client())
+		ctx := context.Background()
+
+		first, err := provider.GetToken(ctx)
+		if err != nil || first != "token-1" {
+			t.Fatalf("Expected token-1, got %q (%v)", first, err)
+		}
+
+		start := time.Now()
+		second, _ := provider.GetToken(ctx)
+		if second != "token-1" {
+			t.Errorf("Expected the still-valid token while refreshing, got %q", second)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("Expected no wait for a still-valid token, took %v", elapsed)
+		}
+
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			token, _ := provider.GetToken(ctx)
+			if token != "token-1" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected a background refresh to replace token-1")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("RetriesOnceOn401", func(t *testing.T) {
+		var calls int32
+		server, fetches := newTokenServer(t, time.Hour, 0, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			if r.Header.Get("x-auth-token") == "token-1" {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "token revoked"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		})
+		client := newTokenTestClient(server)
+
+		var resp map[string]string
+		if err := client.Get(context.Background(), "/v1/ping", &resp); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if calls != 2 || *fetches != 2 {
+			t.Errorf("Expected 2 calls and 2 token fetches, got %d and %d", calls, *fetches)
+		}
+	})
+
+	t.Run("Persistent401", func(t *testing.T) {
+		var calls int32
+		server, _ := newTokenServer(t, time.Hour, 0, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "bad credentials"})
+		})
+		client := newTokenTestClient(server)
+
+		err := client.Get(context.Background(), "/v1/ping", nil)
+		var apiErr *common.APIError
+		if !errors.As(err, &apiErr) || !apiErr.IsUnauthorized() {
+			t.Errorf("Expected unauthorized error, got %v", err)
+		}
+		if calls != 2 {
+			t.Errorf("Expected exactly one retry, got %d calls", calls)
+		}
+	})
+}
+
+func newTokenTestClient(server *httptest.Server) *common.APIClient {
+	config := &configuration.Configuration{
+		Environment: &configuration.Environment{BaseURL: server.URL},
+		HTTPClient:  server.Client(),
+	}
+	return common.NewAPIClient(config, auth.NewTokenProvider(server.URL, "id", "key", server.Client()))
+}
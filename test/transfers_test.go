@@ -76,7 +76,7 @@ func TestTransfers(t *testing.T) {
 				SourceAccountID: "source-account-uuid",
 				TargetAccountID: "target-account-uuid",
 				Currency:        "USD",
-				Amount:          "10.00",
+				Amount:          money.MustParse("10.00"),
 				Reason:          "Test transfer",
 			}
 
@@ -0,0 +1,211 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+)
+
+func TestFileUpload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SendsMultipartForm", func(t *testing.T) {
+		var gotName, gotType, gotContent, gotNotes, gotToken, gotKey string
+		client := supporting.NewClient(NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/files/upload" {
+				t.Errorf("Expected path /v1/files/upload, got %s", r.URL.Path)
+			}
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data; boundary=") {
+				t.Errorf("Expected multipart content type, got %s", r.Header.Get("Content-Type"))
+			}
+			gotToken = r.Header.Get("x-auth-token")
+			gotKey = r.Header.Get("x-idempotency-key")
+
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("Failed to read form file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			gotName = header.Filename
+			gotType = header.Header.Get("Content-Type")
+			gotContent = string(data)
+			gotNotes = r.FormValue("notes")
+
+			writeJSON(w, http.StatusOK, supporting.UploadFileResponse{FileID: "file_1", FileName: header.Filename, Size: len(data)})
+		}))
+
+		resp, err := client.Files.Upload(ctx, &supporting.UploadFileParams{
+			File:     strings.NewReader("%PDF-1.4 passport"),
+			FileName: "passport.pdf",
+			Notes:    "KYC",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.FileID != "file_1" {
+			t.Errorf("Expected file ID file_1, got %s", resp.FileID)
+		}
+		if gotName != "passport.pdf" || gotType != "application/pdf" || gotContent != "%PDF-1.4 passport" {
+			t.Errorf("Unexpected file part: name=%s type=%s content=%q", gotName, gotType, gotContent)
+		}
+		if gotNotes != "KYC" {
+			t.Errorf("Expected notes KYC, got %q", gotNotes)
+		}
+		if gotToken != "mock-token" || gotKey == "" {
+			t.Errorf("Expected auth token and idempotency key, got %q and %q", gotToken, gotKey)
+		}
+	})
+
+	t.Run("RejectsUnsupportedType", func(t *testing.T) {
+		var calls int32
+		client := supporting.NewClient(NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}))
+
+		_, err := client.Files.Upload(ctx, &supporting.UploadFileParams{
+			File:     strings.NewReader("#!/bin/sh"),
+			FileName: "script.sh",
+		})
+		if !errors.Is(err, supporting.ErrUnsupportedFileType) {
+			t.Errorf("Expected ErrUnsupportedFileType, got %v", err)
+		}
+		if calls != 0 {
+			t.Errorf("Expected no request, got %d", calls)
+		}
+	})
+
+	t.Run("RejectsOversizedFile", func(t *testing.T) {
+		client := supporting.NewClient(NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Expected no request for an oversized file")
+		}))
+
+		_, err := client.Files.Upload(ctx, &supporting.UploadFileParams{
+			File:     bytes.NewReader(make([]byte, supporting.MaxUploadSize+1)),
+			FileName: "scan.png",
+		})
+		if !errors.Is(err, common.ErrFileTooLarge) {
+			t.Errorf("Expected ErrFileTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("RejectsOversizedStream", func(t *testing.T) {
+		apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			writeJSON(w, http.StatusOK, map[string]string{})
+		})
+
+		// A reader without a known size is checked while streaming
+		err := apiClient.PostMultipart(ctx, "/v1/files/upload", nil, &common.MultipartFile{
+			FileName: "big.pdf",
+			Reader:   io.LimitReader(zeroReader{}, 2048),
+			MaxSize:  1024,
+		}, nil)
+		if !errors.Is(err, common.ErrFileTooLarge) {
+			t.Errorf("Expected ErrFileTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("RetriesSeekableFile", func(t *testing.T) {
+		var calls int32
+		apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("Failed to read form file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if string(data) != "image-bytes" {
+				t.Errorf("Expected full content on every attempt, got %q", data)
+			}
+			if atomic.AddInt32(&calls, 1) == 1 {
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "busy"})
+				return
+			}
+			writeJSON(w, http.StatusOK, supporting.UploadFileResponse{FileID: "file_2"})
+		})
+		apiClient.RetryPolicy = &common.RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+
+		resp, err := supporting.NewClient(apiClient).Files.Upload(ctx, &supporting.UploadFileParams{
+			File:     strings.NewReader("image-bytes"),
+			FileName: "selfie.JPG",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.FileID != "file_2" || calls != 2 {
+			t.Errorf("Expected success after 2 attempts, got %s after %d", resp.FileID, calls)
+		}
+	})
+
+	t.Run("RetriesAfterUnreadBody", func(t *testing.T) {
+		// The first attempt is rejected before the body is read, while the writer is still
+		// copying the file; the retry must not seek the file until that writer has stopped
+		content := bytes.Repeat([]byte("x"), 8<<20)
+		var calls int32
+		apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "busy"})
+				return
+			}
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Failed to read form file: %v", err)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			if !bytes.Equal(data, content) {
+				t.Errorf("Expected %d bytes on retry, got %d", len(content), len(data))
+			}
+			writeJSON(w, http.StatusOK, supporting.UploadFileResponse{FileID: "file_3"})
+		})
+		apiClient.RetryPolicy = &common.RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+
+		resp, err := supporting.NewClient(apiClient).Files.Upload(ctx, &supporting.UploadFileParams{
+			File:     bytes.NewReader(content),
+			FileName: "scan.pdf",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.FileID != "file_3" || atomic.LoadInt32(&calls) != 2 {
+			t.Errorf("Expected success after 2 attempts, got %s after %d", resp.FileID, calls)
+		}
+	})
+
+	t.Run("DoesNotRetryStream", func(t *testing.T) {
+		var calls int32
+		apiClient := NewMockAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			atomic.AddInt32(&calls, 1)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "busy"})
+		})
+		apiClient.RetryPolicy = &common.RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+
+		_, err := supporting.NewClient(apiClient).Files.Upload(ctx, &supporting.UploadFileParams{
+			File:     io.MultiReader(strings.NewReader("doc-bytes")),
+			FileName: "contract.docx",
+		})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 attempt for a non-seekable reader, got %d", calls)
+		}
+	})
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
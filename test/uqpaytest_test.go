@@ -0,0 +1,455 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/connect"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+func createFakeBeneficiary(t *testing.T, client *uqpay.Client) string {
+	t.Helper()
+	resp, err := client.Banking.Beneficiaries.Create(context.Background(), &banking.BeneficiaryCreationRequest{
+		EntityType:    "INDIVIDUAL",
+		FirstName:     "Jane",
+		LastName:      "Doe",
+		Currency:      "USD",
+		Country:       "US",
+		PaymentMethod: "LOCAL",
+		BankDetails:   &banking.BankDetails{AccountNumber: "123456789", BankName: "Test Bank"},
+		Address:       &banking.Address{FirstLine: "1 Main St", City: "New York", Country: "US"},
+	})
+	if err != nil {
+		t.Fatalf("Create beneficiary failed: %v", err)
+	}
+	return resp.BeneficiaryID
+}
+
+func TestFakeServerIssuing(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "1000")
+
+	holder, err := client.Issuing.Cardholders.Create(ctx, &issuing.CreateCardholderRequest{
+		Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", CountryCode: "SG",
+	})
+	if err != nil {
+		t.Fatalf("Create cardholder failed: %v", err)
+	}
+
+	created, err := client.Issuing.Cards.Create(ctx, &issuing.CreateCardRequest{
+		CardCurrency: "USD", CardholderID: holder.CardholderID, CardProductID: uqpaytest.ProductID,
+	})
+	if err != nil {
+		t.Fatalf("Create card failed: %v", err)
+	}
+	if created.CardStatus != "PENDING" {
+		t.Errorf("Expected new card PENDING, got %s", created.CardStatus)
+	}
+
+	t.Run("CardActivatesOnRead", func(t *testing.T) {
+		card, err := client.Issuing.Cards.Get(ctx, created.CardID)
+		if err != nil {
+			t.Fatalf("Get card failed: %v", err)
+		}
+		if card.CardStatus != "ACTIVE" {
+			t.Errorf("Expected ACTIVE, got %s", card.CardStatus)
+		}
+		if card.Cardholder.Email != "jane@example.com" || card.Cardholder.NumberOfCards != 1 {
+			t.Errorf("Unexpected cardholder info: %+v", card.Cardholder)
+		}
+	})
+
+	t.Run("RechargeMovesFunds", func(t *testing.T) {
+		order, err := client.Issuing.Cards.Recharge(ctx, created.CardID, &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("250"))})
+		if err != nil {
+			t.Fatalf("Recharge failed: %v", err)
+		}
+		if order.OrderStatus != "PROCESSING" {
+			t.Errorf("Expected PROCESSING order, got %s", order.OrderStatus)
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("750")) {
+			t.Errorf("Expected USD balance 750, got %s", got)
+		}
+
+		order, err = client.Issuing.Cards.GetOrder(ctx, order.CardOrderID)
+		if err != nil {
+			t.Fatalf("GetOrder failed: %v", err)
+		}
+		if order.OrderStatus != "SUCCESS" {
+			t.Errorf("Expected SUCCESS order, got %s", order.OrderStatus)
+		}
+	})
+
+	t.Run("CardTransactions", func(t *testing.T) {
+		if _, err := server.AddCardTransaction(created.CardID, "40.50", "Coffee Shop"); err != nil {
+			t.Fatalf("AddCardTransaction failed: %v", err)
+		}
+		declined, err := server.AddCardTransaction(created.CardID, "1000", "Jeweller")
+		if err != nil {
+			t.Fatalf("AddCardTransaction failed: %v", err)
+		}
+		if declined.TransactionStatus != "DECLINED" {
+			t.Errorf("Expected DECLINED, got %s", declined.TransactionStatus)
+		}
+
+		txns, err := client.Issuing.Transactions.List(ctx, &issuing.ListTransactionsRequest{PageSize: 10, PageNumber: 1, CardID: created.CardID})
+		if err != nil {
+			t.Fatalf("List transactions failed: %v", err)
+		}
+		if txns.TotalItems != 2 {
+			t.Errorf("Expected 2 transactions, got %d", txns.TotalItems)
+		}
+
+		card, err := client.Issuing.Cards.Get(ctx, created.CardID)
+		if err != nil {
+			t.Fatalf("Get card failed: %v", err)
+		}
+		if !card.AvailableBalance.Equal(money.MustParse("209.50")) {
+			t.Errorf("Expected card balance 209.50, got %s", card.AvailableBalance)
+		}
+	})
+
+	t.Run("UnknownProduct", func(t *testing.T) {
+		_, err := client.Issuing.Cards.Create(ctx, &issuing.CreateCardRequest{
+			CardCurrency: "USD", CardholderID: holder.CardholderID, CardProductID: "missing",
+		})
+		if !errors.Is(err, common.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestFakeServerPayouts(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "100")
+	server.SetPayoutFee("1.50")
+	beneficiaryID := createFakeBeneficiary(t, client)
+
+	t.Run("StatusProgression", func(t *testing.T) {
+		created, err := client.Banking.Payouts.Create(ctx, &banking.CreatePayoutRequest{
+			BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse("60"), PayoutPurpose: "salary",
+		})
+		if err != nil {
+			t.Fatalf("Create payout failed: %v", err)
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("38.50")) {
+			t.Errorf("Expected balance 38.50 after payout and fee, got %s", got)
+		}
+
+		for _, want := range []string{"PROCESSING", "COMPLETED", "COMPLETED"} {
+			payout, err := client.Banking.Payouts.Get(ctx, created.PayoutID)
+			if err != nil {
+				t.Fatalf("Get payout failed: %v", err)
+			}
+			if payout.PayoutStatus != want {
+				t.Errorf("Expected %s, got %s", want, payout.PayoutStatus)
+			}
+		}
+	})
+
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		_, err := client.Banking.Payouts.Create(ctx, &banking.CreatePayoutRequest{
+			BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse("500"), PayoutPurpose: "salary",
+		})
+		if !errors.Is(err, common.ErrInsufficientBalance) {
+			t.Errorf("Expected ErrInsufficientBalance, got %v", err)
+		}
+	})
+
+	t.Run("FailedPayoutRefunds", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+
+		created, err := client.Banking.Payouts.Create(ctx, &banking.CreatePayoutRequest{
+			BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse("10"), PayoutPurpose: "refund",
+		})
+		if err != nil {
+			t.Fatalf("Create payout failed: %v", err)
+		}
+		if err := server.FailPayout(created.PayoutID, "account closed"); err != nil {
+			t.Fatalf("FailPayout failed: %v", err)
+		}
+		payout, err := client.Banking.Payouts.Get(ctx, created.PayoutID)
+		if err != nil {
+			t.Fatalf("Get payout failed: %v", err)
+		}
+		if payout.PayoutStatus != "FAILED" || payout.FailureReason != "account closed" {
+			t.Errorf("Expected FAILED with reason, got %s %q", payout.PayoutStatus, payout.FailureReason)
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("38.50")) {
+			t.Errorf("Expected refunded balance 38.50, got %s", got)
+		}
+	})
+
+	t.Run("BalanceTransactions", func(t *testing.T) {
+		resp, err := client.Banking.Balances.ListTransactions(ctx, &banking.ListBalanceTransactionsRequest{PageSize: 50, PageNumber: 1, TransactionType: "FEE"})
+		if err != nil {
+			t.Fatalf("List balance transactions failed: %v", err)
+		}
+		if resp.TotalItems != 2 {
+			t.Errorf("Expected 2 fee transactions, got %d", resp.TotalItems)
+		}
+	})
+}
+
+func TestFakeServerConversions(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "1000")
+	server.SetRate("USD", "EUR", "0.9")
+
+	t.Run("QuotedConversion", func(t *testing.T) {
+		quote, err := client.Banking.Conversions.CreateQuote(ctx, &banking.CreateQuoteRequest{
+			CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100"),
+		})
+		if err != nil {
+			t.Fatalf("CreateQuote failed: %v", err)
+		}
+		if !quote.AmountTo.Equal(money.MustParse("90")) {
+			t.Errorf("Expected 90 EUR, got %s", quote.AmountTo)
+		}
+
+		created, err := client.Banking.Conversions.Create(ctx, &banking.CreateConversionRequest{
+			CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100"), QuoteID: quote.QuoteID,
+		})
+		if err != nil {
+			t.Fatalf("Create conversion failed: %v", err)
+		}
+		conversion, err := client.Banking.Conversions.Get(ctx, created.ConversionID)
+		if err != nil {
+			t.Fatalf("Get conversion failed: %v", err)
+		}
+		if conversion.ConversionStatus != "COMPLETED" {
+			t.Errorf("Expected COMPLETED, got %s", conversion.ConversionStatus)
+		}
+		if got := server.Balance("EUR"); !got.Equal(money.MustParse("90")) {
+			t.Errorf("Expected EUR balance 90, got %s", got)
+		}
+
+		_, err = client.Banking.Conversions.Create(ctx, &banking.CreateConversionRequest{
+			CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("100"), QuoteID: quote.QuoteID,
+		})
+		if !errors.Is(err, common.ErrConflict) {
+			t.Errorf("Expected reused quote to conflict, got %v", err)
+		}
+	})
+
+	t.Run("ExpiredQuote", func(t *testing.T) {
+		quote, err := client.Banking.Conversions.CreateQuote(ctx, &banking.CreateQuoteRequest{
+			CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("10"),
+		})
+		if err != nil {
+			t.Fatalf("CreateQuote failed: %v", err)
+		}
+		server.Now = func() time.Time { return time.Now().Add(time.Minute) }
+		defer func() { server.Now = time.Now }()
+
+		_, err = client.Banking.Conversions.Create(ctx, &banking.CreateConversionRequest{
+			CurrencyFrom: "USD", CurrencyTo: "EUR", AmountFrom: money.MustParse("10"), QuoteID: quote.QuoteID,
+		})
+		var apiErr *common.APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "quote_expired" {
+			t.Errorf("Expected quote_expired, got %v", err)
+		}
+	})
+
+	t.Run("Rates", func(t *testing.T) {
+		rates, err := client.Banking.ExchangeRates.List(ctx, &banking.ListRatesRequest{CurrencyPairs: []string{"EUR/USD", "USD/XYZ"}})
+		if err != nil {
+			t.Fatalf("List rates failed: %v", err)
+		}
+		if len(rates.Data.Rates) != 1 || len(rates.Data.UnavailableCurrencyPairs) != 1 {
+			t.Errorf("Expected 1 rate and 1 unavailable pair, got %+v", rates.Data)
+		}
+	})
+}
+
+func TestFakeServerSubAccounts(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "500")
+
+	account, err := client.Connect.Accounts.CreateSubAccount(ctx, &connect.CreateAccountRequest{
+		EntityType: connect.EntityTypeCompany,
+		Company:    &connect.CompanyDetails{LegalName: "Acme Ltd", BusinessType: "LLC"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubAccount failed: %v", err)
+	}
+	if account.Status != "PENDING" {
+		t.Errorf("Expected PENDING account, got %s", account.Status)
+	}
+	if err := server.ActivateAccount(account.AccountID); err != nil {
+		t.Fatalf("ActivateAccount failed: %v", err)
+	}
+
+	_, err = client.Banking.Transfers.Create(ctx, &banking.CreateTransferRequest{
+		SourceAccountID: uqpaytest.MasterAccountID,
+		TargetAccountID: account.AccountID,
+		Currency:        "USD",
+		Amount:          money.MustParse("200"),
+		Reason:          "funding",
+	})
+	if err != nil {
+		t.Fatalf("Create transfer failed: %v", err)
+	}
+
+	merchant := client.WithAccount(account.AccountID)
+	balance, err := merchant.Banking.Balances.Get(ctx, "USD")
+	if err != nil {
+		t.Fatalf("Get sub-account balance failed: %v", err)
+	}
+	if !balance.AvailableBalance.Equal(money.MustParse("200")) {
+		t.Errorf("Expected sub-account balance 200, got %s", balance.AvailableBalance)
+	}
+	if got := server.Balance("USD"); !got.Equal(money.MustParse("300")) {
+		t.Errorf("Expected master balance 300, got %s", got)
+	}
+
+	if _, err := client.WithAccount("acct-unknown").Banking.Balances.Get(ctx, "USD"); err == nil {
+		t.Error("Expected error for unknown sub-account")
+	}
+}
+
+func TestFakeServerFaults(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+
+	t.Run("InjectedError", func(t *testing.T) {
+		server.Inject(uqpaytest.Fault{
+			Method: http.MethodGet, Path: "/v1/balances", Status: http.StatusTooManyRequests,
+			Code: "rate_limit_exceeded", Times: 1,
+		})
+		_, err := client.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1})
+		if !errors.Is(err, common.ErrRateLimited) {
+			t.Errorf("Expected ErrRateLimited, got %v", err)
+		}
+		if _, err := client.Banking.Balances.List(ctx, &banking.ListBalancesRequest{PageSize: 10, PageNumber: 1}); err != nil {
+			t.Errorf("Expected fault to be consumed, got %v", err)
+		}
+	})
+
+	t.Run("RetriedFault", func(t *testing.T) {
+		policy := common.DefaultRetryPolicy()
+		policy.BaseDelay, policy.MaxDelay = time.Millisecond, time.Millisecond
+		retrying, err := server.Client(uqpay.WithRetryPolicy(policy))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		server.Inject(uqpaytest.Fault{Path: "/v1/issuing/products", Status: http.StatusServiceUnavailable, Times: 2})
+		if _, err := retrying.Issuing.Products.List(ctx, &issuing.ListProductsRequest{PageSize: 10, PageNumber: 1}); err != nil {
+			t.Errorf("Expected retries to succeed, got %v", err)
+		}
+	})
+
+	t.Run("RevokedToken", func(t *testing.T) {
+		server.RevokeTokens()
+		if _, err := client.Issuing.Products.List(ctx, &issuing.ListProductsRequest{PageSize: 10, PageNumber: 1}); err != nil {
+			t.Errorf("Expected client to re-authenticate, got %v", err)
+		}
+	})
+
+	t.Run("IdempotentReplay", func(t *testing.T) {
+		server.SetBalance("USD", "100")
+		beneficiaryID := createFakeBeneficiary(t, client)
+		req := &banking.CreatePayoutRequest{
+			BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse("10"),
+			PayoutPurpose: "salary", IdempotencyKey: "payout-1",
+		}
+		first, err := client.Banking.Payouts.Create(ctx, req)
+		if err != nil {
+			t.Fatalf("Create payout failed: %v", err)
+		}
+		second, err := client.Banking.Payouts.Create(ctx, req)
+		if err != nil {
+			t.Fatalf("Replayed payout failed: %v", err)
+		}
+		if first.PayoutID != second.PayoutID {
+			t.Errorf("Expected replay to return %s, got %s", first.PayoutID, second.PayoutID)
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("90")) {
+			t.Errorf("Expected a single debit, balance %s", got)
+		}
+	})
+
+	t.Run("ConcurrentIdempotentRequests", func(t *testing.T) {
+		server.SetBalance("USD", "100")
+		beneficiaryID := createFakeBeneficiary(t, client)
+		req := &banking.CreatePayoutRequest{
+			BeneficiaryID: beneficiaryID, Currency: "USD", Amount: money.MustParse("10"),
+			PayoutPurpose: "salary", IdempotencyKey: "payout-concurrent",
+		}
+		// Slow down the first request so the others arrive while it is being handled
+		var slow sync.Once
+		server.Now = func() time.Time {
+			slow.Do(func() { time.Sleep(20 * time.Millisecond) })
+			return time.Now()
+		}
+		defer func() { server.Now = time.Now }()
+
+		ids := make([]string, 10)
+		var wg sync.WaitGroup
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				payout, err := client.Banking.Payouts.Create(ctx, req)
+				if err != nil {
+					t.Errorf("Create payout failed: %v", err)
+					return
+				}
+				ids[i] = payout.PayoutID
+			}(i)
+		}
+		wg.Wait()
+		for _, id := range ids[1:] {
+			if id != ids[0] {
+				t.Errorf("Expected every request to return %s, got %v", ids[0], ids)
+				break
+			}
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("90")) {
+			t.Errorf("Expected a single debit, balance %s", got)
+		}
+	})
+}
+
+func TestFakeServerFiles(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+
+	uploaded, err := client.Supporting.Files.Upload(ctx, &supporting.UploadFileParams{
+		File: strings.NewReader("%PDF-1.4 test"), FileName: "passport.pdf", Notes: "kyc",
+	})
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if uploaded.FileType != "pdf" || uploaded.Size != 13 || uploaded.Notes != "kyc" {
+		t.Errorf("Unexpected upload response: %+v", uploaded)
+	}
+	if data, ok := server.File(uploaded.FileID); !ok || string(data) != "%PDF-1.4 test" {
+		t.Errorf("Expected stored file content, got %q", data)
+	}
+
+	links, err := client.Supporting.Files.GetDownloadLinks(ctx, &supporting.DownloadLinksRequest{FileIDs: []string{uploaded.FileID, "missing"}})
+	if err != nil {
+		t.Fatalf("GetDownloadLinks failed: %v", err)
+	}
+	if len(links.Files) != 1 || len(links.AbsentFiles) != 1 {
+		t.Errorf("Expected 1 file and 1 absent file, got %+v", links)
+	}
+}
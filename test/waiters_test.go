@@ -0,0 +1,144 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+	"github.com/jackillll/uqpay-sdk-go/uqpaytest"
+)
+
+var fastWait = &common.WaitOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond, Timeout: time.Second}
+
+func createFakeCard(t *testing.T, client *uqpay.Client) *issuing.CardCreationResponse {
+	t.Helper()
+	ctx := context.Background()
+	holder, err := client.Issuing.Cardholders.Create(ctx, &issuing.CreateCardholderRequest{
+		Email: "waiter@example.com", FirstName: "Wait", LastName: "Er", CountryCode: "SG",
+	})
+	if err != nil {
+		t.Fatalf("Create cardholder failed: %v", err)
+	}
+	card, err := client.Issuing.Cards.Create(ctx, &issuing.CreateCardRequest{
+		CardCurrency: "USD", CardholderID: holder.CardholderID, CardProductID: uqpaytest.ProductID,
+	})
+	if err != nil {
+		t.Fatalf("Create card failed: %v", err)
+	}
+	return card
+}
+
+func TestWaitForOrder(t *testing.T) {
+	client, server := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	server.SetBalance("USD", "100")
+	card := createFakeCard(t, client)
+
+	t.Run("Success", func(t *testing.T) {
+		order, err := client.Issuing.Cards.WaitForOrder(ctx, card.CardOrderID, fastWait)
+		if err != nil {
+			t.Fatalf("WaitForOrder failed: %v", err)
+		}
+		if order.OrderStatus != issuing.OrderStatusSuccess {
+			t.Errorf("Expected SUCCESS, got %s", order.OrderStatus)
+		}
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+
+		recharge, err := client.Issuing.Cards.Recharge(ctx, card.CardID, &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("40"))})
+		if err != nil {
+			t.Fatalf("Recharge failed: %v", err)
+		}
+		if err := server.FailCardOrder(recharge.CardOrderID); err != nil {
+			t.Fatalf("FailCardOrder failed: %v", err)
+		}
+
+		_, err = client.Issuing.Cards.WaitForOrder(ctx, recharge.CardOrderID, fastWait)
+		var orderErr *issuing.OrderError
+		if !errors.As(err, &orderErr) {
+			t.Fatalf("Expected OrderError, got %v", err)
+		}
+		if !errors.Is(err, issuing.ErrOrderFailed) {
+			t.Errorf("Expected ErrOrderFailed, got %v", err)
+		}
+		if orderErr.Order == nil || orderErr.Order.OrderStatus != issuing.OrderStatusFailed {
+			t.Errorf("Expected last order FAILED, got %+v", orderErr.Order)
+		}
+		if got := server.Balance("USD"); !got.Equal(money.MustParse("100")) {
+			t.Errorf("Expected refunded balance 100, got %s", got)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+
+		recharge, err := client.Issuing.Cards.Recharge(ctx, card.CardID, &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("10"))})
+		if err != nil {
+			t.Fatalf("Recharge failed: %v", err)
+		}
+		opts := &common.WaitOptions{Interval: time.Millisecond, Timeout: 30 * time.Millisecond}
+		_, err = client.Issuing.Cards.WaitForOrder(ctx, recharge.CardOrderID, opts)
+		if !errors.Is(err, common.ErrWaitTimeout) {
+			t.Fatalf("Expected ErrWaitTimeout, got %v", err)
+		}
+		var orderErr *issuing.OrderError
+		if errors.As(err, &orderErr) && (orderErr.Order == nil || orderErr.Order.OrderStatus != issuing.OrderStatusProcessing) {
+			t.Errorf("Expected last order PROCESSING, got %+v", orderErr.Order)
+		}
+	})
+
+	t.Run("ContextCancelled", func(t *testing.T) {
+		server.SetAutoAdvance(false)
+		defer server.SetAutoAdvance(true)
+
+		recharge, err := client.Issuing.Cards.Recharge(ctx, card.CardID, &issuing.CardOrderRequest{Amount: money.Number(money.MustParse("10"))})
+		if err != nil {
+			t.Fatalf("Recharge failed: %v", err)
+		}
+		cancelled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err = client.Issuing.Cards.WaitForOrder(cancelled, recharge.CardOrderID, fastWait)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context error, got %v", err)
+		}
+	})
+}
+
+func TestWaitForCardStatus(t *testing.T) {
+	client, _ := uqpaytest.NewClient(t)
+	ctx := context.Background()
+	card := createFakeCard(t, client)
+
+	t.Run("Active", func(t *testing.T) {
+		got, err := client.Issuing.Cards.WaitForCardStatus(ctx, card.CardID, issuing.CardStatusActive, fastWait)
+		if err != nil {
+			t.Fatalf("WaitForCardStatus failed: %v", err)
+		}
+		if got.CardStatus != issuing.CardStatusActive {
+			t.Errorf("Expected ACTIVE, got %s", got.CardStatus)
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		if _, err := client.Issuing.Cards.UpdateStatus(ctx, card.CardID, &issuing.UpdateCardStatusRequest{CardStatus: issuing.CardStatusCancelled}); err != nil {
+			t.Fatalf("UpdateStatus failed: %v", err)
+		}
+		_, err := client.Issuing.Cards.WaitForCardStatus(ctx, card.CardID, issuing.CardStatusFrozen, fastWait)
+		var statusErr *issuing.CardStatusError
+		if !errors.As(err, &statusErr) || !errors.Is(err, issuing.ErrCardStatusUnreachable) {
+			t.Fatalf("Expected CardStatusError wrapping ErrCardStatusUnreachable, got %v", err)
+		}
+		if statusErr.Card == nil || statusErr.Card.CardStatus != issuing.CardStatusCancelled {
+			t.Errorf("Expected last card CANCELLED, got %+v", statusErr.Card)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/webhooks"
+)
+
+const webhookSecret = "whsec_test"
+
+func newWebhookRequest(t *testing.T, body string, ts time.Time, secret string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(secret, ts.Unix(), []byte(body)))
+	return req
+}
+
+func TestWebhookVerify(t *testing.T) {
+	body := []byte(`{"event_id":"evt_1"}`)
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := webhooks.Sign(webhookSecret, now.Unix(), body)
+
+	t.Run("ValidSignature", func(t *testing.T) {
+		if err := webhooks.Verify(webhookSecret, body, sig, ts, time.Minute, now); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("RotatedSignatures", func(t *testing.T) {
+		header := "v1=deadbeef, v1=" + sig
+		if err := webhooks.Verify(webhookSecret, body, header, ts, time.Minute, now); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		err := webhooks.Verify("other", body, sig, ts, time.Minute, now)
+		if !errors.Is(err, webhooks.ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("TamperedBody", func(t *testing.T) {
+		err := webhooks.Verify(webhookSecret, []byte(`{"event_id":"evt_2"}`), sig, ts, time.Minute, now)
+		if !errors.Is(err, webhooks.ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("OutsideReplayWindow", func(t *testing.T) {
+		err := webhooks.Verify(webhookSecret, body, sig, ts, time.Minute, now.Add(2*time.Minute))
+		if !errors.Is(err, webhooks.ErrTimestampExpired) {
+			t.Errorf("Expected ErrTimestampExpired, got %v", err)
+		}
+	})
+
+	t.Run("MissingHeaders", func(t *testing.T) {
+		err := webhooks.Verify(webhookSecret, body, "", ts, time.Minute, now)
+		if !errors.Is(err, webhooks.ErrMissingSignature) {
+			t.Errorf("Expected ErrMissingSignature, got %v", err)
+		}
+	})
+
+	t.Run("EmptySecret", func(t *testing.T) {
+		unsigned := webhooks.Sign("", now.Unix(), body)
+		err := webhooks.Verify("", body, unsigned, ts, time.Minute, now)
+		if !errors.Is(err, webhooks.ErrMissingSecret) {
+			t.Errorf("Expected ErrMissingSecret, got %v", err)
+		}
+	})
+
+	t.Run("InvalidTimestamp", func(t *testing.T) {
+		err := webhooks.Verify(webhookSecret, body, sig, "yesterday", time.Minute, now)
+		if !errors.Is(err, webhooks.ErrInvalidTimestamp) {
+			t.Errorf("Expected ErrInvalidTimestamp, got %v", err)
+		}
+	})
+}
+
+func TestWebhookHandler(t *testing.T) {
+	payoutBody := `{"event_id":"evt_1","event_type":"banking.payout.status_changed","create_time":"2024-01-01T00:00:00Z",` +
+		`"data":{"payout_id":"po_1","currency":"USD","amount":"100.50","payout_status":"COMPLETED"}}`
+
+	t.Run("DispatchesTypedEvent", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		var got *webhooks.PayoutEvent
+		h.OnPayout(func(ctx context.Context, e *webhooks.PayoutEvent) error {
+			got = e
+			return nil
+		})
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, time.Now(), webhookSecret))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if got == nil {
+			t.Fatal("Expected payout handler to be called")
+		}
+		if got.EventID != "evt_1" || got.Payout.PayoutID != "po_1" || got.Payout.PayoutStatus != "COMPLETED" {
+			t.Errorf("Unexpected event: %+v", got)
+		}
+		if got.Payout.Amount.String() != "100.50" {
+			t.Errorf("Expected amount 100.50, got %s", got.Payout.Amount)
+		}
+	})
+
+	t.Run("CardTransactionEvents", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		var ids []string
+		h.OnCardTransaction(func(ctx context.Context, e *webhooks.CardTransactionEvent) error {
+			ids = append(ids, e.Transaction.TransactionID)
+			return nil
+		})
+
+		for _, eventType := range []string{webhooks.EventCardTransactionCreated, webhooks.EventCardTransactionUpdated} {
+			body := `{"event_id":"evt","event_type":"` + eventType + `","data":{"transaction_id":"txn_1","transaction_amount":"9.99"}}`
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newWebhookRequest(t, body, time.Now(), webhookSecret))
+			if rec.Code != http.StatusOK {
+				t.Errorf("Expected status 200 for %s, got %d", eventType, rec.Code)
+			}
+		}
+		if len(ids) != 2 {
+			t.Errorf("Expected 2 transaction events, got %d", len(ids))
+		}
+	})
+
+	t.Run("RejectsBadSignature", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, time.Now(), "wrong"))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rec.Code)
+		}
+	})
+
+	t.Run("RejectsReplay", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, time.Now().Add(-time.Hour), webhookSecret))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rec.Code)
+		}
+	})
+
+	t.Run("RejectsNonPost", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rec.Code)
+		}
+	})
+
+	t.Run("MalformedEvent", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, `{"event_id":"evt_1"}`, time.Now(), webhookSecret))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("UndecodableDataReturns400", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		h.OnPayout(func(ctx context.Context, e *webhooks.PayoutEvent) error { return nil })
+		body := `{"event_id":"evt_1","event_type":"banking.payout.status_changed","data":{"amount":"not a number"}}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, body, time.Now(), webhookSecret))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("EmptySecretReturns500", func(t *testing.T) {
+		h := webhooks.NewHandler("")
+		called := false
+		h.OnPayout(func(ctx context.Context, e *webhooks.PayoutEvent) error {
+			called = true
+			return nil
+		})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, time.Now(), ""))
+		if rec.Code != http.StatusInternalServerError || called {
+			t.Errorf("Expected status 500 without dispatch, got %d (dispatched: %v)", rec.Code, called)
+		}
+	})
+
+	t.Run("HandlerErrorReturns500", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		h.OnPayout(func(ctx context.Context, e *webhooks.PayoutEvent) error {
+			return errors.New("database unavailable")
+		})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, time.Now(), webhookSecret))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", rec.Code)
+		}
+	})
+
+	t.Run("CustomScheme", func(t *testing.T) {
+		scheme := &webhooks.Scheme{
+			SignatureHeader: "x-signature",
+			TimestampHeader: "x-timestamp",
+			Payload: func(timestamp int64, body []byte) []byte {
+				return append([]byte(strconv.FormatInt(timestamp, 10)), body...)
+			},
+		}
+		h := webhooks.NewHandler(webhookSecret)
+		h.Scheme = scheme
+		var called bool
+		h.OnPayout(func(ctx context.Context, e *webhooks.PayoutEvent) error {
+			called = true
+			return nil
+		})
+
+		now := time.Now()
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(payoutBody))
+		req.Header.Set("x-timestamp", strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set("x-signature", scheme.Sign(webhookSecret, now.Unix(), []byte(payoutBody)))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || !called {
+			t.Errorf("Expected status 200 and a dispatched event, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		// A request signed with the default scheme does not verify
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, now, webhookSecret))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for the default headers, got %d", rec.Code)
+		}
+	})
+
+	t.Run("UnhandledEventUsesFallback", func(t *testing.T) {
+		h := webhooks.NewHandler(webhookSecret)
+		var fallbackType string
+		h.Fallback = func(ctx context.Context, e *webhooks.Event) error {
+			fallbackType = e.EventType
+			return nil
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newWebhookRequest(t, payoutBody, time.Now(), webhookSecret))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rec.Code)
+		}
+		if fallbackType != webhooks.EventPayoutStatusChanged {
+			t.Errorf("Expected fallback for %s, got %q", webhooks.EventPayoutStatusChanged, fallbackType)
+		}
+	})
+}
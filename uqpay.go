@@ -1,8 +1,7 @@
 package uqpay
 
 import (
-	"net/http"
-	"time"
+	"errors"
 
 	"github.com/jackillll/uqpay-sdk-go/auth"
 	"github.com/jackillll/uqpay-sdk-go/banking"
@@ -13,54 +12,146 @@ import (
 	"github.com/jackillll/uqpay-sdk-go/supporting"
 )
 
+// Errors returned by NewClient for invalid arguments
+var (
+	ErrMissingCredentials = errors.New("uqpay: client ID and API key are required")
+	ErrMissingEnvironment = errors.New("uqpay: environment is required")
+)
+
+// DefaultUserAgent is the User-Agent sent when WithUserAgent is not given
+const DefaultUserAgent = "uqpay-sdk-go/" + Version
+
 // Client is the main UQPAY SDK client
 type Client struct {
 	Issuing    *issuing.Client
 	Banking    *banking.Client
 	Connect    *connect.Client
 	Supporting *supporting.Client
+
+	apiClient      *common.APIClient
+	filesAPIClient *common.APIClient
 }
 
-// NewClient creates a new UQPAY client
-func NewClient(clientID, apiKey string, env *configuration.Environment) (*Client, error) {
-	config := &configuration.Configuration{
-		ClientID:    clientID,
-		APIKey:      apiKey,
-		Environment: env,
-		HTTPClient:  &http.Client{Timeout: 15 * time.Second},
+// NewClient creates a new UQPAY client. Options apply to both the main API client and the
+// Files API client.
+func NewClient(clientID, apiKey string, env *configuration.Environment, opts ...Option) (*Client, error) {
+	o := &options{userAgent: DefaultUserAgent}
+	for _, opt := range opts {
+		opt(o)
 	}
 
-	// Create token provider
-	tokenProvider := auth.NewTokenProvider(
-		env.BaseURL,
-		clientID,
-		apiKey,
-		config.HTTPClient,
-	)
-
-	// Create API client for main APIs
-	apiClient := common.NewAPIClient(config, tokenProvider)
-
-	// Create separate configuration for Files API (different base URL)
-	filesConfig := &configuration.Configuration{
-		ClientID:    clientID,
-		APIKey:      apiKey,
-		Environment: &configuration.Environment{BaseURL: env.FilesBaseURL},
-		HTTPClient:  &http.Client{Timeout: 15 * time.Second},
+	if env == nil {
+		return nil, ErrMissingEnvironment
+	}
+	if o.tokenProvider == nil && (clientID == "" || apiKey == "") {
+		return nil, ErrMissingCredentials
+	}
+
+	baseURL := env.BaseURL
+	if o.baseURL != "" {
+		baseURL = o.baseURL
+	}
+	filesBaseURL := env.FilesBaseURL
+	if o.filesBaseURL != "" {
+		filesBaseURL = o.filesBaseURL
 	}
-	filesTokenProvider := auth.NewTokenProvider(
-		env.FilesBaseURL,
-		clientID,
-		apiKey,
-		filesConfig.HTTPClient,
-	)
-	filesAPIClient := common.NewAPIClient(filesConfig, filesTokenProvider)
-
-	// Initialize service clients
+	httpClient := o.resolveHTTPClient()
+
+	// The main and Files token providers share one token through the store
+	tokenProvider, filesTokenProvider := o.tokenProvider, o.tokenProvider
+	if tokenProvider == nil {
+		tokenStore := o.tokenStore
+		if tokenStore == nil {
+			tokenStore = auth.NewMemoryTokenStore()
+		}
+		key := auth.TokenKey(clientID, baseURL)
+		tokenProvider = auth.NewTokenProviderWithStoreKey(baseURL, clientID, apiKey, httpClient, tokenStore, key)
+		filesTokenProvider = auth.NewTokenProviderWithStoreKey(filesBaseURL, clientID, apiKey, httpClient, tokenStore, key)
+	}
+
+	newAPIClient := func(url string, tokenProvider common.TokenProvider) *common.APIClient {
+		config := &configuration.Configuration{
+			ClientID:    clientID,
+			APIKey:      apiKey,
+			Environment: &configuration.Environment{BaseURL: url, FilesBaseURL: filesBaseURL},
+			HTTPClient:  httpClient,
+		}
+
+		apiClient := common.NewAPIClient(config, tokenProvider)
+		apiClient.UserAgent = o.userAgent
+		if o.retryPolicy != nil {
+			apiClient.RetryPolicy = o.retryPolicy
+		}
+		apiClient.Use(o.middleware...)
+		if o.logger != nil {
+			apiClient.Use(common.LoggingMiddleware(o.logger))
+		}
+		if o.accountID != "" {
+			apiClient = apiClient.WithAccount(o.accountID)
+		}
+		return apiClient
+	}
+
+	apiClient := newAPIClient(baseURL, tokenProvider)
+	filesAPIClient := newAPIClient(filesBaseURL, filesTokenProvider) // Files API has a different base URL
+
+	return newServiceClients(apiClient, filesAPIClient), nil
+}
+
+// newServiceClients initializes the service clients
+func newServiceClients(apiClient, filesAPIClient *common.APIClient) *Client {
 	return &Client{
-		Issuing:    issuing.NewClient(apiClient),
-		Banking:    banking.NewClient(apiClient),
-		Connect:    connect.NewClient(apiClient),
-		Supporting: supporting.NewClient(filesAPIClient), // Use separate client for Files API
-	}, nil
+		Issuing:        issuing.NewClient(apiClient),
+		Banking:        banking.NewClient(apiClient),
+		Connect:        connect.NewClient(apiClient),
+		Supporting:     supporting.NewClient(filesAPIClient), // Use separate client for Files API
+		apiClient:      apiClient,
+		filesAPIClient: filesAPIClient,
+	}
+}
+
+// WithAccount returns a client whose every request, including Files API requests, acts on
+// behalf of a Connect sub-account. The scoped client shares credentials, tokens and settings
+// with c, so one credential can manage many sub-accounts. An empty ID scopes back to the
+// master account. For a single call, use common.WithAccountID on the context instead.
+//
+//	merchant := client.WithAccount(subAccount.AccountID)
+//	balances, err := merchant.Banking.Balances.List(ctx, &banking.ListBalancesRequest{})
+func (c *Client) WithAccount(accountID string) *Client {
+	return newServiceClients(c.apiClient.WithAccount(accountID), c.filesAPIClient.WithAccount(accountID))
+}
+
+// NewClientFromConfig creates a client from a Configuration, e.g. one loaded with
+// configuration.FromEnv or configuration.FromFile. Options are applied after the
+// configuration and take precedence over it.
+func NewClientFromConfig(config *configuration.Configuration, opts ...Option) (*Client, error) {
+	if config == nil {
+		return nil, ErrMissingEnvironment
+	}
+
+	var configOpts []Option
+	if config.HTTPClient != nil {
+		configOpts = append(configOpts, WithHTTPClient(config.HTTPClient))
+	}
+	if config.Timeout > 0 {
+		configOpts = append(configOpts, WithTimeout(config.Timeout))
+	}
+	if config.Retry != nil {
+		policy := common.DefaultRetryPolicy()
+		if config.Retry.MaxAttempts > 0 {
+			policy.MaxAttempts = config.Retry.MaxAttempts
+		}
+		if config.Retry.BaseDelay > 0 {
+			policy.BaseDelay = config.Retry.BaseDelay
+		}
+		if config.Retry.MaxDelay > 0 {
+			policy.MaxDelay = config.Retry.MaxDelay
+		}
+		configOpts = append(configOpts, WithRetryPolicy(policy))
+	}
+	if config.AccountID != "" {
+		configOpts = append(configOpts, WithAccountID(config.AccountID))
+	}
+
+	return NewClient(config.ClientID, config.APIKey, config.Environment, append(configOpts, opts...)...)
 }
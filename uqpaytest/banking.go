@@ -0,0 +1,1041 @@
+package uqpaytest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// MasterAccountID identifies the master account in transfers
+const MasterAccountID = "acct_master"
+
+// QuoteLifetime is how long conversion quotes stay valid
+const QuoteLifetime = 30 * time.Second
+
+type ownedBalanceTxn struct {
+	owner string
+	banking.BalanceTransaction
+}
+
+type payoutState struct {
+	owner string
+	banking.PayoutDetailResponse
+	reference string
+}
+
+type transferState struct {
+	owner string
+	banking.Transfer
+}
+
+type conversionState struct {
+	owner string
+	banking.Conversion
+}
+
+type quoteState struct {
+	owner     string
+	quote     banking.CreateQuoteResponse
+	expiresAt time.Time
+	used      bool
+}
+
+type beneficiaryState struct {
+	owner string
+	banking.Beneficiary
+}
+
+type depositState struct {
+	owner string
+	banking.Deposit
+}
+
+type virtualAccountState struct {
+	owner string
+	banking.VirtualAccount
+}
+
+// SetBalance sets the available balance of the master account in a currency
+func (s *Server) SetBalance(currency, amount string) {
+	s.SetAccountBalance("", currency, amount)
+}
+
+// SetAccountBalance sets the available balance of a sub-account in a currency.
+// An empty account ID is the master account.
+func (s *Server) SetAccountBalance(accountID, currency, amount string) {
+	value := money.MustParse(amount)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.balance(accountID, currency)
+	b.AvailableBalance = fixed(value, b.Currency)
+	b.UpdateTime = s.timestamp()
+}
+
+// Balance returns the available balance of the master account in a currency
+func (s *Server) Balance(currency string) money.Decimal {
+	return s.AccountBalance("", currency)
+}
+
+// AccountBalance returns the available balance of an account in a currency
+func (s *Server) AccountBalance(accountID, currency string) money.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.balances[balanceKey(accountID, currency)]; ok {
+		return b.AvailableBalance
+	}
+	return money.Zero
+}
+
+// SetRate sets the exchange rate used for conversions from one currency to another
+func (s *Server) SetRate(from, to, rate string) {
+	money.MustParse(rate)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates[strings.ToUpper(from)+"/"+strings.ToUpper(to)] = rate
+}
+
+// SetQuoteMarkup sets the fraction taken off the published rate for quotes and conversions,
+// e.g. "0.01" quotes 1% below the rates returned by the exchange rates endpoint. Zero by default.
+func (s *Server) SetQuoteMarkup(markup string) {
+	value := money.MustParse(markup)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quoteMarkup = value
+}
+
+// SetPayoutFee sets the fee charged on every new payout, zero by default
+func (s *Server) SetPayoutFee(fee string) {
+	value := money.MustParse(fee)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payoutFee = value
+}
+
+// SetAutoAdvance controls whether pending resources advance one status each time they are
+// retrieved (the default). With auto-advance off, statuses only change through helpers
+// such as CompletePayout and FailPayout.
+func (s *Server) SetAutoAdvance(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manual = !enabled
+}
+
+// CompletePayout moves a pending or processing payout to COMPLETED
+func (s *Server) CompletePayout(payoutID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, err := s.openPayout(payoutID)
+	if err != nil {
+		return err
+	}
+	s.settlePayout(p, "COMPLETED", "")
+	return nil
+}
+
+// FailPayout moves a pending or processing payout to FAILED and refunds the amount and fee
+func (s *Server) FailPayout(payoutID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, err := s.openPayout(payoutID)
+	if err != nil {
+		return err
+	}
+	s.settlePayout(p, "FAILED", reason)
+	return nil
+}
+
+// FailConversion moves a pending conversion to FAILED and refunds the sold currency
+func (s *Server) FailConversion(conversionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conversions {
+		if c.ConversionID != conversionID {
+			continue
+		}
+		if c.ConversionStatus != "PENDING" {
+			return fmt.Errorf("uqpaytest: conversion %s is %s", conversionID, c.ConversionStatus)
+		}
+		c.ConversionStatus = "FAILED"
+		s.credit(c.owner, c.CurrencyFrom, c.AmountFrom, "REFUND", c.ConversionID, "conversion "+c.ShortReferenceID+" failed")
+		return nil
+	}
+	return fmt.Errorf("uqpaytest: conversion %s not found", conversionID)
+}
+
+// AddDeposit simulates an incoming deposit to the master account, crediting its balance
+func (s *Server) AddDeposit(currency, amount, payerName string) *banking.Deposit {
+	value := money.MustParse(amount)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timestamp()
+	d := &depositState{
+		Deposit: banking.Deposit{
+			DepositID:        s.nextID("deposit"),
+			ShortReferenceID: s.shortRef("D"),
+			Currency:         strings.ToUpper(currency),
+			Amount:           fixed(value, currency),
+			DepositStatus:    "COMPLETED",
+			PaymentMethod:    "LOCAL",
+			PayerName:        payerName,
+			CreateTime:       now,
+			CompletedTime:    now,
+		},
+	}
+	s.deposits = append(s.deposits, d)
+	s.credit("", d.Currency, d.Amount, "DEPOSIT", d.DepositID, "deposit from "+payerName)
+	result := d.Deposit
+	return &result
+}
+
+func balanceKey(owner, currency string) string {
+	return owner + "/" + strings.ToUpper(currency)
+}
+
+// balance returns the balance record for owner and currency, creating an empty one. s.mu is held.
+func (s *Server) balance(owner, currency string) *banking.Balance {
+	key := balanceKey(owner, currency)
+	if b, ok := s.balances[key]; ok {
+		return b
+	}
+	now := s.timestamp()
+	currency = strings.ToUpper(currency)
+	zero := fixed(money.Zero, currency)
+	b := &banking.Balance{
+		BalanceID:        s.nextID("balance"),
+		Currency:         currency,
+		AvailableBalance: zero,
+		PrepaidBalance:   zero,
+		MarginBalance:    zero,
+		FrozenBalance:    zero,
+		BalanceStatus:    "ACTIVE",
+		CreateTime:       now,
+		UpdateTime:       now,
+	}
+	s.balances[key] = b
+	return b
+}
+
+// debit removes amount from a balance and records a transaction. s.mu is held.
+func (s *Server) debit(owner, currency string, amount money.Decimal, txnType, ref, description string) *apiError {
+	b := s.balance(owner, currency)
+	if b.AvailableBalance.LessThan(amount) {
+		return errInsufficientBalance(b.Currency)
+	}
+	s.move(owner, b, amount.Neg(), txnType, ref, description)
+	return nil
+}
+
+// credit adds amount to a balance and records a transaction. s.mu is held.
+func (s *Server) credit(owner, currency string, amount money.Decimal, txnType, ref, description string) {
+	s.move(owner, s.balance(owner, currency), amount, txnType, ref, description)
+}
+
+func (s *Server) move(owner string, b *banking.Balance, amount money.Decimal, txnType, ref, description string) {
+	before := b.AvailableBalance
+	b.AvailableBalance = fixed(before.Add(amount), b.Currency)
+	b.UpdateTime = s.timestamp()
+	s.balanceTxns = append(s.balanceTxns, &ownedBalanceTxn{
+		owner: owner,
+		BalanceTransaction: banking.BalanceTransaction{
+			TransactionID:     s.nextID("btxn"),
+			Currency:          b.Currency,
+			Amount:            fixed(amount, b.Currency),
+			TransactionType:   txnType,
+			TransactionStatus: "COMPLETED",
+			BalanceBefore:     before,
+			BalanceAfter:      b.AvailableBalance,
+			Description:       description,
+			CreateTime:        b.UpdateTime,
+			ReferenceID:       ref,
+		},
+	})
+}
+
+// fixed rounds an amount to the currency's minor units, keeping trailing zeros
+func fixed(d money.Decimal, currency string) money.Decimal {
+	return money.MustParse(d.StringFixed(money.MinorUnits(currency)))
+}
+
+func (s *Server) routeBalances(r *request, rest []string) (interface{}, *apiError) {
+	if r.method != http.MethodGet {
+		return nil, errNotFound("route", r.method+" /v1/balances")
+	}
+	switch {
+	case len(rest) == 0:
+		var balances []banking.Balance
+		for _, key := range sortedKeys(s.balances) {
+			if strings.HasPrefix(key, r.owner+"/") {
+				balances = append(balances, *s.balances[key])
+			}
+		}
+		return paginate(r, balances), nil
+	case len(rest) == 1 && rest[0] == "transactions":
+		var txns []banking.BalanceTransaction
+		for _, t := range s.balanceTxns {
+			if t.owner == r.owner &&
+				matches(r.param("currency"), t.Currency) &&
+				matches(r.param("transaction_type"), t.TransactionType) &&
+				matches(r.param("transaction_status"), t.TransactionStatus) &&
+				inRange(t.CreateTime, r.param("start_time"), r.param("end_time")) {
+				txns = append(txns, t.BalanceTransaction)
+			}
+		}
+		return paginate(r, txns), nil
+	case len(rest) == 1:
+		b, ok := s.balances[balanceKey(r.owner, rest[0])]
+		if !ok {
+			return nil, errNotFound("balance", strings.ToUpper(rest[0]))
+		}
+		return *b, nil
+	}
+	return nil, errNotFound("route", "/v1/balances/"+strings.Join(rest, "/"))
+}
+
+func (s *Server) routePayouts(r *request, rest []string) (interface{}, *apiError) {
+	switch {
+	case len(rest) == 0 && r.method == http.MethodPost:
+		return s.createPayout(r)
+	case len(rest) == 0 && r.method == http.MethodGet:
+		var payouts []banking.Payout
+		for _, p := range s.payouts {
+			if p.owner == r.owner &&
+				matches(r.param("payout_status"), p.PayoutStatus) &&
+				matches(r.param("currency"), p.Currency) &&
+				(r.param("beneficiary_id") == "" || p.Beneficiary.BeneficiaryID == r.param("beneficiary_id")) &&
+				inRange(p.CreateTime, r.param("start_time"), r.param("end_time")) {
+				payouts = append(payouts, p.Payout)
+			}
+		}
+		return paginate(r, payouts), nil
+	case len(rest) == 1 && r.method == http.MethodGet:
+		for _, p := range s.payouts {
+			if p.owner == r.owner && p.PayoutID == rest[0] {
+				s.advancePayout(p)
+				return p.PayoutDetailResponse, nil
+			}
+		}
+		return nil, errNotFound("payout", rest[0])
+	}
+	return nil, errNotFound("route", r.method+" /v1/payouts/"+strings.Join(rest, "/"))
+}
+
+func (s *Server) createPayout(r *request) (interface{}, *apiError) {
+	var req banking.CreatePayoutRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(req.Currency) != 3:
+		return nil, errValidation("currency", "currency is required")
+	case req.Amount.Sign() <= 0:
+		return nil, errValidation("amount", "amount must be greater than zero")
+	case req.PayoutPurpose == "":
+		return nil, errValidation("payout_purpose", "payout purpose is required")
+	case req.BeneficiaryID == "" && req.Beneficiary == nil:
+		return nil, errValidation("beneficiary_id", "beneficiary_id or beneficiary is required")
+	}
+
+	var beneficiary banking.PayoutBeneficiary
+	if req.BeneficiaryID != "" {
+		b := s.findBeneficiary(r.owner, req.BeneficiaryID)
+		if b == nil || b.Status == "deleted" {
+			return nil, errNotFound("beneficiary", req.BeneficiaryID)
+		}
+		beneficiary = payoutBeneficiary(b)
+	} else {
+		beneficiary = *req.Beneficiary
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	amount := fixed(req.Amount, currency)
+	fee := fixed(s.payoutFee, currency)
+	if s.balance(r.owner, currency).AvailableBalance.LessThan(amount.Add(fee)) {
+		return nil, errInsufficientBalance(currency)
+	}
+
+	p := &payoutState{
+		owner: r.owner,
+		PayoutDetailResponse: banking.PayoutDetailResponse{
+			Payout: banking.Payout{
+				PayoutID:         s.nextID("payout"),
+				ShortReferenceID: s.shortRef("P"),
+				Currency:         currency,
+				Amount:           amount,
+				Fee:              fee,
+				PayoutPurpose:    req.PayoutPurpose,
+				PayoutStatus:     "PENDING",
+				Beneficiary:      beneficiary,
+				CreateTime:       s.timestamp(),
+			},
+		},
+		reference: req.Reference,
+	}
+	s.debit(r.owner, currency, amount, "PAYOUT", p.PayoutID, "payout "+p.ShortReferenceID)
+	if fee.Sign() > 0 {
+		s.debit(r.owner, currency, fee, "FEE", p.PayoutID, "payout fee "+p.ShortReferenceID)
+	}
+	s.payouts = append(s.payouts, p)
+
+	return banking.CreatePayoutResponse{
+		PayoutID:         p.PayoutID,
+		ShortReferenceID: p.ShortReferenceID,
+		Status:           p.PayoutStatus,
+		CreateTime:       p.CreateTime,
+	}, nil
+}
+
+func payoutBeneficiary(b *beneficiaryState) banking.PayoutBeneficiary {
+	name := strings.TrimSpace(b.FirstName + " " + b.LastName)
+	if b.EntityType == "COMPANY" {
+		name = b.CompanyName
+	}
+	pb := banking.PayoutBeneficiary{BeneficiaryID: b.BeneficiaryID, BeneficiaryName: name}
+	if b.BankDetails != nil {
+		pb.BankDetails = &banking.PayoutBankDetails{
+			AccountNumber: b.BankDetails.AccountNumber,
+			AccountName:   name,
+			BankName:      b.BankDetails.BankName,
+			RoutingNumber: b.BankDetails.RoutingNumber,
+			SwiftCode:     b.BankDetails.BIC,
+			IBAN:          b.BankDetails.IBAN,
+		}
+	}
+	return pb
+}
+
+// advancePayout moves a payout one step towards COMPLETED. s.mu is held.
+func (s *Server) advancePayout(p *payoutState) {
+	if s.manual {
+		return
+	}
+	switch p.PayoutStatus {
+	case "PENDING":
+		p.PayoutStatus = "PROCESSING"
+		p.TransactionDetails = &banking.TransactionDetails{
+			TransactionID:  s.nextID("ptxn"),
+			ProcessingTime: s.timestamp(),
+		}
+	case "PROCESSING":
+		s.settlePayout(p, "COMPLETED", "")
+	}
+}
+
+func (s *Server) openPayout(payoutID string) (*payoutState, error) {
+	for _, p := range s.payouts {
+		if p.PayoutID != payoutID {
+			continue
+		}
+		if p.PayoutStatus != "PENDING" && p.PayoutStatus != "PROCESSING" {
+			return nil, fmt.Errorf("uqpaytest: payout %s is %s", payoutID, p.PayoutStatus)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("uqpaytest: payout %s not found", payoutID)
+}
+
+// settlePayout moves a payout to a final status, refunding failed payouts. s.mu is held.
+func (s *Server) settlePayout(p *payoutState, status, reason string) {
+	now := s.timestamp()
+	p.PayoutStatus = status
+	if p.TransactionDetails == nil {
+		p.TransactionDetails = &banking.TransactionDetails{TransactionID: s.nextID("ptxn"), ProcessingTime: now}
+	}
+	if status == "COMPLETED" {
+		p.CompletedTime = now
+		p.TransactionDetails.SettlementTime = now
+		p.TransactionDetails.ProcessorResponse = "APPROVED"
+		return
+	}
+	p.FailureReason = reason
+	p.TransactionDetails.ProcessorResponse = reason
+	s.credit(p.owner, p.Currency, p.Amount.Add(p.Fee), "REFUND", p.PayoutID, "payout "+p.ShortReferenceID+" failed")
+}
+
+func (s *Server) routeTransfers(r *request, rest []string) (interface{}, *apiError) {
+	switch {
+	case len(rest) == 0 && r.method == http.MethodPost:
+		return s.createTransfer(r)
+	case len(rest) == 0 && r.method == http.MethodGet:
+		var transfers []banking.Transfer
+		for _, t := range s.transfers {
+			if t.owner == r.owner &&
+				matches(r.param("transfer_status"), t.TransferStatus) &&
+				matches(r.param("currency"), t.Currency) &&
+				inRange(t.CreateTime, r.param("start_time"), r.param("end_time")) {
+				transfers = append(transfers, t.Transfer)
+			}
+		}
+		return paginate(r, transfers), nil
+	case len(rest) == 1 && r.method == http.MethodGet:
+		for _, t := range s.transfers {
+			if t.owner == r.owner && t.TransferID == rest[0] {
+				return t.Transfer, nil
+			}
+		}
+		return nil, errNotFound("transfer", rest[0])
+	}
+	return nil, errNotFound("route", r.method+" /v1/transfer/"+strings.Join(rest, "/"))
+}
+
+func (s *Server) createTransfer(r *request) (interface{}, *apiError) {
+	var req banking.CreateTransferRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	switch {
+	case req.SourceAccountID == "":
+		return nil, errValidation("source_account_id", "source account is required")
+	case req.TargetAccountID == "":
+		return nil, errValidation("target_account_id", "target account is required")
+	case req.SourceAccountID == req.TargetAccountID:
+		return nil, errValidation("target_account_id", "source and target accounts must differ")
+	case len(req.Currency) != 3:
+		return nil, errValidation("currency", "currency is required")
+	case req.Amount.Sign() <= 0:
+		return nil, errValidation("amount", "amount must be greater than zero")
+	case req.Reason == "":
+		return nil, errValidation("reason", "reason is required")
+	}
+	source, err := s.accountOwner(req.SourceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.accountOwner(req.TargetAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	amount := fixed(req.Amount, currency)
+	now := s.timestamp()
+	t := &transferState{
+		owner: r.owner,
+		Transfer: banking.Transfer{
+			TransferID:       s.nextID("transfer"),
+			ShortReferenceID: s.shortRef("T"),
+			SourceAccountID:  req.SourceAccountID,
+			TargetAccountID:  req.TargetAccountID,
+			Currency:         currency,
+			Amount:           amount,
+			Reason:           req.Reason,
+			TransferStatus:   "COMPLETED",
+			CreateTime:       now,
+			CompletedTime:    now,
+		},
+	}
+	if err := s.debit(source, currency, amount, "TRANSFER", t.TransferID, "transfer to "+req.TargetAccountID); err != nil {
+		return nil, err
+	}
+	s.credit(target, currency, amount, "TRANSFER", t.TransferID, "transfer from "+req.SourceAccountID)
+	s.transfers = append(s.transfers, t)
+	return banking.CreateTransferResponse{TransferID: t.TransferID, ShortReferenceID: t.ShortReferenceID}, nil
+}
+
+// accountOwner maps an account ID to the owner key used for balances. s.mu is held.
+func (s *Server) accountOwner(accountID string) (string, *apiError) {
+	if accountID == MasterAccountID {
+		return "", nil
+	}
+	if s.findAccount(accountID) == nil {
+		return "", errNotFound("account", accountID)
+	}
+	return accountID, nil
+}
+
+func (s *Server) routeConversions(r *request, rest []string) (interface{}, *apiError) {
+	switch {
+	case len(rest) == 0 && r.method == http.MethodPost:
+		return s.createConversion(r)
+	case len(rest) == 0 && r.method == http.MethodGet:
+		var conversions []banking.Conversion
+		for _, c := range s.conversions {
+			if c.owner == r.owner &&
+				matches(r.param("conversion_status"), c.ConversionStatus) &&
+				matches(r.param("currency_from"), c.CurrencyFrom) &&
+				matches(r.param("currency_to"), c.CurrencyTo) &&
+				inRange(c.CreateTime, r.param("start_time"), r.param("end_time")) {
+				conversions = append(conversions, c.Conversion)
+			}
+		}
+		return paginate(r, conversions), nil
+	case len(rest) == 1 && rest[0] == "quote" && r.method == http.MethodPost:
+		return s.createQuote(r)
+	case len(rest) == 1 && rest[0] == "conversion_dates" && r.method == http.MethodGet:
+		return s.conversionDates(r)
+	case len(rest) == 1 && r.method == http.MethodGet:
+		for _, c := range s.conversions {
+			if c.owner == r.owner && c.ConversionID == rest[0] {
+				if c.ConversionStatus == "PENDING" && !s.manual {
+					s.settleConversion(c)
+				}
+				return c.Conversion, nil
+			}
+		}
+		return nil, errNotFound("conversion", rest[0])
+	}
+	return nil, errNotFound("route", r.method+" /v1/conversion/"+strings.Join(rest, "/"))
+}
+
+// rate returns the exchange rate from one currency to another, inverting the reverse pair when needed
+func (s *Server) rate(from, to string) (money.Decimal, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return money.NewFromInt(1), true
+	}
+	if rate, ok := s.rates[from+"/"+to]; ok {
+		return money.MustParse(rate), true
+	}
+	if rate, ok := s.rates[to+"/"+from]; ok {
+		inverse, err := money.NewFromInt(1).Div(money.MustParse(rate), 6)
+		return inverse, err == nil
+	}
+	return money.Zero, false
+}
+
+func (s *Server) validateConversion(from, to string, amount money.Decimal, settlementDate string) (money.Decimal, *apiError) {
+	switch {
+	case len(from) != 3:
+		return money.Zero, errValidation("currency_from", "currency_from is required")
+	case len(to) != 3:
+		return money.Zero, errValidation("currency_to", "currency_to is required")
+	case strings.EqualFold(from, to):
+		return money.Zero, errValidation("currency_to", "currencies must differ")
+	case amount.Sign() <= 0:
+		return money.Zero, errValidation("amount_from", "amount must be greater than zero")
+	}
+	if settlementDate != "" {
+		if _, err := time.Parse("2006-01-02", settlementDate); err != nil {
+			return money.Zero, errValidation("settlement_date", "settlement date must be YYYY-MM-DD")
+		}
+	}
+	rate, ok := s.rate(from, to)
+	if !ok {
+		return money.Zero, &apiError{status: http.StatusBadRequest, Code: "unsupported_currency_pair", Message: fmt.Sprintf("no rate for %s/%s", from, to)}
+	}
+	if s.quoteMarkup.Sign() != 0 {
+		rate = rate.Mul(money.NewFromInt(1).Sub(s.quoteMarkup)).Round(6)
+	}
+	return rate, nil
+}
+
+func (s *Server) createQuote(r *request) (interface{}, *apiError) {
+	var req banking.CreateQuoteRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	rate, err := s.validateConversion(req.CurrencyFrom, req.CurrencyTo, req.AmountFrom, req.SettlementDate)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.Now().Add(QuoteLifetime)
+	q := &quoteState{
+		owner:     r.owner,
+		expiresAt: expiresAt,
+		quote: banking.CreateQuoteResponse{
+			QuoteID:        s.nextID("quote"),
+			CurrencyFrom:   strings.ToUpper(req.CurrencyFrom),
+			CurrencyTo:     strings.ToUpper(req.CurrencyTo),
+			AmountFrom:     fixed(req.AmountFrom, req.CurrencyFrom),
+			AmountTo:       fixed(req.AmountFrom.Mul(rate), req.CurrencyTo),
+			Rate:           rate,
+			SettlementDate: s.settlementDate(req.SettlementDate),
+			ExpiresAt:      expiresAt.UTC().Format(time.RFC3339),
+		},
+	}
+	s.quotes[q.quote.QuoteID] = q
+	return q.quote, nil
+}
+
+func (s *Server) createConversion(r *request) (interface{}, *apiError) {
+	var req banking.CreateConversionRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	rate, err := s.validateConversion(req.CurrencyFrom, req.CurrencyTo, req.AmountFrom, req.SettlementDate)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := strings.ToUpper(req.CurrencyFrom), strings.ToUpper(req.CurrencyTo)
+	amountFrom := fixed(req.AmountFrom, from)
+	amountTo := fixed(amountFrom.Mul(rate), to)
+	settlementDate := s.settlementDate(req.SettlementDate)
+
+	var quote *quoteState
+	if req.QuoteID != "" {
+		quote = s.quotes[req.QuoteID]
+		switch {
+		case quote == nil || quote.owner != r.owner:
+			return nil, errNotFound("quote", req.QuoteID)
+		case quote.used:
+			return nil, &apiError{status: http.StatusConflict, Code: "quote_already_used", Message: "quote " + req.QuoteID + " has already been used"}
+		case !s.Now().Before(quote.expiresAt):
+			return nil, &apiError{status: http.StatusBadRequest, Code: "quote_expired", Message: "quote " + req.QuoteID + " has expired"}
+		case quote.quote.CurrencyFrom != from || quote.quote.CurrencyTo != to || !quote.quote.AmountFrom.Equal(amountFrom):
+			return nil, errValidation("quote_id", "quote does not match the conversion")
+		}
+		rate, amountTo, settlementDate = quote.quote.Rate, quote.quote.AmountTo, quote.quote.SettlementDate
+	}
+
+	c := &conversionState{
+		owner: r.owner,
+		Conversion: banking.Conversion{
+			ConversionID:     s.nextID("conversion"),
+			ShortReferenceID: s.shortRef("C"),
+			CurrencyFrom:     from,
+			CurrencyTo:       to,
+			AmountFrom:       amountFrom,
+			AmountTo:         amountTo,
+			Rate:             rate,
+			ConversionStatus: "PENDING",
+			CreateTime:       s.timestamp(),
+			SettlementDate:   settlementDate,
+		},
+	}
+	if err := s.debit(r.owner, from, amountFrom, "CONVERSION", c.ConversionID, fmt.Sprintf("conversion %s to %s", from, to)); err != nil {
+		return nil, err
+	}
+	if quote != nil {
+		quote.used = true
+	}
+	s.conversions = append(s.conversions, c)
+	return banking.CreateConversionResponse{ConversionID: c.ConversionID, ShortReferenceID: c.ShortReferenceID}, nil
+}
+
+// settleConversion completes a conversion and credits the bought currency. s.mu is held.
+func (s *Server) settleConversion(c *conversionState) {
+	c.ConversionStatus = "COMPLETED"
+	c.CompletedTime = s.timestamp()
+	s.credit(c.owner, c.CurrencyTo, c.AmountTo, "CONVERSION", c.ConversionID, fmt.Sprintf("conversion %s to %s", c.CurrencyFrom, c.CurrencyTo))
+}
+
+// settlementDate returns the requested date or the next business day from now
+func (s *Server) settlementDate(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return nextBusinessDays(s.Now(), 1)[0].Format("2006-01-02")
+}
+
+// nextBusinessDays returns n weekdays starting today (or the next weekday)
+func nextBusinessDays(from time.Time, n int) []time.Time {
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	days := make([]time.Time, 0, n)
+	for len(days) < n {
+		if wd := day.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			days = append(days, day)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return days
+}
+
+func (s *Server) conversionDates(r *request) (interface{}, *apiError) {
+	from, to := r.param("currency_from"), r.param("currency_to")
+	if _, ok := s.rate(from, to); !ok || from == "" || to == "" {
+		return nil, &apiError{status: http.StatusBadRequest, Code: "unsupported_currency_pair", Message: fmt.Sprintf("no rate for %s/%s", from, to)}
+	}
+	days := nextBusinessDays(s.Now(), 5)
+	dates := make([]banking.ConversionDate, len(days))
+	for i, day := range days {
+		dates[i] = banking.ConversionDate{
+			Date:          day.Format("2006-01-02"),
+			FirstCutoff:   day.Add(10 * time.Hour).Format(time.RFC3339),
+			SecondCutoff:  day.Add(16 * time.Hour).Format(time.RFC3339),
+			OptimizedDate: i == 1,
+		}
+	}
+	return dates, nil
+}
+
+func (s *Server) listRates(r *request) (interface{}, *apiError) {
+	var resp banking.ListRatesResponse
+	resp.Data.LastUpdated = s.timestamp()
+	resp.Data.Rates = []banking.RateItem{}
+	resp.Data.UnavailableCurrencyPairs = []string{}
+
+	pairs := sortedKeys(s.rates)
+	if filter := r.param("currency_pairs"); filter != "" {
+		pairs = strings.Split(filter, ",")
+	}
+	// Pairs may be sent as "USD/EUR" or "USDEUR"; each pair is reported once
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		normalized := strings.ToUpper(strings.TrimSpace(pair))
+		if len(normalized) == 6 && !strings.Contains(normalized, "/") {
+			normalized = normalized[:3] + "/" + normalized[3:]
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+
+		parts := strings.Split(normalized, "/")
+		rate, ok := money.Zero, false
+		if len(parts) == 2 {
+			rate, ok = s.rate(parts[0], parts[1])
+		}
+		if !ok {
+			resp.Data.UnavailableCurrencyPairs = append(resp.Data.UnavailableCurrencyPairs, normalized)
+			continue
+		}
+		resp.Data.Rates = append(resp.Data.Rates, banking.RateItem{
+			CurrencyPair: normalized,
+			BuyPrice:     rate,
+			SellPrice:    rate,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) routeBeneficiaries(r *request, rest []string) (interface{}, *apiError) {
+	switch {
+	case len(rest) == 0 && r.method == http.MethodPost:
+		var req banking.BeneficiaryCreationRequest
+		if err := r.decode(&req); err != nil {
+			return nil, err
+		}
+		if err := validateBeneficiary(&req); err != nil {
+			return nil, err
+		}
+		now := s.timestamp()
+		b := &beneficiaryState{owner: r.owner, Beneficiary: beneficiaryFrom(&req)}
+		b.BeneficiaryID = s.nextID("beneficiary")
+		b.Status = "active"
+		b.CreateTime, b.UpdateTime = now, now
+		s.beneficiaries = append(s.beneficiaries, b)
+		return banking.BeneficiaryCreationResponse{BeneficiaryID: b.BeneficiaryID, Status: b.Status}, nil
+	case len(rest) == 0 && r.method == http.MethodGet:
+		var beneficiaries []banking.Beneficiary
+		for _, b := range s.beneficiaries {
+			status := r.param("status")
+			if b.owner != r.owner || (status == "" && b.Status == "deleted") || !matches(status, b.Status) ||
+				!matches(r.param("currency"), b.Currency) ||
+				!matches(r.param("country"), b.Country) ||
+				!matches(r.param("entity_type"), b.EntityType) {
+				continue
+			}
+			beneficiaries = append(beneficiaries, b.Beneficiary)
+		}
+		return paginate(r, beneficiaries), nil
+	case len(rest) == 1 && rest[0] == "paymentmethods" && r.method == http.MethodGet:
+		return paymentMethods(r.param("currency"), r.param("country")), nil
+	case len(rest) == 1 && rest[0] == "check" && r.method == http.MethodPost:
+		var req banking.BeneficiaryCheckRequest
+		if err := r.decode(&req); err != nil {
+			return nil, err
+		}
+		check := banking.BeneficiaryCreationRequest{
+			EntityType:    "COMPANY",
+			CompanyName:   "check",
+			Currency:      req.Currency,
+			Country:       req.Country,
+			PaymentMethod: req.PaymentMethod,
+			BankDetails:   req.BankDetails,
+			Address:       &banking.Address{FirstLine: "-", City: "-", Country: req.Country},
+		}
+		if err := validateBeneficiary(&check); err != nil {
+			return nil, err
+		}
+		return banking.Beneficiary{
+			Currency:      strings.ToUpper(req.Currency),
+			Country:       strings.ToUpper(req.Country),
+			PaymentMethod: req.PaymentMethod,
+			BankDetails:   req.BankDetails,
+			Status:        "valid",
+		}, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, errNotFound("route", r.method+" /v1/beneficiaries")
+	}
+	b := s.findBeneficiary(r.owner, rest[0])
+	if b == nil {
+		return nil, errNotFound("beneficiary", rest[0])
+	}
+	switch {
+	case len(rest) == 1 && r.method == http.MethodGet:
+		return b.Beneficiary, nil
+	case len(rest) == 1 && r.method == http.MethodPost:
+		var req banking.BeneficiaryCreationRequest
+		if err := r.decode(&req); err != nil {
+			return nil, err
+		}
+		if b.Status == "deleted" {
+			return nil, errInvalidState("beneficiary " + b.BeneficiaryID + " is deleted")
+		}
+		if err := validateBeneficiary(&req); err != nil {
+			return nil, err
+		}
+		updated := beneficiaryFrom(&req)
+		updated.BeneficiaryID, updated.Status, updated.CreateTime = b.BeneficiaryID, b.Status, b.CreateTime
+		updated.UpdateTime = s.timestamp()
+		b.Beneficiary = updated
+		return b.Beneficiary, nil
+	case len(rest) == 2 && rest[1] == "delete" && r.method == http.MethodPost:
+		b.Status = "deleted"
+		b.UpdateTime = s.timestamp()
+		return struct{}{}, nil
+	}
+	return nil, errNotFound("route", r.method+" /v1/beneficiaries/"+strings.Join(rest, "/"))
+}
+
+func (s *Server) findBeneficiary(owner, id string) *beneficiaryState {
+	for _, b := range s.beneficiaries {
+		if b.owner == owner && b.BeneficiaryID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+func validateBeneficiary(req *banking.BeneficiaryCreationRequest) *apiError {
+	switch {
+	case req.EntityType != "INDIVIDUAL" && req.EntityType != "COMPANY":
+		return errValidation("entity_type", "entity type must be INDIVIDUAL or COMPANY")
+	case req.EntityType == "INDIVIDUAL" && (req.FirstName == "" || req.LastName == ""):
+		return errValidation("first_name", "first and last name are required for individuals")
+	case req.EntityType == "COMPANY" && req.CompanyName == "":
+		return errValidation("company_name", "company name is required for companies")
+	case len(req.Currency) != 3:
+		return errValidation("currency", "currency is required")
+	case len(req.Country) != 2:
+		return errValidation("country", "country must be ISO 3166-1 alpha-2")
+	case req.PaymentMethod == "":
+		return errValidation("payment_method", "payment method is required")
+	case req.BankDetails == nil || req.BankDetails.AccountNumber == "":
+		return errValidation("bank_details.account_number", "account number is required")
+	case req.Address == nil || req.Address.FirstLine == "" || req.Address.City == "":
+		return errValidation("address", "address first line and city are required")
+	}
+	return nil
+}
+
+func beneficiaryFrom(req *banking.BeneficiaryCreationRequest) banking.Beneficiary {
+	return banking.Beneficiary{
+		EntityType:    req.EntityType,
+		FirstName:     req.FirstName,
+		LastName:      req.LastName,
+		CompanyName:   req.CompanyName,
+		Currency:      strings.ToUpper(req.Currency),
+		Country:       strings.ToUpper(req.Country),
+		PaymentMethod: req.PaymentMethod,
+		BankDetails:   req.BankDetails,
+		Address:       req.Address,
+		Email:         req.Email,
+		PhoneNumber:   req.PhoneNumber,
+		Reference:     req.Reference,
+	}
+}
+
+func paymentMethods(currency, country string) []banking.PaymentMethod {
+	currency, country = strings.ToUpper(currency), strings.ToUpper(country)
+	return []banking.PaymentMethod{
+		{
+			PaymentMethodID:   "LOCAL",
+			PaymentMethodName: "Local bank transfer",
+			Currency:          currency,
+			Country:           country,
+			RequiredFields:    []string{"account_number", "bank_name"},
+			OptionalFields:    []string{"routing_number", "sort_code"},
+			MinAmount:         decimalPtr("1"),
+			MaxAmount:         decimalPtr("50000"),
+		},
+		{
+			PaymentMethodID:   "SWIFT",
+			PaymentMethodName: "SWIFT wire transfer",
+			Currency:          currency,
+			Country:           country,
+			RequiredFields:    []string{"account_number", "bic"},
+			OptionalFields:    []string{"iban", "bank_address"},
+			MinAmount:         decimalPtr("50"),
+			MaxAmount:         decimalPtr("1000000"),
+		},
+	}
+}
+
+func decimalPtr(s string) *money.Decimal {
+	d := money.MustParse(s)
+	return &d
+}
+
+func (s *Server) routeDeposits(r *request, rest []string) (interface{}, *apiError) {
+	if r.method != http.MethodGet {
+		return nil, errNotFound("route", r.method+" /v1/deposit")
+	}
+	switch len(rest) {
+	case 0:
+		var deposits []banking.Deposit
+		for _, d := range s.deposits {
+			if d.owner == r.owner &&
+				matches(r.param("deposit_status"), d.DepositStatus) &&
+				matches(r.param("currency"), d.Currency) &&
+				inRange(d.CreateTime, r.param("start_time"), r.param("end_time")) {
+				deposits = append(deposits, d.Deposit)
+			}
+		}
+		return paginate(r, deposits), nil
+	case 1:
+		for _, d := range s.deposits {
+			if d.owner == r.owner && d.DepositID == rest[0] {
+				return d.Deposit, nil
+			}
+		}
+		return nil, errNotFound("deposit", rest[0])
+	}
+	return nil, errNotFound("route", "/v1/deposit/"+strings.Join(rest, "/"))
+}
+
+func (s *Server) routeVirtualAccounts(r *request) (interface{}, *apiError) {
+	switch r.method {
+	case http.MethodGet:
+		var accounts []banking.VirtualAccount
+		for _, va := range s.virtual {
+			if va.owner == r.owner {
+				accounts = append(accounts, va.VirtualAccount)
+			}
+		}
+		return paginate(r, accounts), nil
+	case http.MethodPost:
+		var req banking.CreateVirtualAccountRequest
+		if err := r.decode(&req); err != nil {
+			return nil, err
+		}
+		if req.VirtualAccountName == "" {
+			return nil, errValidation("virtual_account_name", "virtual account name is required")
+		}
+		if len(req.Currencies) == 0 {
+			return nil, errValidation("currencies", "at least one currency is required")
+		}
+		va := &virtualAccountState{
+			owner: r.owner,
+			VirtualAccount: banking.VirtualAccount{
+				VirtualAccountID:   s.nextID("va"),
+				VirtualAccountName: req.VirtualAccountName,
+				Status:             "ACTIVE",
+				CreateTime:         s.timestamp(),
+			},
+		}
+		for _, currency := range req.Currencies {
+			s.seq++
+			va.CurrencyBankDetail = append(va.CurrencyBankDetail, banking.CurrencyBankDetail{
+				Currency:        strings.ToUpper(currency),
+				BankName:        "UQPAY Test Bank",
+				BankAddress:     "1 Test Street, Singapore",
+				BankCountryCode: "SG",
+				AccountName:     req.VirtualAccountName,
+				AccountNumber:   fmt.Sprintf("88%08d", s.seq),
+				SwiftCode:       "UQTSSGSG",
+			})
+		}
+		s.virtual = append(s.virtual, va)
+		return va.VirtualAccount, nil
+	}
+	return nil, errNotFound("route", r.method+" /v1/virtual/accounts")
+}
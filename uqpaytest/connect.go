@@ -0,0 +1,149 @@
+package uqpaytest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jackillll/uqpay-sdk-go/connect"
+)
+
+type accountState struct {
+	connect.Account
+	documents []connect.Document
+}
+
+// ActivateAccount moves a PENDING sub-account to ACTIVE
+func (s *Server) ActivateAccount(accountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.findAccount(accountID)
+	if a == nil {
+		return fmt.Errorf("uqpaytest: account %s not found", accountID)
+	}
+	s.activateAccount(a)
+	return nil
+}
+
+func (s *Server) findAccount(id string) *accountState {
+	for _, a := range s.accounts {
+		if a.AccountID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Server) activateAccount(a *accountState) {
+	a.Status = "ACTIVE"
+	a.PayoutsEnabled = true
+	a.ChargesEnabled = true
+	a.Requirements = &connect.AccountRequirements{}
+	a.UpdateTime = s.timestamp()
+	for i := range a.documents {
+		a.documents[i].Status = "verified"
+	}
+}
+
+func (s *Server) routeAccounts(r *request, rest []string) (interface{}, *apiError) {
+	if r.owner != "" {
+		return nil, &apiError{status: http.StatusForbidden, Code: "forbidden", Message: "sub-accounts cannot manage accounts"}
+	}
+
+	switch {
+	case len(rest) == 0 && r.method == http.MethodPost,
+		len(rest) == 1 && rest[0] == "create_accounts" && r.method == http.MethodPost:
+		return s.createAccount(r)
+	case len(rest) == 0 && r.method == http.MethodGet:
+		var accounts []connect.Account
+		for _, a := range s.accounts {
+			if matches(r.param("status"), a.Status) {
+				accounts = append(accounts, a.Account)
+			}
+		}
+		return paginate(r, accounts), nil
+	case len(rest) == 1 && rest[0] == "get_additional" && r.method == http.MethodGet:
+		a := s.findAccount(r.param("account_id"))
+		if a == nil {
+			return nil, errNotFound("account", r.param("account_id"))
+		}
+		return connect.GetAdditionalDocumentsResponse{AccountID: a.AccountID, Documents: a.documents}, nil
+	}
+
+	if len(rest) != 1 {
+		return nil, errNotFound("route", r.method+" /v1/accounts/"+strings.Join(rest, "/"))
+	}
+	a := s.findAccount(rest[0])
+	if a == nil {
+		return nil, errNotFound("account", rest[0])
+	}
+	switch r.method {
+	case http.MethodGet:
+		if a.Status == "PENDING" && !s.manual {
+			s.activateAccount(a)
+		}
+		return a.Account, nil
+	case http.MethodPost:
+		var req connect.UpdateAccountRequest
+		if err := r.decode(&req); err != nil {
+			return nil, err
+		}
+		if req.Individual != nil {
+			if a.EntityType != connect.EntityTypeIndividual {
+				return nil, errValidation("individual", "account is not an individual account")
+			}
+			a.Individual = req.Individual
+		}
+		if req.Company != nil {
+			if a.EntityType != connect.EntityTypeCompany {
+				return nil, errValidation("company", "account is not a company account")
+			}
+			a.Company = req.Company
+		}
+		if req.Metadata != nil {
+			a.Metadata = req.Metadata
+		}
+		a.UpdateTime = s.timestamp()
+		return a.Account, nil
+	}
+	return nil, errNotFound("route", r.method+" /v1/accounts/"+rest[0])
+}
+
+func (s *Server) createAccount(r *request) (interface{}, *apiError) {
+	var req connect.CreateAccountRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	switch {
+	case req.EntityType == connect.EntityTypeIndividual && req.Individual == nil:
+		return nil, errValidation("individual", "individual details are required")
+	case req.EntityType == connect.EntityTypeCompany && req.Company == nil:
+		return nil, errValidation("company", "company details are required")
+	case req.EntityType != connect.EntityTypeIndividual && req.EntityType != connect.EntityTypeCompany:
+		return nil, errValidation("entity_type", "entity type must be INDIVIDUAL or COMPANY")
+	}
+
+	documentType := "identity_document"
+	if req.EntityType == connect.EntityTypeCompany {
+		documentType = "business_registration"
+	}
+	a := &accountState{
+		Account: connect.Account{
+			AccountID:  s.nextID("acct"),
+			EntityType: req.EntityType,
+			Individual: req.Individual,
+			Company:    req.Company,
+			Status:     "PENDING",
+			Requirements: &connect.AccountRequirements{
+				CurrentlyDue: []string{documentType},
+			},
+			Metadata:   req.Metadata,
+			CreateTime: s.timestamp(),
+		},
+		documents: []connect.Document{
+			{Type: documentType, Description: "Proof of identity", Required: true, Status: "required"},
+		},
+	}
+	s.accounts = append(s.accounts, a)
+	return a.Account, nil
+}
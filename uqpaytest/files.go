@@ -0,0 +1,140 @@
+package uqpaytest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/jackillll/uqpay-sdk-go/supporting"
+)
+
+type fileState struct {
+	owner       string
+	info        supporting.UploadFileResponse
+	contentType string
+	data        []byte
+}
+
+// File returns the content of an uploaded file
+func (s *Server) File(fileID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[fileID]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), f.data...), true
+}
+
+func (s *Server) routeFiles(r *request, rest []string) (interface{}, *apiError) {
+	if len(rest) != 1 || r.method != http.MethodPost {
+		return nil, errNotFound("route", r.method+" /v1/files/"+strings.Join(rest, "/"))
+	}
+	switch rest[0] {
+	case "upload":
+		return s.uploadFile(r)
+	case "download_links":
+		var req supporting.DownloadLinksRequest
+		if err := r.decode(&req); err != nil {
+			return nil, err
+		}
+		if len(req.FileIDs) == 0 {
+			return nil, errValidation("file_ids", "at least one file ID is required")
+		}
+		resp := supporting.DownloadLinksResponse{Files: []supporting.FileDownloadInfo{}, AbsentFiles: []string{}}
+		for _, id := range req.FileIDs {
+			f, ok := s.files[id]
+			if !ok || f.owner != r.owner {
+				resp.AbsentFiles = append(resp.AbsentFiles, id)
+				continue
+			}
+			resp.Files = append(resp.Files, supporting.FileDownloadInfo{
+				FileID:   id,
+				FileType: f.info.FileType,
+				FileName: f.info.FileName,
+				Size:     f.info.Size,
+				URL:      s.URL + "/download/" + id,
+			})
+		}
+		return resp, nil
+	}
+	return nil, errNotFound("route", "/v1/files/"+rest[0])
+}
+
+func (s *Server) uploadFile(r *request) (interface{}, *apiError) {
+	mediaType, params, err := mime.ParseMediaType(r.header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, &apiError{status: http.StatusBadRequest, Code: "invalid_request", Message: "expected multipart/form-data"}
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(r.body), params["boundary"]).ReadForm(int64(len(r.body)) + 1)
+	if err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, Code: "invalid_request", Message: "malformed multipart body: " + err.Error()}
+	}
+	defer form.RemoveAll()
+
+	headers := form.File["file"]
+	if len(headers) == 0 {
+		return nil, errValidation("file", "file is required")
+	}
+	header := headers[0]
+	if header.Size > supporting.MaxUploadSize {
+		return nil, &apiError{status: http.StatusRequestEntityTooLarge, Code: "file_too_large", Message: "file exceeds the upload size limit"}
+	}
+	fileType := strings.TrimPrefix(strings.ToLower(path.Ext(header.Filename)), ".")
+	switch fileType {
+	case "jpeg", "jpg", "png", "pdf", "doc", "docx":
+	default:
+		return nil, errValidation("file", "unsupported file type "+fileType)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, Code: "invalid_request", Message: err.Error()}
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, Code: "invalid_request", Message: err.Error()}
+	}
+
+	var notes string
+	if values := form.Value["notes"]; len(values) > 0 {
+		notes = values[0]
+	}
+	f := &fileState{
+		owner: r.owner,
+		info: supporting.UploadFileResponse{
+			CreateTime: s.timestamp(),
+			FileID:     s.nextID("file"),
+			FileName:   header.Filename,
+			FileType:   fileType,
+			Size:       len(data),
+			Notes:      notes,
+		},
+		contentType: header.Header.Get("Content-Type"),
+		data:        data,
+	}
+	s.files[f.info.FileID] = f
+	return f.info, nil
+}
+
+// handleDownload serves the download links returned by /v1/files/download_links
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/download/")
+	s.mu.Lock()
+	f, ok := s.files[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if f.contentType != "" {
+		w.Header().Set("Content-Type", f.contentType)
+	}
+	w.Write(f.data)
+}
@@ -0,0 +1,690 @@
+package uqpaytest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// ProductID is the ID of the card product every fake server starts with
+const ProductID = "product_visa_usd"
+
+const defaultCardBIN = "411111"
+
+type cardholderState struct {
+	owner string
+	issuing.Cardholder
+	phoneNumber string
+}
+
+type cardState struct {
+	owner string
+	issuing.RetrieveCardResponse
+	cvv        string
+	expireDate string
+}
+
+type orderState struct {
+	owner string
+	issuing.CardOrder
+}
+
+type cardTxnState struct {
+	owner string
+	issuing.Transaction
+}
+
+func (s *Server) seedDefaults() {
+	now := s.timestamp()
+	s.products = append(s.products, &issuing.CardProduct{
+		ProductID:     ProductID,
+		ModeType:      "SINGLE",
+		CardBin:       defaultCardBIN,
+		CardForm:      []string{"VIR"},
+		MaxCardQuota:  1000,
+		CardScheme:    "VISA",
+		CardCurrency:  []string{"USD"},
+		ProductStatus: "ENABLED",
+		NoPinPaymentAmount: []issuing.NoPinPaymentLimit{
+			{Amount: money.MustParse("500.00"), Currency: "USD"},
+		},
+		CreateTime: now,
+		UpdateTime: now,
+	})
+	for pair, rate := range map[string]string{
+		"USD/EUR": "0.92",
+		"USD/GBP": "0.79",
+		"USD/SGD": "1.35",
+		"EUR/GBP": "0.86",
+	} {
+		s.rates[pair] = rate
+	}
+}
+
+func (s *Server) routeIssuing(r *request, resource string, rest []string) (interface{}, *apiError) {
+	switch resource {
+	case "cards":
+		return s.routeCards(r, rest)
+	case "cardholders":
+		switch {
+		case len(rest) == 0 && r.method == http.MethodPost:
+			return s.createCardholder(r)
+		case len(rest) == 0 && r.method == http.MethodGet:
+			return paginate(r, s.ownedCardholders(r.owner)), nil
+		case len(rest) == 1 && r.method == http.MethodGet:
+			holder := s.findCardholder(r.owner, rest[0])
+			if holder == nil {
+				return nil, errNotFound("cardholder", rest[0])
+			}
+			return holder.Cardholder, nil
+		}
+	case "transactions":
+		switch {
+		case len(rest) == 0 && r.method == http.MethodGet:
+			var txns []issuing.Transaction
+			for _, t := range s.cardTxns {
+				if t.owner == r.owner && (r.param("card_id") == "" || t.CardID == r.param("card_id")) {
+					txns = append(txns, t.Transaction)
+				}
+			}
+			return paginate(r, txns), nil
+		case len(rest) == 1 && r.method == http.MethodGet:
+			for _, t := range s.cardTxns {
+				if t.owner == r.owner && t.TransactionID == rest[0] {
+					return t.Transaction, nil
+				}
+			}
+			return nil, errNotFound("transaction", rest[0])
+		}
+	case "products":
+		if len(rest) == 0 && r.method == http.MethodGet {
+			products := make([]issuing.CardProduct, len(s.products))
+			for i, p := range s.products {
+				products[i] = *p
+			}
+			return paginate(r, products), nil
+		}
+	}
+	return nil, errNotFound("route", r.method+" /v1/issuing/"+resource)
+}
+
+func (s *Server) routeCards(r *request, rest []string) (interface{}, *apiError) {
+	if len(rest) == 0 {
+		switch r.method {
+		case http.MethodPost:
+			return s.createCard(r)
+		case http.MethodGet:
+			return s.listCards(r), nil
+		}
+		return nil, errNotFound("route", r.method+" /v1/issuing/cards")
+	}
+
+	if r.method == http.MethodPost && len(rest) == 1 {
+		switch rest[0] {
+		case "activate":
+			return s.activateCard(r)
+		case "pin":
+			return s.resetPIN(r)
+		case "assign":
+			return s.assignCard(r)
+		case "bulk":
+			return s.bulkCreateCards(r)
+		}
+	}
+
+	if len(rest) == 2 && rest[1] == "order" && r.method == http.MethodGet {
+		return s.getOrder(r, rest[0])
+	}
+
+	card := s.findCard(r.owner, rest[0])
+	if card == nil {
+		return nil, errNotFound("card", rest[0])
+	}
+	switch {
+	case len(rest) == 1 && r.method == http.MethodGet:
+		if card.CardStatus == "PENDING" && !s.manual {
+			card.CardStatus = "ACTIVE"
+		}
+		return s.cardView(card), nil
+	case len(rest) == 1 && r.method == http.MethodPost:
+		return s.updateCard(r, card)
+	case len(rest) == 2 && rest[1] == "secure" && r.method == http.MethodGet:
+		return issuing.SecureCardInfo{CVV: card.cvv, ExpireDate: card.expireDate, CardNumber: card.CardNumber}, nil
+	case len(rest) == 2 && rest[1] == "status" && r.method == http.MethodPost:
+		return s.updateCardStatus(r, card)
+	case len(rest) == 2 && rest[1] == "recharge" && r.method == http.MethodPost:
+		return s.cardOrder(r, card, "RECHARGE")
+	case len(rest) == 2 && rest[1] == "withdraw" && r.method == http.MethodPost:
+		return s.cardOrder(r, card, "WITHDRAW")
+	}
+	return nil, errNotFound("route", r.method+" /v1/issuing/cards/"+strings.Join(rest, "/"))
+}
+
+func (s *Server) createCardholder(r *request) (interface{}, *apiError) {
+	var req issuing.CreateCardholderRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	switch {
+	case req.Email == "":
+		return nil, errValidation("email", "email is required")
+	case req.FirstName == "":
+		return nil, errValidation("first_name", "first name is required")
+	case req.LastName == "":
+		return nil, errValidation("last_name", "last name is required")
+	case len(req.CountryCode) != 2:
+		return nil, errValidation("country_code", "country code must be ISO 3166-1 alpha-2")
+	}
+	for _, h := range s.cardholders {
+		if h.owner == r.owner && strings.EqualFold(h.Email, req.Email) {
+			return nil, &apiError{status: http.StatusConflict, Code: "duplicate_cardholder", Message: "a cardholder with this email already exists"}
+		}
+	}
+
+	holder := &cardholderState{
+		owner: r.owner,
+		Cardholder: issuing.Cardholder{
+			CardholderID: s.nextID("cardholder"),
+			Email:        req.Email,
+			FirstName:    req.FirstName,
+			LastName:     req.LastName,
+			CountryCode:  req.CountryCode,
+			Status:       "SUCCESS",
+			CreateTime:   s.timestamp(),
+		},
+		phoneNumber: req.PhoneNumber,
+	}
+	s.cardholders = append(s.cardholders, holder)
+	return holder.Cardholder, nil
+}
+
+func (s *Server) ownedCardholders(owner string) []issuing.Cardholder {
+	var holders []issuing.Cardholder
+	for _, h := range s.cardholders {
+		if h.owner == owner {
+			holders = append(holders, h.Cardholder)
+		}
+	}
+	return holders
+}
+
+func (s *Server) findCardholder(owner, id string) *cardholderState {
+	for _, h := range s.cardholders {
+		if h.owner == owner && h.CardholderID == id {
+			return h
+		}
+	}
+	return nil
+}
+
+func (s *Server) findProduct(id string) *issuing.CardProduct {
+	for _, p := range s.products {
+		if p.ProductID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) findCard(owner, id string) *cardState {
+	for _, c := range s.cards {
+		if c.owner == owner && c.CardID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// cardView returns the card with up-to-date cardholder details
+func (s *Server) cardView(card *cardState) issuing.RetrieveCardResponse {
+	view := card.RetrieveCardResponse
+	if holder := s.findCardholder(card.owner, card.Cardholder.CardholderID); holder != nil {
+		count := 0
+		for _, c := range s.cards {
+			if c.owner == card.owner && c.Cardholder.CardholderID == holder.CardholderID {
+				count++
+			}
+		}
+		view.Cardholder = issuing.CardholderInfo{
+			CardholderID:     holder.CardholderID,
+			Email:            holder.Email,
+			NumberOfCards:    count,
+			FirstName:        holder.FirstName,
+			LastName:         holder.LastName,
+			CreateTime:       holder.CreateTime,
+			CardholderStatus: holder.Status,
+		}
+		if holder.CountryCode != "" {
+			view.Cardholder.CountryCode = &holder.CountryCode
+		}
+		if holder.phoneNumber != "" {
+			view.Cardholder.PhoneNumber = &holder.phoneNumber
+		}
+	}
+	return view
+}
+
+func (s *Server) listCards(r *request) *listResponse {
+	var cards []issuing.RetrieveCardResponse
+	for _, c := range s.cards {
+		if c.owner != r.owner ||
+			!matches(r.param("card_status"), c.CardStatus) ||
+			(r.param("cardholder_id") != "" && c.Cardholder.CardholderID != r.param("cardholder_id")) ||
+			(r.param("card_number") != "" && !strings.HasSuffix(c.CardNumber, r.param("card_number"))) {
+			continue
+		}
+		cards = append(cards, s.cardView(c))
+	}
+	return paginate(r, cards)
+}
+
+func (s *Server) createCard(r *request) (interface{}, *apiError) {
+	var req issuing.CreateCardRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	if req.CardholderID == "" {
+		return nil, errValidation("cardholder_id", "cardholder ID is required")
+	}
+	holder := s.findCardholder(r.owner, req.CardholderID)
+	if holder == nil {
+		return nil, errNotFound("cardholder", req.CardholderID)
+	}
+	product := s.findProduct(req.CardProductID)
+	if product == nil {
+		return nil, errNotFound("card product", req.CardProductID)
+	}
+	if product.ProductStatus != "ENABLED" {
+		return nil, errInvalidState("card product " + product.ProductID + " is not enabled")
+	}
+	if !containsFold(product.CardCurrency, req.CardCurrency) {
+		return nil, errValidation("card_currency", fmt.Sprintf("card product does not support %s", req.CardCurrency))
+	}
+
+	card := s.newCard(r.owner, holder, product, strings.ToUpper(req.CardCurrency), product.ModeType, "")
+	if req.CardLimit != nil {
+		card.CardLimit = req.CardLimit.Decimal()
+	}
+	card.SpendingControls = req.SpendingControls
+	card.RiskControls = req.RiskControls
+	card.Metadata = req.Metadata
+
+	order := s.newOrder(r.owner, card, "CREATE", money.Zero)
+	return issuing.CardCreationResponse{
+		CardID:      card.CardID,
+		CardOrderID: order.CardOrderID,
+		CreateTime:  order.CreateTime,
+		CardStatus:  card.CardStatus,
+		OrderStatus: order.OrderStatus,
+	}, nil
+}
+
+// newCard adds a PENDING card; number is generated when empty
+func (s *Server) newCard(owner string, holder *cardholderState, product *issuing.CardProduct, currency, mode, number string) *cardState {
+	bin := defaultCardBIN
+	scheme := "VISA"
+	productID := ""
+	if product != nil {
+		bin, scheme, productID = product.CardBin, product.CardScheme, product.ProductID
+	}
+	if number == "" {
+		s.seq++
+		number = luhnNumber(fmt.Sprintf("%s%09d", bin, s.seq))
+	}
+
+	card := &cardState{
+		owner: owner,
+		RetrieveCardResponse: issuing.RetrieveCardResponse{
+			CardID:             s.nextID("card"),
+			CardBIN:            bin,
+			CardScheme:         scheme,
+			CardCurrency:       currency,
+			CardNumber:         number,
+			FormFactor:         "VIRTUAL",
+			ModeType:           mode,
+			CardProductID:      productID,
+			CardLimit:          money.Zero,
+			AvailableBalance:   money.Zero,
+			NoPINPaymentAmount: money.Zero,
+			CardStatus:         "PENDING",
+			Cardholder:         issuing.CardholderInfo{CardholderID: holder.CardholderID},
+		},
+		cvv:        fmt.Sprintf("%03d", s.seq%1000),
+		expireDate: s.Now().AddDate(3, 0, 0).Format("01/06"),
+	}
+	s.cards = append(s.cards, card)
+	return card
+}
+
+func (s *Server) newOrder(owner string, card *cardState, orderType string, amount money.Decimal) *orderState {
+	now := s.timestamp()
+	order := &orderState{
+		owner: owner,
+		CardOrder: issuing.CardOrder{
+			CardID:       card.CardID,
+			CardOrderID:  s.nextID("order"),
+			OrderType:    orderType,
+			Amount:       amount,
+			CardCurrency: card.CardCurrency,
+			CreateTime:   now,
+			UpdateTime:   now,
+			OrderStatus:  "PROCESSING",
+		},
+	}
+	s.orders = append(s.orders, order)
+	return order
+}
+
+func (s *Server) getOrder(r *request, orderID string) (interface{}, *apiError) {
+	for _, o := range s.orders {
+		if o.owner != r.owner || o.CardOrderID != orderID {
+			continue
+		}
+		if o.OrderStatus == "PROCESSING" && !s.manual {
+			s.completeOrder(o, "SUCCESS")
+		}
+		return o.CardOrder, nil
+	}
+	return nil, errNotFound("card order", orderID)
+}
+
+// completeOrder moves an order to a final status, returning recharged funds when it fails
+func (s *Server) completeOrder(o *orderState, status string) {
+	o.OrderStatus = status
+	o.UpdateTime = s.timestamp()
+	o.CompleteTime = o.UpdateTime
+	if status != "FAILED" {
+		return
+	}
+	card := s.findCard(o.owner, o.CardID)
+	if card == nil {
+		return
+	}
+	switch o.OrderType {
+	case "RECHARGE":
+		card.AvailableBalance = card.AvailableBalance.Sub(o.Amount)
+		s.credit(o.owner, card.CardCurrency, o.Amount, "REFUND", o.CardOrderID, "card recharge failed")
+	case "WITHDRAW":
+		s.debit(o.owner, card.CardCurrency, o.Amount, "ADJUSTMENT", o.CardOrderID, "card withdrawal failed")
+		card.AvailableBalance = card.AvailableBalance.Add(o.Amount)
+	}
+}
+
+func (s *Server) updateCard(r *request, card *cardState) (interface{}, *apiError) {
+	var req issuing.CardUpdateRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	if card.CardStatus == "CANCELLED" {
+		return nil, errInvalidState("card " + card.CardID + " is cancelled")
+	}
+	if req.CardLimit != nil {
+		card.CardLimit = req.CardLimit.Decimal()
+	}
+	if req.NoPINPaymentAmount != nil {
+		card.NoPINPaymentAmount = req.NoPINPaymentAmount.Decimal()
+	}
+	if req.SpendingControls != nil {
+		card.SpendingControls = req.SpendingControls
+	}
+	if req.RiskControls != nil {
+		card.RiskControls = req.RiskControls
+	}
+	if req.Metadata != nil {
+		card.Metadata = req.Metadata
+	}
+
+	order := s.newOrder(r.owner, card, "UPDATE", money.Zero)
+	s.completeOrder(order, "SUCCESS")
+	return issuing.CardUpdatedResponse{
+		CardID:      card.CardID,
+		CardOrderID: order.CardOrderID,
+		CardStatus:  card.CardStatus,
+		OrderStatus: order.OrderStatus,
+	}, nil
+}
+
+func (s *Server) updateCardStatus(r *request, card *cardState) (interface{}, *apiError) {
+	var req issuing.UpdateCardStatusRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	switch req.CardStatus {
+	case "ACTIVE", "FROZEN", "CANCELLED":
+	default:
+		return nil, errValidation("card_status", "card status must be ACTIVE, FROZEN or CANCELLED")
+	}
+	if card.CardStatus == "CANCELLED" {
+		return nil, errInvalidState("card " + card.CardID + " is cancelled")
+	}
+
+	card.CardStatus = req.CardStatus
+	card.UpdateReason = req.UpdateReason
+	order := s.newOrder(r.owner, card, "STATUS_UPDATE", money.Zero)
+	s.completeOrder(order, "SUCCESS")
+	return issuing.CardStatusResponse{
+		CardID:       card.CardID,
+		CardOrderID:  order.CardOrderID,
+		OrderStatus:  order.OrderStatus,
+		UpdateReason: req.UpdateReason,
+	}, nil
+}
+
+func (s *Server) cardOrder(r *request, card *cardState, orderType string) (interface{}, *apiError) {
+	var req issuing.CardOrderRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	amount := req.Amount.Decimal()
+	if amount.Sign() <= 0 {
+		return nil, errValidation("amount", "amount must be greater than zero")
+	}
+	if card.CardStatus == "CANCELLED" || card.CardStatus == "FROZEN" {
+		return nil, errInvalidState(fmt.Sprintf("card %s is %s", card.CardID, strings.ToLower(card.CardStatus)))
+	}
+
+	if orderType == "RECHARGE" {
+		if err := s.debit(r.owner, card.CardCurrency, amount, "CARD_RECHARGE", card.CardID, "card recharge"); err != nil {
+			return nil, err
+		}
+		card.AvailableBalance = card.AvailableBalance.Add(amount)
+	} else {
+		if card.AvailableBalance.LessThan(amount) {
+			return nil, &apiError{status: http.StatusBadRequest, Code: "insufficient_card_balance", Message: "insufficient card balance"}
+		}
+		card.AvailableBalance = card.AvailableBalance.Sub(amount)
+		s.credit(r.owner, card.CardCurrency, amount, "CARD_WITHDRAW", card.CardID, "card withdrawal")
+	}
+	return s.newOrder(r.owner, card, orderType, amount).CardOrder, nil
+}
+
+func (s *Server) activateCard(r *request) (interface{}, *apiError) {
+	var req issuing.ActivateCardRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	card := s.findCard(r.owner, req.CardID)
+	if card == nil {
+		return nil, errNotFound("card", req.CardID)
+	}
+	if !validPIN(req.PIN) {
+		return nil, errValidation("pin", "PIN must be 6 digits")
+	}
+	if card.CardStatus == "CANCELLED" {
+		return nil, errInvalidState("card " + card.CardID + " is cancelled")
+	}
+	card.CardStatus = "ACTIVE"
+	if req.NoPINPaymentAmount != nil {
+		card.NoPINPaymentAmount = req.NoPINPaymentAmount.Decimal()
+	}
+	return issuing.ActivateCardResponse{RequestStatus: "SUCCESS"}, nil
+}
+
+func (s *Server) resetPIN(r *request) (interface{}, *apiError) {
+	var req issuing.SetPINRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	if s.findCard(r.owner, req.CardID) == nil {
+		return nil, errNotFound("card", req.CardID)
+	}
+	if !validPIN(req.PIN) {
+		return nil, errValidation("pin", "PIN must be 6 digits")
+	}
+	return issuing.SetPINResponse{RequestStatus: "SUCCESS"}, nil
+}
+
+func (s *Server) assignCard(r *request) (interface{}, *apiError) {
+	var req issuing.AssignCardRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	holder := s.findCardholder(r.owner, req.CardholderID)
+	if holder == nil {
+		return nil, errNotFound("cardholder", req.CardholderID)
+	}
+	if req.CardNumber == "" {
+		return nil, errValidation("card_number", "card number is required")
+	}
+	for _, c := range s.cards {
+		if c.CardNumber == req.CardNumber {
+			return nil, &apiError{status: http.StatusConflict, Code: "card_already_assigned", Message: "card is already assigned"}
+		}
+	}
+
+	card := s.newCard(r.owner, holder, nil, strings.ToUpper(req.CardCurrency), req.CardMode, req.CardNumber)
+	order := s.newOrder(r.owner, card, "ASSIGN", money.Zero)
+	return issuing.AssignCardResponse{
+		CardID:      card.CardID,
+		CardOrderID: order.CardOrderID,
+		CreateTime:  order.CreateTime,
+		CardStatus:  card.CardStatus,
+		OrderStatus: order.OrderStatus,
+	}, nil
+}
+
+func (s *Server) bulkCreateCards(r *request) (interface{}, *apiError) {
+	var req issuing.BulkCardCreationRequest
+	if err := r.decode(&req); err != nil {
+		return nil, err
+	}
+	if req.Numbers < 1 || req.Numbers > 5000 {
+		return nil, errValidation("numbers", "numbers must be between 1 and 5000")
+	}
+	expireDate := s.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	return issuing.BulkCardCreationResponse{ReportID: s.nextID("report"), ExpireDate: &expireDate}, nil
+}
+
+// AddCardTransaction simulates a card authorization of amount in the card currency.
+// The transaction is APPROVED and spends the card balance, or DECLINED when the card is
+// not active or its balance is too low. The card ID must exist.
+func (s *Server) AddCardTransaction(cardID, amount, merchant string) (*issuing.Transaction, error) {
+	value, err := money.Parse(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var card *cardState
+	for _, c := range s.cards {
+		if c.CardID == cardID {
+			card = c
+		}
+	}
+	if card == nil {
+		return nil, fmt.Errorf("uqpaytest: card %s not found", cardID)
+	}
+
+	status := "APPROVED"
+	if card.CardStatus != "ACTIVE" || card.AvailableBalance.LessThan(value) {
+		status = "DECLINED"
+	} else {
+		card.AvailableBalance = card.AvailableBalance.Sub(value)
+		consumed := value
+		if card.ConsumedAmount != nil {
+			consumed = card.ConsumedAmount.Add(value)
+		}
+		card.ConsumedAmount = &consumed
+	}
+
+	txn := &cardTxnState{
+		owner: card.owner,
+		Transaction: issuing.Transaction{
+			TransactionID:       s.nextID("txn"),
+			CardID:              card.CardID,
+			TransactionType:     "AUTHORIZATION",
+			TransactionAmount:   value,
+			TransactionCurrency: card.CardCurrency,
+			BillingAmount:       value,
+			BillingCurrency:     card.CardCurrency,
+			MerchantName:        merchant,
+			TransactionStatus:   status,
+			TransactionTime:     s.timestamp(),
+		},
+	}
+	s.cardTxns = append(s.cardTxns, txn)
+	result := txn.Transaction
+	return &result, nil
+}
+
+// FailCardOrder marks a card order as FAILED, returning any recharged funds to the balance
+func (s *Server) FailCardOrder(orderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range s.orders {
+		if o.CardOrderID == orderID {
+			if o.OrderStatus != "PROCESSING" {
+				return fmt.Errorf("uqpaytest: card order %s is %s", orderID, o.OrderStatus)
+			}
+			s.completeOrder(o, "FAILED")
+			return nil
+		}
+	}
+	return fmt.Errorf("uqpaytest: card order %s not found", orderID)
+}
+
+func validPIN(pin string) bool {
+	if len(pin) != 6 {
+		return false
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, v string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// luhnNumber appends a Luhn check digit to a 15-digit prefix
+func luhnNumber(prefix string) string {
+	if len(prefix) > 15 {
+		prefix = prefix[:15]
+	}
+	sum := 0
+	double := true
+	for i := len(prefix) - 1; i >= 0; i-- {
+		d := int(prefix[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return prefix + string(rune('0'+(10-sum%10)%10))
+}
@@ -0,0 +1,529 @@
+// Package uqpaytest provides an in-process fake of the UQPAY API for offline tests.
+//
+// The fake is stateful: cardholders, cards, payouts, conversions and the rest are kept in
+// memory, balances move as money is spent, and pending resources advance one status per
+// read (a card is PENDING when created and ACTIVE when next retrieved). Faults can be
+// injected to exercise retries and error handling.
+//
+//	client, server := uqpaytest.NewClient(t)
+//	server.SetBalance("USD", "1000.00")
+//	payout, err := client.Banking.Payouts.Create(ctx, req)
+package uqpaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	uqpay "github.com/jackillll/uqpay-sdk-go"
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/common"
+	"github.com/jackillll/uqpay-sdk-go/configuration"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+	"github.com/jackillll/uqpay-sdk-go/money"
+)
+
+// Credentials accepted by the fake's token endpoint
+const (
+	ClientID = "uqpaytest-client"
+	APIKey   = "uqpaytest-key"
+)
+
+// Fault makes matching requests fail. Method and Path are optional filters; Path matches
+// as a prefix of the request path.
+type Fault struct {
+	Method  string
+	Path    string
+	Status  int           // HTTP status, 500 when zero
+	Code    string        // error code in the body, e.g. "rate_limit_exceeded"
+	Message string        // error message in the body
+	Header  http.Header   // extra response headers, e.g. Retry-After
+	Delay   time.Duration // wait before responding, e.g. to trigger client timeouts
+	Times   int           // number of matching requests to fail, 0 for every request
+}
+
+// RecordedRequest is a request received by the fake
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// Server is a stateful fake UQPAY API. The zero value is not usable; create one with NewServer.
+type Server struct {
+	*httptest.Server
+
+	// Now returns the current time used for timestamps and quote expiry
+	Now func() time.Time
+
+	mu       sync.Mutex
+	seq      int
+	tokens   map[string]time.Time
+	faults   []*Fault
+	requests []RecordedRequest
+	replays  map[string]*replay
+
+	balances      map[string]*banking.Balance // key: owner + "/" + currency
+	balanceTxns   []*ownedBalanceTxn
+	cardholders   []*cardholderState
+	cards         []*cardState
+	orders        []*orderState
+	cardTxns      []*cardTxnState
+	products      []*issuing.CardProduct
+	payouts       []*payoutState
+	payoutFee     money.Decimal
+	transfers     []*transferState
+	conversions   []*conversionState
+	quotes        map[string]*quoteState
+	rates         map[string]string // "USD/EUR" -> rate
+	quoteMarkup   money.Decimal     // fraction taken off the rate of quotes and conversions
+	beneficiaries []*beneficiaryState
+	deposits      []*depositState
+	virtual       []*virtualAccountState
+	accounts      []*accountState
+	files         map[string]*fileState
+	tokenLifetime time.Duration
+	manual        bool // statuses only change through helpers
+}
+
+// replay is a stored response for an idempotency key
+type replay struct {
+	status int
+	body   []byte
+}
+
+// NewServer starts a fake UQPAY server with a default card product, USD/EUR/SGD/GBP rates
+// and empty balances. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		Now:           time.Now,
+		tokens:        make(map[string]time.Time),
+		replays:       make(map[string]*replay),
+		balances:      make(map[string]*banking.Balance),
+		quotes:        make(map[string]*quoteState),
+		rates:         make(map[string]string),
+		files:         make(map[string]*fileState),
+		tokenLifetime: time.Hour,
+	}
+	s.seedDefaults()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// NewClient starts a fake server, registers its cleanup with t and returns a client
+// pointed at it. Retries are disabled so injected faults surface immediately; pass
+// uqpay.WithRetryPolicy to override.
+func NewClient(t testing.TB, opts ...uqpay.Option) (*uqpay.Client, *Server) {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	client, err := s.Client(opts...)
+	if err != nil {
+		t.Fatalf("uqpaytest: failed to create client: %v", err)
+	}
+	return client, s
+}
+
+// Environment returns an environment whose main and Files URLs point at the fake
+func (s *Server) Environment() *configuration.Environment {
+	return &configuration.Environment{Name: "uqpaytest", BaseURL: s.URL, FilesBaseURL: s.URL}
+}
+
+// Client returns a client pointed at the fake, authenticated with ClientID and APIKey
+func (s *Server) Client(opts ...uqpay.Option) (*uqpay.Client, error) {
+	base := []uqpay.Option{
+		uqpay.WithHTTPClient(s.Server.Client()),
+		uqpay.WithRetryPolicy(common.NoRetry()),
+	}
+	return uqpay.NewClient(ClientID, APIKey, s.Environment(), append(base, opts...)...)
+}
+
+// Inject adds a fault; faults are checked in the order they were added
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the API requests received so far, excluding token requests
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// SetTokenLifetime sets how long issued tokens are valid, e.g. to exercise token refresh
+func (s *Server) SetTokenLifetime(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenLifetime = d
+}
+
+// RevokeTokens invalidates every issued token, so the next API call gets a 401
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]time.Time)
+}
+
+// apiError is the error body returned by the fake
+type apiError struct {
+	status  int
+	Code    string              `json:"code"`
+	Message string              `json:"message"`
+	Details []common.FieldError `json:"details,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func errNotFound(resource, id string) *apiError {
+	return &apiError{status: http.StatusNotFound, Code: "resource_not_found", Message: fmt.Sprintf("%s %s not found", resource, id)}
+}
+
+func errValidation(field, message string) *apiError {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		Code:    "validation_error",
+		Message: "request validation failed",
+		Details: []common.FieldError{{Field: field, Message: message}},
+	}
+}
+
+func errInsufficientBalance(currency string) *apiError {
+	return &apiError{status: http.StatusBadRequest, Code: "insufficient_balance", Message: fmt.Sprintf("insufficient %s balance", currency)}
+}
+
+func errInvalidState(message string) *apiError {
+	return &apiError{status: http.StatusBadRequest, Code: "invalid_state", Message: message}
+}
+
+// request is the parsed request passed to route handlers
+type request struct {
+	method   string
+	segments []string // path segments after /v1
+	query    map[string][]string
+	body     []byte
+	owner    string // x-on-behalf-of account, empty for the master account
+	header   http.Header
+}
+
+func (r *request) param(name string) string {
+	if v := r.query[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (r *request) decode(v interface{}) *apiError {
+	if len(r.body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return &apiError{status: http.StatusBadRequest, Code: "invalid_request", Message: "malformed JSON body: " + err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	requestID := fmt.Sprintf("req_%d", time.Now().UnixNano())
+	w.Header().Set("x-request-id", requestID)
+
+	if r.URL.Path == "/v1/connect/token" {
+		s.handleToken(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/download/") {
+		s.handleDownload(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		for name, values := range fault.Header {
+			w.Header()[name] = values
+		}
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		message := fault.Message
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		writeJSON(w, status, &apiError{Code: fault.Code, Message: message})
+		return
+	}
+
+	if !s.authorized(r.Header.Get("x-auth-token")) {
+		writeJSON(w, http.StatusUnauthorized, &apiError{Code: "unauthorized", Message: "invalid or expired token"})
+		return
+	}
+
+	req := &request{
+		method: r.Method,
+		query:  r.URL.Query(),
+		body:   body,
+		owner:  r.Header.Get(common.OnBehalfOfHeader),
+		header: r.Header,
+	}
+	path := strings.Trim(r.URL.Path, "/")
+	if !strings.HasPrefix(path, "v1/") {
+		writeJSON(w, http.StatusNotFound, errNotFound("route", r.URL.Path))
+		return
+	}
+	req.segments = strings.Split(strings.TrimPrefix(path, "v1/"), "/")
+
+	// Writes with an idempotency key seen before replay the stored response
+	key := r.Header.Get("x-idempotency-key")
+	replayKey := ""
+	if r.Method == http.MethodPost && key != "" {
+		replayKey = req.owner + "|" + r.Method + "|" + r.URL.Path + "|" + key
+	}
+	status, data, replayed := s.serve(req, replayKey)
+
+	w.Header().Set("Content-Type", "application/json")
+	if replayed {
+		w.Header().Set("x-idempotent-replay", "true")
+	}
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// serve routes a request and encodes the result. With a replayKey, the stored response is
+// returned if there is one, and otherwise the response is stored; both happen under s.mu so
+// concurrent requests with the same key execute once.
+func (s *Server) serve(req *request, replayKey string) (status int, data []byte, replayed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.replays[replayKey]; ok && replayKey != "" {
+		return stored.status, stored.body, true
+	}
+
+	result, err := s.route(req)
+	status = http.StatusOK
+	var payload interface{} = result
+	if err != nil {
+		status = err.status
+		payload = err
+	}
+	data, _ = json.Marshal(payload)
+
+	if replayKey != "" && status < 500 {
+		s.replays[replayKey] = &replay{status: status, body: data}
+	}
+	return status, data, false
+}
+
+// route dispatches a request to the resource handlers. s.mu is held.
+func (s *Server) route(r *request) (interface{}, *apiError) {
+	if r.owner != "" && s.findAccount(r.owner) == nil {
+		return nil, &apiError{status: http.StatusForbidden, Code: "invalid_account", Message: "unknown on-behalf-of account " + r.owner}
+	}
+
+	switch r.segments[0] {
+	case "issuing":
+		if len(r.segments) > 1 {
+			return s.routeIssuing(r, r.segments[1], r.segments[2:])
+		}
+	case "balances":
+		return s.routeBalances(r, r.segments[1:])
+	case "payouts":
+		return s.routePayouts(r, r.segments[1:])
+	case "transfer":
+		return s.routeTransfers(r, r.segments[1:])
+	case "conversion":
+		return s.routeConversions(r, r.segments[1:])
+	case "exchange":
+		if len(r.segments) == 2 && r.segments[1] == "rates" && r.method == http.MethodGet {
+			return s.listRates(r)
+		}
+	case "beneficiaries":
+		return s.routeBeneficiaries(r, r.segments[1:])
+	case "deposit":
+		return s.routeDeposits(r, r.segments[1:])
+	case "virtual":
+		if len(r.segments) == 2 && r.segments[1] == "accounts" {
+			return s.routeVirtualAccounts(r)
+		}
+	case "accounts":
+		return s.routeAccounts(r, r.segments[1:])
+	case "files":
+		return s.routeFiles(r, r.segments[1:])
+	}
+	return nil, errNotFound("route", "/v1/"+strings.Join(r.segments, "/"))
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("x-client-id") != ClientID || r.Header.Get("x-api-key") != APIKey {
+		writeJSON(w, http.StatusUnauthorized, &apiError{Code: "invalid_credentials", Message: "invalid client ID or API key"})
+		return
+	}
+
+	s.mu.Lock()
+	token := s.nextID("token")
+	expiresAt := s.Now().Add(s.tokenLifetime)
+	s.tokens[token] = expiresAt
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"auth_token": token,
+		"expired_at": expiresAt.Unix(),
+	})
+}
+
+func (s *Server) authorized(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.tokens[token]
+	return ok && s.Now().Before(expiresAt)
+}
+
+// matchFault returns the first fault matching r and consumes one use of it. s.mu is held.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+			continue
+		}
+		if f.Path != "" && !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// nextID returns a unique, readable ID such as "card_000012". s.mu is held.
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_%06d", prefix, s.seq)
+}
+
+// shortRef returns a short reference ID such as "P240101-000012". s.mu is held.
+func (s *Server) shortRef(letter string) string {
+	s.seq++
+	return fmt.Sprintf("%s%s-%06d", letter, s.Now().Format("060102"), s.seq)
+}
+
+func (s *Server) timestamp() string {
+	return s.Now().UTC().Format(time.RFC3339)
+}
+
+// pageOf returns one page of items using the page_size and page_number query parameters
+func pageOf[T any](r *request, items []T) (int, int, []T) {
+	size, _ := strconv.Atoi(r.param("page_size"))
+	if size <= 0 {
+		size = 10
+	}
+	number, _ := strconv.Atoi(r.param("page_number"))
+	if number <= 0 {
+		number = 1
+	}
+
+	total := len(items)
+	pages := (total + size - 1) / size
+	start := (number - 1) * size
+	if start > total {
+		start = total
+	}
+	end := start + size
+	if end > total {
+		end = total
+	}
+	page := make([]T, end-start)
+	copy(page, items[start:end])
+	return pages, total, page
+}
+
+// listResponse is the paginated list envelope used by every list endpoint
+type listResponse struct {
+	TotalPages int         `json:"total_pages"`
+	TotalItems int         `json:"total_items"`
+	Data       interface{} `json:"data"`
+}
+
+func paginate[T any](r *request, items []T) *listResponse {
+	pages, total, page := pageOf(r, items)
+	return &listResponse{TotalPages: pages, TotalItems: total, Data: page}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(v)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// matches reports whether an optional filter value matches, ignoring case; "ALL" matches everything
+func matches(filter, value string) bool {
+	return filter == "" || strings.EqualFold(filter, "ALL") || strings.EqualFold(filter, value)
+}
+
+// inRange reports whether an RFC3339 timestamp lies within optional start/end bounds
+func inRange(ts, start, end string) bool {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return true
+	}
+	if s, err := time.Parse(time.RFC3339, start); err == nil && t.Before(s) {
+		return false
+	}
+	if e, err := time.Parse(time.RFC3339, end); err == nil && t.After(e) {
+		return false
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackillll/uqpay-sdk-go/banking"
+	"github.com/jackillll/uqpay-sdk-go/connect"
+	"github.com/jackillll/uqpay-sdk-go/issuing"
+)
+
+// Event types with typed handlers. They are not confirmed against UQPAY's webhook
+// documentation; register other types with Handler.On.
+const (
+	EventCardTransactionCreated = "issuing.transaction.created"
+	EventCardTransactionUpdated = "issuing.transaction.updated"
+	EventPayoutStatusChanged    = "banking.payout.status_changed"
+	EventDepositStatusChanged   = "banking.deposit.status_changed"
+	EventAccountUpdated         = "connect.account.updated" // KYC and capability changes
+)
+
+// Event is the envelope shared by every webhook notification
+type Event struct {
+	EventID    string          `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	AccountID  string          `json:"account_id,omitempty"`
+	CreateTime string          `json:"create_time"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// ErrMalformedEvent is returned by Decode when the event data does not match the expected type
+var ErrMalformedEvent = errors.New("webhooks: malformed event data")
+
+// Decode unmarshals the event data into v. Errors wrap ErrMalformedEvent.
+func (e *Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("%w: failed to decode %s event data: %v", ErrMalformedEvent, e.EventType, err)
+	}
+	return nil
+}
+
+// ParseEvent parses a webhook request body. It does not verify the signature.
+func ParseEvent(body []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+	if event.EventType == "" {
+		return nil, fmt.Errorf("failed to parse event: missing event_type")
+	}
+	return &event, nil
+}
+
+// CardTransactionEvent is sent when a card transaction is created or updated
+type CardTransactionEvent struct {
+	*Event
+	Transaction issuing.Transaction
+}
+
+// PayoutEvent is sent when a payout changes status
+type PayoutEvent struct {
+	*Event
+	Payout banking.Payout
+}
+
+// DepositEvent is sent when a deposit changes status
+type DepositEvent struct {
+	*Event
+	Deposit banking.Deposit
+}
+
+// AccountEvent is sent when a Connect account is updated, including KYC changes
+type AccountEvent struct {
+	*Event
+	Account connect.Account
+}
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBodyBytes is the largest webhook body accepted by Handler
+const DefaultMaxBodyBytes = 1 << 20
+
+// HandlerFunc handles a verified webhook event
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handler is an http.Handler that verifies, parses and dispatches UQPAY webhooks.
+//
+//	h := webhooks.NewHandler(secret)
+//	h.OnPayout(func(ctx context.Context, e *webhooks.PayoutEvent) error {
+//		log.Printf("payout %s is %s", e.Payout.PayoutID, e.Payout.PayoutStatus)
+//		return nil
+//	})
+//	http.Handle("/webhooks/uqpay", h)
+type Handler struct {
+	Secret       string
+	Scheme       *Scheme          // signature headers and payload, DefaultScheme when nil
+	Tolerance    time.Duration    // replay window, DefaultTolerance when zero
+	MaxBodyBytes int64            // DefaultMaxBodyBytes when zero
+	Now          func() time.Time // clock used for the replay window, time.Now when nil
+
+	// Fallback handles event types without a registered handler; such events are acknowledged when nil
+	Fallback HandlerFunc
+
+	mu       sync.RWMutex
+	handlers map[string][]HandlerFunc
+}
+
+// NewHandler creates a webhook handler that verifies requests with the given secret.
+// With an empty secret every request is refused with 500 rather than accepted unverified.
+func NewHandler(secret string) *Handler {
+	return &Handler{
+		Secret:   secret,
+		handlers: make(map[string][]HandlerFunc),
+	}
+}
+
+// On registers fn for an event type. Several handlers may be registered for the same type.
+func (h *Handler) On(eventType string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.handlers == nil {
+		h.handlers = make(map[string][]HandlerFunc)
+	}
+	h.handlers[eventType] = append(h.handlers[eventType], fn)
+}
+
+// OnCardTransaction registers fn for card transaction created and updated events
+func (h *Handler) OnCardTransaction(fn func(ctx context.Context, event *CardTransactionEvent) error) {
+	handle := func(ctx context.Context, event *Event) error {
+		e := &CardTransactionEvent{Event: event}
+		if err := event.Decode(&e.Transaction); err != nil {
+			return err
+		}
+		return fn(ctx, e)
+	}
+	h.On(EventCardTransactionCreated, handle)
+	h.On(EventCardTransactionUpdated, handle)
+}
+
+// OnPayout registers fn for payout status change events
+func (h *Handler) OnPayout(fn func(ctx context.Context, event *PayoutEvent) error) {
+	h.On(EventPayoutStatusChanged, func(ctx context.Context, event *Event) error {
+		e := &PayoutEvent{Event: event}
+		if err := event.Decode(&e.Payout); err != nil {
+			return err
+		}
+		return fn(ctx, e)
+	})
+}
+
+// OnDeposit registers fn for deposit status change events
+func (h *Handler) OnDeposit(fn func(ctx context.Context, event *DepositEvent) error) {
+	h.On(EventDepositStatusChanged, func(ctx context.Context, event *Event) error {
+		e := &DepositEvent{Event: event}
+		if err := event.Decode(&e.Deposit); err != nil {
+			return err
+		}
+		return fn(ctx, e)
+	})
+}
+
+// OnAccount registers fn for Connect account update events
+func (h *Handler) OnAccount(fn func(ctx context.Context, event *AccountEvent) error) {
+	h.On(EventAccountUpdated, func(ctx context.Context, event *Event) error {
+		e := &AccountEvent{Event: event}
+		if err := event.Decode(&e.Account); err != nil {
+			return err
+		}
+		return fn(ctx, e)
+	})
+}
+
+// VerifyRequest reads and verifies a webhook request and returns the parsed event
+func (h *Handler) VerifyRequest(r *http.Request) (*Event, error) {
+	maxBytes := h.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, errors.New("webhooks: request body too large")
+	}
+
+	tolerance := h.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	now := time.Now
+	if h.Now != nil {
+		now = h.Now
+	}
+	scheme := DefaultScheme
+	if h.Scheme != nil {
+		scheme = h.Scheme.withDefaults()
+	}
+	if err := scheme.Verify(h.Secret, body, r.Header.Get(scheme.SignatureHeader), r.Header.Get(scheme.TimestampHeader), tolerance, now()); err != nil {
+		return nil, err
+	}
+
+	return ParseEvent(body)
+}
+
+// Dispatch runs the handlers registered for the event type, stopping at the first error
+func (h *Handler) Dispatch(ctx context.Context, event *Event) error {
+	h.mu.RLock()
+	handlers := h.handlers[event.EventType]
+	h.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		if h.Fallback != nil {
+			return h.Fallback(ctx, event)
+		}
+		return nil
+	}
+	for _, fn := range handlers {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler. Verification failures return 401, malformed events and
+// event data that fails to decode 400, and handler errors or a missing secret 500 so that
+// UQPAY retries the delivery.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	event, err := h.VerifyRequest(r)
+	if errors.Is(err, ErrMissingSecret) {
+		http.Error(w, "webhook secret not configured", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrMissingSignature) || errors.Is(err, ErrInvalidSignature) ||
+			errors.Is(err, ErrInvalidTimestamp) || errors.Is(err, ErrTimestampExpired) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := h.Dispatch(r.Context(), event); err != nil {
+		if errors.Is(err, ErrMalformedEvent) {
+			// Retrying cannot fix the body
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "webhook handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
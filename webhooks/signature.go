@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default headers carrying the webhook signature and the time it was produced (Unix seconds).
+// They are not confirmed against UQPAY's webhook documentation; if your deliveries use other
+// headers or sign a different payload, set Handler.Scheme.
+const (
+	SignatureHeader = "x-uqpay-signature"
+	TimestampHeader = "x-uqpay-timestamp"
+)
+
+// PayloadFunc builds the bytes a webhook signature covers from the timestamp and the body
+type PayloadFunc func(timestamp int64, body []byte) []byte
+
+// DefaultPayload returns "<timestamp>.<body>"
+func DefaultPayload(timestamp int64, body []byte) []byte {
+	payload := strconv.AppendInt(nil, timestamp, 10)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
+
+// Scheme describes how webhook requests are signed
+type Scheme struct {
+	SignatureHeader string      // SignatureHeader when empty
+	TimestampHeader string      // TimestampHeader when empty
+	Payload         PayloadFunc // DefaultPayload when nil
+}
+
+// DefaultScheme is the scheme used by Sign, Verify and a Handler without a Scheme
+var DefaultScheme = Scheme{SignatureHeader: SignatureHeader, TimestampHeader: TimestampHeader, Payload: DefaultPayload}
+
+func (s Scheme) withDefaults() Scheme {
+	if s.SignatureHeader == "" {
+		s.SignatureHeader = SignatureHeader
+	}
+	if s.TimestampHeader == "" {
+		s.TimestampHeader = TimestampHeader
+	}
+	if s.Payload == nil {
+		s.Payload = DefaultPayload
+	}
+	return s
+}
+
+// DefaultTolerance is the maximum accepted age of a webhook request
+const DefaultTolerance = 5 * time.Minute
+
+// Verification errors
+var (
+	ErrMissingSecret    = errors.New("webhooks: secret not configured")
+	ErrMissingSignature = errors.New("webhooks: missing signature")
+	ErrInvalidSignature = errors.New("webhooks: invalid signature")
+	ErrInvalidTimestamp = errors.New("webhooks: invalid timestamp")
+	ErrTimestampExpired = errors.New("webhooks: timestamp outside tolerance")
+)
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of "<timestamp>.<body>"
+func Sign(secret string, timestamp int64, body []byte) string {
+	return DefaultScheme.Sign(secret, timestamp, body)
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of the scheme's payload
+func (s Scheme) Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(s.withDefaults().Payload(timestamp, body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a webhook request with DefaultScheme
+func Verify(secret string, body []byte, signature, timestamp string, tolerance time.Duration, now time.Time) error {
+	return DefaultScheme.Verify(secret, body, signature, timestamp, tolerance, now)
+}
+
+// Verify checks the signature and timestamp headers of a webhook request.
+// The signature header may carry several comma-separated signatures, e.g. during secret rotation;
+// any match is accepted. A tolerance <= 0 disables the replay-window check. An empty secret
+// is rejected with ErrMissingSecret, since anyone can sign with an empty key.
+func (s Scheme) Verify(secret string, body []byte, signature, timestamp string, tolerance time.Duration, now time.Time) error {
+	if secret == "" {
+		return ErrMissingSecret
+	}
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	ts, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimestamp, timestamp)
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(ts, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	expected := []byte(s.Sign(secret, ts, body))
+	for _, candidate := range strings.Split(signature, ",") {
+		candidate = strings.TrimSpace(candidate)
+		candidate = strings.TrimPrefix(candidate, "v1=")
+		if hmac.Equal(expected, []byte(strings.ToLower(candidate))) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}